@@ -0,0 +1,26 @@
+package app
+
+import (
+	"log"
+	"online-shop/payment"
+
+	"github.com/spf13/viper"
+)
+
+func InitPaymentProvider() payment.Provider {
+	provider, err := payment.NewProvider(
+		viper.GetString("PAYMENT_PROVIDER"),
+		viper.GetString("PAYMENT_WEBHOOK_SECRET"),
+		payment.FakeOptions{
+			Scenario:    viper.GetString("PAYMENT_FAKE_SCENARIO"),
+			Delay:       viper.GetDuration("PAYMENT_FAKE_DELAY"),
+			CallbackURL: viper.GetString("PAYMENT_FAKE_CALLBACK_URL"),
+		},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("payment provider initialized:", provider.Name())
+	return provider
+}
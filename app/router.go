@@ -1,30 +1,97 @@
 package app
 
 import (
+	"context"
+	"log"
 	"net/http"
 	"online-shop/delivery"
+	"online-shop/mailer"
 	"online-shop/middleware"
+	"online-shop/model/entity"
+	"online-shop/payment"
 	"online-shop/repository"
+	"online-shop/storage"
 	"online-shop/usecase"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
 	"gorm.io/gorm"
 )
 
-func InitRouter(postgresConn *gorm.DB, redisClient *redis.Client) *gin.Engine {
+func InitRouter(postgresConn *gorm.DB, redisClient *redis.Client, paymentProvider payment.Provider, store storage.Storage) *gin.Engine {
+
+	auditRepo := repository.NewAuditRepository(postgresConn)
+	auditUsecase := usecase.NewAuditUsecase(auditRepo)
+	auditDelivery := delivery.NewAuditDelivery(auditUsecase)
 
 	orderRepo := repository.NewOrderRepository(postgresConn, redisClient)
+	paymentRepo := repository.NewPaymentRepository(postgresConn, redisClient)
 
 	r := repository.NewRepository(postgresConn, redisClient)
-	u := usecase.NewUsecase(r, orderRepo)
-	d := delivery.NewDelivery(u)
+	variantRepo := repository.NewVariantRepository(postgresConn)
+	promotionRepo := repository.NewPromotionRepository(postgresConn)
+	shippingRepo := repository.NewShippingRepository(postgresConn)
+	taxRepo := repository.NewTaxRepository(postgresConn)
+	u := usecase.NewUsecase(r, orderRepo, variantRepo, promotionRepo, shippingRepo, taxRepo)
+	d := delivery.NewDelivery(u, auditUsecase)
 
-	orderUsecase := usecase.NewOrderUsecase(orderRepo)
-	orderDelivery := delivery.NewOrderDelivery(u, orderUsecase)
+	attemptRepo := repository.NewPasscodeAttemptRepository(redisClient)
+	orderUsecase := usecase.NewOrderUsecase(orderRepo, paymentRepo, paymentProvider, attemptRepo, auditUsecase)
+	orderDelivery := delivery.NewOrderDelivery(u, orderUsecase, auditUsecase)
 
-	router := gin.Default()
-	router.Use(CORSMiddleware())
+	categoryRepo := repository.NewCategoryRepository(postgresConn, redisClient)
+	categoryUsecase := usecase.NewCategoryUsecase(categoryRepo, r)
+	categoryDelivery := delivery.NewCategoryDelivery(categoryUsecase, auditUsecase)
+
+	promotionUsecase := usecase.NewPromotionUsecase(promotionRepo, r, categoryRepo)
+	promotionDelivery := delivery.NewPromotionDelivery(promotionUsecase, auditUsecase)
+
+	taxUsecase := usecase.NewTaxUsecase(taxRepo)
+	taxDelivery := delivery.NewTaxDelivery(taxUsecase, auditUsecase)
+
+	shippingUsecase := usecase.NewShippingUsecase(shippingRepo, r)
+	shippingDelivery := delivery.NewShippingDelivery(shippingUsecase, auditUsecase)
+
+	variantUsecase := usecase.NewVariantUsecase(variantRepo, r)
+	variantDelivery := delivery.NewVariantDelivery(variantUsecase, auditUsecase)
+
+	imageRepo := repository.NewImageRepository(postgresConn, redisClient)
+	imageUsecase := usecase.NewImageUsecase(imageRepo, r, store)
+	imageDelivery := delivery.NewImageDelivery(imageUsecase, auditUsecase)
+
+	customerRepo := repository.NewCustomerRepository(postgresConn, redisClient)
+	tokenRepo := repository.NewTokenRepository(redisClient)
+	cartRepo := repository.NewCartRepository(redisClient)
+	customerUsecase := usecase.NewCustomerUsecase(customerRepo, tokenRepo, cartRepo, mailer.NewLogMailer())
+	customerDelivery := delivery.NewCustomerDelivery(customerUsecase)
+
+	cartUsecase := usecase.NewCartUsecase(cartRepo, r, variantRepo)
+	cartDelivery := delivery.NewCartDelivery(cartUsecase, u)
+
+	adminRepo := repository.NewAdminRepository(postgresConn, redisClient)
+	adminUsecase := usecase.NewAdminUsecase(adminRepo)
+	adminDelivery := delivery.NewAdminDelivery(adminUsecase, auditUsecase)
+
+	apiKeyRepo := repository.NewAPIKeyRepository(postgresConn, redisClient)
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepo)
+	apiKeyDelivery := delivery.NewAPIKeyDelivery(apiKeyUsecase, auditUsecase)
+
+	if err := adminUsecase.Bootstrap(context.Background()); err != nil {
+		log.Fatal("error bootstrap admin: ", err)
+	}
+
+	router, err := newEngine()
+	if err != nil {
+		log.Fatal("error set trusted proxies: ", err)
+	}
+	router.Use(middleware.RequestIDMiddleware(), CORSMiddleware())
+
+	// File media disajikan langsung jika memakai storage lokal
+	if store.Name() == "local" {
+		router.Static("/media", viper.GetString("STORAGE_LOCAL_DIR"))
+	}
 
 	router.NoRoute(func(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -32,31 +99,160 @@ func InitRouter(postgresConn *gorm.DB, redisClient *redis.Client) *gin.Engine {
 		})
 	})
 
+	authLimit := middleware.RateLimitMiddleware(redisClient, "auth")
+	checkoutLimit := middleware.RateLimitMiddleware(redisClient, "checkout")
+	ordersLimit := middleware.RateLimitMiddleware(redisClient, "orders")
+	publicLimit := middleware.RateLimitMiddleware(redisClient, "public")
+
 	v1 := router.Group("/api/v1")
+	router.POST("/admin/login", authLimit, adminDelivery.Login)
 	admin := router.Group("/admin")
-	admin.Use(middleware.HeaderMiddleware())
+	admin.Use(
+		middleware.APIKeyMiddleware(apiKeyUsecase),
+		middleware.HeaderMiddleware(adminUsecase),
+		middleware.RateLimitMiddleware(redisClient, "admin"),
+	)
+
+	productsRead := middleware.RequirePermission(entity.PermissionProductsRead)
+	productsWrite := middleware.RequirePermission(entity.PermissionProductsWrite)
+	ordersRead := middleware.RequirePermission(entity.PermissionOrdersRead)
+	ordersWrite := middleware.RequirePermission(entity.PermissionOrdersWrite)
+	refundsWrite := middleware.RequirePermission(entity.PermissionRefundsWrite)
+	adminsManage := middleware.RequirePermission(entity.PermissionAdminsManage)
+	promotionsManage := middleware.RequirePermission(entity.PermissionPromotionsManage)
+	taxesManage := middleware.RequirePermission(entity.PermissionTaxesManage)
+
+	// API Admin
+	adminOnly := middleware.AdminOnly()
+	admin.POST("/logout", adminOnly, adminDelivery.Logout)
+	admin.POST("/tokens/rotate", adminOnly, adminDelivery.RotateToken)
+	admin.GET("/users", adminsManage, adminDelivery.ListUsers)
+	admin.POST("/users", adminsManage, adminDelivery.CreateUser)
+	admin.PUT("/users/:id", adminsManage, adminDelivery.UpdateUser)
+	admin.POST("/users/:id/revoke-tokens", adminsManage, adminDelivery.RevokeUserTokens)
+	admin.GET("/api-keys", adminsManage, apiKeyDelivery.List)
+	admin.POST("/api-keys", adminsManage, apiKeyDelivery.Create)
+	admin.DELETE("/api-keys/:id", adminsManage, apiKeyDelivery.Revoke)
+	admin.GET("/audit", adminsManage, auditDelivery.ListAuditLogs)
 
 	// API Products
-	v1.GET("/products", d.GetProducts)
-	v1.GET("/products/:id", d.GetProductbyID)
-	admin.POST("/products", d.CreateProduct)
-	admin.PUT("/products/:id", d.UpdateProduct)
-	admin.DELETE("/products/:id", d.DeleteProduct)
+	v1.GET("/products", publicLimit, d.GetProducts)
+	v1.GET("/products/search", publicLimit, d.SearchProducts)
+	v1.GET("/products/:id", publicLimit, d.GetProductbyID)
+	admin.POST("/products", productsWrite, d.CreateProduct)
+	admin.POST("/products/import", productsWrite, d.ImportProducts)
+	admin.GET("/products/export", productsRead, d.ExportProducts)
+	admin.PUT("/products/:id", productsWrite, d.UpdateProduct)
+	admin.DELETE("/products/:id", productsWrite, d.DeleteProduct)
+	admin.POST("/products/:id/stock", productsWrite, d.AdjustStock)
+	admin.GET("/products/:id/stock", productsRead, d.GetStockMovements)
+	admin.GET("/products/:id/prices", productsRead, d.GetProductPrices)
+	admin.PUT("/products/:id/prices", productsWrite, d.SetProductPrices)
+	admin.PUT("/products/:id/categories", productsWrite, categoryDelivery.SetProductCategories)
+
+	// API Variants
+	v1.GET("/products/:id/variants", publicLimit, variantDelivery.GetVariants)
+	admin.PUT("/products/:id/options", productsWrite, variantDelivery.SetOptions)
+	admin.POST("/products/:id/variants", productsWrite, variantDelivery.CreateVariant)
+	admin.PUT("/products/:id/variants/:variantId", productsWrite, variantDelivery.UpdateVariant)
+	admin.DELETE("/products/:id/variants/:variantId", productsWrite, variantDelivery.DeleteVariant)
+	admin.POST("/products/:id/variants/:variantId/stock", productsWrite, variantDelivery.AdjustStock)
+
+	// API Images
+	admin.GET("/products/:id/images", productsRead, imageDelivery.GetImages)
+	admin.POST("/products/:id/images", productsWrite, imageDelivery.UploadImages)
+	admin.PUT("/products/:id/images/order", productsWrite, imageDelivery.ReorderImages)
+	admin.DELETE("/products/:id/images/:imageId", productsWrite, imageDelivery.DeleteImage)
+
+	// API Categories
+	v1.GET("/categories", publicLimit, categoryDelivery.GetCategories)
+	v1.GET("/products/:id/categories", publicLimit, categoryDelivery.GetProductCategories)
+	admin.POST("/categories", productsWrite, categoryDelivery.CreateCategory)
+	admin.PUT("/categories/:id", productsWrite, categoryDelivery.UpdateCategory)
+	admin.DELETE("/categories/:id", productsWrite, categoryDelivery.DeleteCategory)
+
+	// API Promotions
+	admin.GET("/promotions", promotionsManage, promotionDelivery.ListPromotions)
+	admin.GET("/promotions/:id", promotionsManage, promotionDelivery.GetPromotion)
+	admin.POST("/promotions", promotionsManage, promotionDelivery.CreatePromotion)
+	admin.PUT("/promotions/:id", promotionsManage, promotionDelivery.UpdatePromotion)
+	admin.DELETE("/promotions/:id", promotionsManage, promotionDelivery.DeletePromotion)
+
+	// API Shipping
+	v1.POST("/shipping/quote", publicLimit, shippingDelivery.QuoteShipping)
+	admin.GET("/shipping-methods", ordersRead, shippingDelivery.ListShippingMethods)
+	admin.GET("/shipping-methods/:id", ordersRead, shippingDelivery.GetShippingMethod)
+	admin.POST("/shipping-methods", ordersWrite, shippingDelivery.CreateShippingMethod)
+	admin.PUT("/shipping-methods/:id", ordersWrite, shippingDelivery.UpdateShippingMethod)
+	admin.DELETE("/shipping-methods/:id", ordersWrite, shippingDelivery.DeleteShippingMethod)
+
+	// API Taxes
+	admin.GET("/tax-classes", taxesManage, taxDelivery.ListTaxClasses)
+	admin.POST("/tax-classes", taxesManage, taxDelivery.CreateTaxClass)
+	admin.DELETE("/tax-classes/:code", taxesManage, taxDelivery.DeleteTaxClass)
+	admin.GET("/tax-rates", taxesManage, taxDelivery.ListTaxRates)
+	admin.POST("/tax-rates", taxesManage, taxDelivery.CreateTaxRate)
+	admin.PUT("/tax-rates/:id", taxesManage, taxDelivery.UpdateTaxRate)
+	admin.DELETE("/tax-rates/:id", taxesManage, taxDelivery.DeleteTaxRate)
+
+	// API Customers
+	customerAuth := middleware.CustomerAuthMiddleware(tokenRepo, true)
+	optionalCustomerAuth := middleware.CustomerAuthMiddleware(tokenRepo, false)
+	v1.POST("/customers/register", authLimit, customerDelivery.Register)
+	v1.POST("/customers/verify", authLimit, customerDelivery.VerifyEmail)
+	v1.POST("/customers/login", authLimit, customerDelivery.Login)
+	v1.POST("/customers/refresh", authLimit, customerDelivery.Refresh)
+	v1.POST("/customers/logout", customerAuth, customerDelivery.Logout)
+	v1.GET("/customers/me", customerAuth, publicLimit, customerDelivery.GetProfile)
+	v1.GET("/customers/me/orders", customerAuth, publicLimit, orderDelivery.ListCustomerOrders)
+	v1.GET("/customers/me/cart", customerAuth, publicLimit, cartDelivery.GetCustomerCart)
 
 	// API Orders
-	v1.POST("/checkout", d.Checkout)
-	v1.POST("/orders/:id/confirm", orderDelivery.ConfirmOrder)
-	v1.GET("/orders/:id", orderDelivery.GetDetailOrder)
+	idempotency := middleware.IdempotencyMiddleware(redisClient)
+	v1.POST("/checkout", optionalCustomerAuth, checkoutLimit, idempotency, d.Checkout)
+	v1.POST("/orders/:id/pay", ordersLimit, orderDelivery.PayOrder)
+	v1.POST("/orders/:id/confirm", ordersLimit, idempotency, orderDelivery.ConfirmOrder)
+	v1.GET("/orders/:id", optionalCustomerAuth, ordersLimit, orderDelivery.GetDetailOrder)
+	v1.POST("/orders/:id/cancel", ordersLimit, orderDelivery.CancelOrder)
+	v1.POST("/payments/callback", orderDelivery.PaymentCallback)
+	admin.GET("/orders", ordersRead, orderDelivery.ListOrders)
+	admin.PUT("/orders/:id/status", ordersWrite, orderDelivery.UpdateStatus)
+	admin.GET("/orders/:id/history", ordersRead, orderDelivery.GetStatusHistory)
+	admin.POST("/orders/:id/refunds", refundsWrite, orderDelivery.RefundOrder)
+	admin.GET("/orders/:id/refunds", ordersRead, orderDelivery.GetRefunds)
+
+	// API Carts
+	v1.GET("/carts/:id", optionalCustomerAuth, publicLimit, cartDelivery.GetCart)
+	v1.POST("/carts/:id", optionalCustomerAuth, publicLimit, cartDelivery.AddCartItems)
+	v1.PATCH("/carts/:id", optionalCustomerAuth, publicLimit, cartDelivery.UpdateCart)
+	v1.DELETE("/carts/:id", optionalCustomerAuth, publicLimit, cartDelivery.DeleteCart)
+	v1.POST("/carts/:id/checkout", optionalCustomerAuth, checkoutLimit, idempotency, cartDelivery.CheckoutCart)
 
 	return router
 }
 
+// newEngine membuat engine gin yang hanya mempercayai header X-Forwarded-For
+// dari proxy pada TRUSTED_PROXIES. Tanpa konfigurasi, c.ClientIP() selalu
+// memakai alamat koneksi sehingga IP tidak bisa dipalsukan lewat header.
+func newEngine() (*gin.Engine, error) {
+	router := gin.Default()
+
+	var proxies []string
+	for _, proxy := range strings.Split(viper.GetString("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return router, router.SetTrustedProxies(proxies)
+}
+
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"online-shop/middleware"
+	"online-shop/model/entity"
+	"online-shop/usecase"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// allowListUsecase hanya menerima API key dari allowedIP
+type allowListUsecase struct {
+	usecase.APIKeyUsecase
+	allowedIP string
+}
+
+func (u allowListUsecase) Authenticate(c context.Context, rawKey string, clientIP string) (entity.APIKey, error) {
+	if clientIP != u.allowedIP {
+		return entity.APIKey{}, usecase.ErrAPIKeyIPBlocked
+	}
+	return entity.APIKey{ID: "key", Name: "test"}, nil
+}
+
+func TestAPIKeyAllowListIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies string
+		remoteAddr     string
+		forwardedFor   string
+		want           int
+	}{
+		{"spoofed header without trusted proxy", "", "203.0.113.5:4000", "10.0.0.1", http.StatusForbidden},
+		{"spoofed header from untrusted proxy", "192.168.0.0/16", "203.0.113.5:4000", "10.0.0.1", http.StatusForbidden},
+		{"header from trusted proxy", "192.168.0.0/16", "192.168.1.2:4000", "10.0.0.1", http.StatusOK},
+		{"direct connection from allowed IP", "", "10.0.0.1:4000", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("TRUSTED_PROXIES", tt.trustedProxies)
+			t.Cleanup(func() { viper.Set("TRUSTED_PROXIES", "") })
+
+			router, err := newEngine()
+			if err != nil {
+				t.Fatal(err)
+			}
+			router.GET("/admin/ping", middleware.APIKeyMiddleware(allowListUsecase{allowedIP: "10.0.0.1"}), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/ping", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-API-Key", "sk_test")
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package app
+
+import (
+	"log"
+	"online-shop/storage"
+
+	"github.com/spf13/viper"
+)
+
+func InitStorage() storage.Storage {
+	store, err := storage.NewStorage(
+		viper.GetString("STORAGE_DRIVER"),
+		storage.LocalOptions{
+			Dir:     viper.GetString("STORAGE_LOCAL_DIR"),
+			BaseURL: viper.GetString("STORAGE_BASE_URL"),
+		},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("storage initialized:", store.Name())
+	return store
+}
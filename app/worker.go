@@ -0,0 +1,27 @@
+package app
+
+import (
+	"online-shop/payment"
+	"online-shop/repository"
+	"online-shop/usecase"
+	"online-shop/worker"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+func InitWorker(postgresConn *gorm.DB, redisClient *redis.Client, paymentProvider payment.Provider) worker.OrderExpiryWorker {
+	orderRepo := repository.NewOrderRepository(postgresConn, redisClient)
+	paymentRepo := repository.NewPaymentRepository(postgresConn, redisClient)
+	attemptRepo := repository.NewPasscodeAttemptRepository(redisClient)
+	auditUsecase := usecase.NewAuditUsecase(repository.NewAuditRepository(postgresConn))
+	orderUsecase := usecase.NewOrderUsecase(orderRepo, paymentRepo, paymentProvider, attemptRepo, auditUsecase)
+
+	return worker.NewOrderExpiryWorker(
+		orderUsecase,
+		redisClient,
+		viper.GetString("ORDER_EXPIRY_LEASE_KEY"),
+		viper.GetDuration("ORDER_EXPIRY_INTERVAL"),
+	)
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Jenis token yang diterbitkan
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// Audience token, membedakan token pelanggan dari token lain
+const (
+	AudienceCustomer = "customer"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+type Claims struct {
+	Type string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// GenerateToken menerbitkan JWT HS256 untuk subject dengan masa berlaku ttl
+func GenerateToken(secret string, audience string, subject string, tokenType string, ttl time.Duration) (string, Claims, error) {
+	now := time.Now()
+	claims := Claims{
+		Type: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		return "", claims, err
+	}
+
+	return token, claims, nil
+}
+
+// ParseToken memverifikasi signature, masa berlaku, audience dan jenis token
+func ParseToken(secret string, audience string, tokenString string, tokenType string) (Claims, error) {
+	var claims Claims
+
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience(audience), jwt.WithExpirationRequired())
+	if err != nil {
+		return claims, ErrInvalidToken
+	}
+
+	if claims.Type != tokenType || claims.ID == "" || claims.Subject == "" {
+		return claims, ErrInvalidToken
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,186 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"online-shop/middleware"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminDelivery interface {
+	Login(c *gin.Context)
+	Logout(c *gin.Context)
+	RotateToken(c *gin.Context)
+	CreateUser(c *gin.Context)
+	ListUsers(c *gin.Context)
+	UpdateUser(c *gin.Context)
+	RevokeUserTokens(c *gin.Context)
+}
+
+type adminDelivery struct {
+	adminUsecase usecase.AdminUsecase
+	auditUsecase usecase.AuditUsecase
+}
+
+func NewAdminDelivery(adminUsecase usecase.AdminUsecase, auditUsecase usecase.AuditUsecase) AdminDelivery {
+	return &adminDelivery{adminUsecase, auditUsecase}
+}
+
+func (d *adminDelivery) Login(c *gin.Context) {
+	var input dto.ReqAdminLogin
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, errResult := d.adminUsecase.Login(c, input)
+	if errResult != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *adminDelivery) Logout(c *gin.Context) {
+	token := c.MustGet(middleware.AdminTokenKey).(entity.AdminToken)
+
+	err := d.adminUsecase.Logout(c, token.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "successfully logged out",
+	})
+}
+
+func (d *adminDelivery) RotateToken(c *gin.Context) {
+	admin := c.MustGet(middleware.AdminKey).(entity.AdminUser)
+	token := c.MustGet(middleware.AdminTokenKey).(entity.AdminToken)
+
+	result, err := d.adminUsecase.RotateToken(c, admin, token.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *adminDelivery) CreateUser(c *gin.Context) {
+	var input dto.ReqAdminUser
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.AdminUser
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		var err error
+		result, err = d.adminUsecase.CreateUser(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		return record("admin_user.create", "admin_user", result.ID, nil, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (d *adminDelivery) ListUsers(c *gin.Context) {
+	result, err := d.adminUsecase.ListUsers(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *adminDelivery) UpdateUser(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqUpdateAdminUser
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.AdminUser
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.adminUsecase.GetUser(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		result, err = d.adminUsecase.UpdateUser(ctx, id, input)
+		if err != nil {
+			return err
+		}
+
+		return record("admin_user.update", "admin_user", id, before, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *adminDelivery) RevokeUserTokens(c *gin.Context) {
+	id := c.Param("id")
+
+	err := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		err := d.adminUsecase.RevokeUserTokens(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return record("admin_user.revoke_tokens", "admin_user", id, nil, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "tokens successfully revoked",
+	})
+}
@@ -0,0 +1,93 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"online-shop/middleware"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyDelivery interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Revoke(c *gin.Context)
+}
+
+type apiKeyDelivery struct {
+	apiKeyUsecase usecase.APIKeyUsecase
+	auditUsecase  usecase.AuditUsecase
+}
+
+func NewAPIKeyDelivery(apiKeyUsecase usecase.APIKeyUsecase, auditUsecase usecase.AuditUsecase) APIKeyDelivery {
+	return &apiKeyDelivery{apiKeyUsecase, auditUsecase}
+}
+
+func (d *apiKeyDelivery) Create(c *gin.Context) {
+	var input dto.ReqAPIKey
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.APIKeyWithSecret
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		var err error
+		result, err = d.apiKeyUsecase.Create(ctx, input, c.GetString(middleware.ActorKey))
+		if err != nil {
+			return err
+		}
+
+		return record("api_key.create", "api_key", result.ID, nil, result.APIKey)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (d *apiKeyDelivery) List(c *gin.Context) {
+	result, err := d.apiKeyUsecase.List(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *apiKeyDelivery) Revoke(c *gin.Context) {
+	id := c.Param("id")
+
+	err := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		err := d.apiKeyUsecase.Revoke(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return record("api_key.revoke", "api_key", id, nil, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key successfully revoked",
+	})
+}
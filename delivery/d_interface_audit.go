@@ -0,0 +1,74 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"online-shop/middleware"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditDelivery interface {
+	ListAuditLogs(c *gin.Context)
+}
+
+type auditDelivery struct {
+	auditUsecase usecase.AuditUsecase
+}
+
+func NewAuditDelivery(auditUsecase usecase.AuditUsecase) AuditDelivery {
+	return &auditDelivery{auditUsecase}
+}
+
+func (d *auditDelivery) ListAuditLogs(c *gin.Context) {
+	var query dto.ReqAuditQuery
+
+	errBind := c.ShouldBindQuery(&query)
+	if errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errBind.Error(),
+		})
+		return
+	}
+
+	result, err := d.auditUsecase.List(c, query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// auditRecorder mencatat satu perubahan admin di dalam transaksi audited
+type auditRecorder func(action string, entityType string, entityID string, before any, after any) error
+
+// audited menjalankan perubahan admin dan pencatatan audit-nya dalam satu
+// transaksi database. fn harus memakai ctx untuk setiap pemanggilan usecase dan
+// memanggil record untuk setiap perubahan yang dilakukan. Jika perubahan atau
+// pencatatannya gagal, keduanya dibatalkan sehingga setiap perubahan admin
+// yang tersimpan pasti tercatat.
+func audited(c *gin.Context, auditUsecase usecase.AuditUsecase, fn func(ctx context.Context, record auditRecorder) error) error {
+	return auditUsecase.Transaction(c, func(ctx context.Context) error {
+		return fn(ctx, func(action string, entityType string, entityID string, before any, after any) error {
+			return auditUsecase.Record(ctx, auditEntry(c, action, entityType, entityID), before, after)
+		})
+	})
+}
+
+// auditEntry menyiapkan entri audit untuk perubahan oleh pemanggil request c
+func auditEntry(c *gin.Context, action string, entityType string, entityID string) entity.AuditLog {
+	return entity.AuditLog{
+		Actor:      c.GetString(middleware.ActorKey),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		RequestID:  c.GetString(middleware.RequestIDKey),
+		ClientIP:   c.ClientIP(),
+	}
+}
@@ -0,0 +1,172 @@
+package delivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"online-shop/middleware"
+	"online-shop/model/dto"
+	"online-shop/repository"
+	"online-shop/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CartDelivery interface {
+	GetCart(c *gin.Context)
+	AddCartItems(c *gin.Context)
+	UpdateCart(c *gin.Context)
+	DeleteCart(c *gin.Context)
+	CheckoutCart(c *gin.Context)
+	GetCustomerCart(c *gin.Context)
+}
+
+type cartDelivery struct {
+	cartUsecase usecase.CartUsecase
+	usecase     usecase.Usecase
+}
+
+func NewCartDelivery(cartUsecase usecase.CartUsecase, usecase usecase.Usecase) CartDelivery {
+	return &cartDelivery{cartUsecase, usecase}
+}
+
+func (d *cartDelivery) GetCart(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := d.cartUsecase.Get(c, id, c.GetString(middleware.CustomerIDKey))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *cartDelivery) AddCartItems(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqCartItems
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, errResult := d.cartUsecase.AddItems(c, id, c.GetString(middleware.CustomerIDKey), input)
+	writeCartResult(c, result, errResult)
+}
+
+func (d *cartDelivery) UpdateCart(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqCartUpdate
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, errResult := d.cartUsecase.Update(c, id, c.GetString(middleware.CustomerIDKey), input)
+	writeCartResult(c, result, errResult)
+}
+
+func (d *cartDelivery) DeleteCart(c *gin.Context) {
+	id := c.Param("id")
+
+	err := d.cartUsecase.Delete(c, id, c.GetString(middleware.CustomerIDKey))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "cart deleted",
+	})
+}
+
+func (d *cartDelivery) CheckoutCart(c *gin.Context) {
+	id := c.Param("id")
+	customerID := c.GetString(middleware.CustomerIDKey)
+	var input dto.ReqCartCheckout
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	checkout, errCart := d.cartUsecase.PrepareCheckout(c, id, customerID, input)
+	if errCart != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errCart.Error(),
+		})
+		return
+	}
+
+	result, errResult := d.usecase.Checkout(c, checkout)
+	var outOfStock *repository.OutOfStockError
+	if errors.As(errResult, &outOfStock) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      errResult.Error(),
+			"productIds": outOfStock.ProductIDs,
+			"variantIds": outOfStock.VariantIDs,
+		})
+		return
+	}
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	// Pesanan sudah dibuat, keranjang yang gagal dihapus akan kedaluwarsa
+	// sendiri sesuai TTL
+	err = d.cartUsecase.Delete(c, id, customerID)
+	if err != nil {
+		log.Println("error delete cart after checkout:", id, err)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *cartDelivery) GetCustomerCart(c *gin.Context) {
+	result, err := d.cartUsecase.GetByCustomerID(c, c.GetString(middleware.CustomerIDKey))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// writeCartResult menulis hasil perubahan keranjang, perubahan yang bentrok
+// dengan request lain dikembalikan sebagai 409 agar klien mengulang
+func writeCartResult(c *gin.Context, result dto.ResCart, err error) {
+	if errors.Is(err, repository.ErrCartConflict) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
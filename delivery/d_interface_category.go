@@ -0,0 +1,187 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryDelivery interface {
+	GetCategories(c *gin.Context)
+	CreateCategory(c *gin.Context)
+	UpdateCategory(c *gin.Context)
+	DeleteCategory(c *gin.Context)
+	GetProductCategories(c *gin.Context)
+	SetProductCategories(c *gin.Context)
+}
+
+type categoryDelivery struct {
+	categoryUsecase usecase.CategoryUsecase
+	auditUsecase    usecase.AuditUsecase
+}
+
+func NewCategoryDelivery(categoryUsecase usecase.CategoryUsecase, auditUsecase usecase.AuditUsecase) CategoryDelivery {
+	return &categoryDelivery{categoryUsecase, auditUsecase}
+}
+
+func (d *categoryDelivery) GetCategories(c *gin.Context) {
+	result, err := d.categoryUsecase.GetTree(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *categoryDelivery) CreateCategory(c *gin.Context) {
+	var input dto.ReqCategory
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.Category
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		var err error
+		result, err = d.categoryUsecase.Create(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		return record("category.create", "category", result.ID, nil, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (d *categoryDelivery) UpdateCategory(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqCategory
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.Category
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.categoryUsecase.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		result, err = d.categoryUsecase.Update(ctx, id, input)
+		if err != nil {
+			return err
+		}
+
+		return record("category.update", "category", id, before, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *categoryDelivery) DeleteCategory(c *gin.Context) {
+	id := c.Param("id")
+
+	err := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.categoryUsecase.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = d.categoryUsecase.Delete(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return record("category.delete", "category", id, before, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "category successfully deleted",
+	})
+}
+
+func (d *categoryDelivery) GetProductCategories(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := d.categoryUsecase.GetProductCategories(c, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *categoryDelivery) SetProductCategories(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqProductCategories
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result []entity.Category
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.categoryUsecase.GetProductCategories(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		result, err = d.categoryUsecase.SetProductCategories(ctx, id, input)
+		if err != nil {
+			return err
+		}
+
+		return record("product.set_categories", "product", id,
+			gin.H{"categories": before}, gin.H{"categories": result})
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,164 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"online-shop/auth"
+	"online-shop/middleware"
+	"online-shop/model/dto"
+	"online-shop/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CustomerDelivery interface {
+	Register(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	Login(c *gin.Context)
+	Refresh(c *gin.Context)
+	Logout(c *gin.Context)
+	GetProfile(c *gin.Context)
+}
+
+type customerDelivery struct {
+	customerUsecase usecase.CustomerUsecase
+}
+
+func NewCustomerDelivery(customerUsecase usecase.CustomerUsecase) CustomerDelivery {
+	return &customerDelivery{customerUsecase}
+}
+
+func (d *customerDelivery) Register(c *gin.Context) {
+	var input dto.ReqRegister
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, errResult := d.customerUsecase.Register(c, input)
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (d *customerDelivery) VerifyEmail(c *gin.Context) {
+	var input dto.ReqVerifyEmail
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, errResult := d.customerUsecase.VerifyEmail(c, input.Token)
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *customerDelivery) Login(c *gin.Context) {
+	var input dto.ReqLogin
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, errResult := d.customerUsecase.Login(c, input)
+	if errResult != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *customerDelivery) Refresh(c *gin.Context) {
+	var input dto.ReqRefreshToken
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, errResult := d.customerUsecase.Refresh(c, input.RefreshToken)
+	if errors.Is(errResult, auth.ErrInvalidToken) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *customerDelivery) Logout(c *gin.Context) {
+	var input dto.ReqLogout
+
+	// Body boleh kosong, refresh token hanya dicabut jika dikirim
+	if c.Request.ContentLength > 0 {
+		err := c.ShouldBindJSON(&input)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+
+	claims := c.MustGet(middleware.CustomerClaimsKey).(auth.Claims)
+
+	err := d.customerUsecase.Logout(c, claims, input.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "successfully logged out",
+	})
+}
+
+func (d *customerDelivery) GetProfile(c *gin.Context) {
+	result, err := d.customerUsecase.GetProfile(c, c.GetString(middleware.CustomerIDKey))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,165 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// multipartOverhead adalah ruang untuk boundary dan header setiap part
+const multipartOverhead = 1 << 20
+
+type ImageDelivery interface {
+	GetImages(c *gin.Context)
+	UploadImages(c *gin.Context)
+	DeleteImage(c *gin.Context)
+	ReorderImages(c *gin.Context)
+}
+
+type imageDelivery struct {
+	imageUsecase usecase.ImageUsecase
+	auditUsecase usecase.AuditUsecase
+}
+
+func NewImageDelivery(imageUsecase usecase.ImageUsecase, auditUsecase usecase.AuditUsecase) ImageDelivery {
+	return &imageDelivery{imageUsecase, auditUsecase}
+}
+
+func (d *imageDelivery) GetImages(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := d.imageUsecase.GetByProductID(c, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *imageDelivery) UploadImages(c *gin.Context) {
+	id := c.Param("id")
+
+	// Body dibatasi sebelum di-parse, batas per file diperiksa lagi oleh usecase
+	maxBody := viper.GetInt64("PRODUCT_IMAGE_MAX_COUNT")*viper.GetInt64("PRODUCT_IMAGE_MAX_SIZE") + multipartOverhead
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBody)
+
+	form, err := c.MultipartForm()
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result []entity.ProductImage
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		var err error
+		result, err = d.imageUsecase.Upload(ctx, id, form.File["images"])
+		if err != nil {
+			return err
+		}
+
+		for _, image := range result {
+			err = record("product.upload_image", "product", id, nil, image)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (d *imageDelivery) DeleteImage(c *gin.Context) {
+	id := c.Param("id")
+	imageID := c.Param("imageId")
+
+	var result entity.ProductImage
+	err := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		var err error
+		result, err = d.imageUsecase.Delete(ctx, id, imageID)
+		if err != nil {
+			return err
+		}
+
+		return record("product.delete_image", "product", id, result, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "image successfully deleted",
+	})
+}
+
+func (d *imageDelivery) ReorderImages(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqImageOrder
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result []entity.ProductImage
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.imageUsecase.GetByProductID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		result, err = d.imageUsecase.Reorder(ctx, id, input)
+		if err != nil {
+			return err
+		}
+
+		return record("product.reorder_images", "product", id,
+			gin.H{"images": imageIDs(before)}, gin.H{"images": imageIDs(result)})
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func imageIDs(images []entity.ProductImage) []string {
+	ids := make([]string, 0, len(images))
+	for _, image := range images {
+		ids = append(ids, image.ID)
+	}
+	return ids
+}
@@ -1,26 +1,45 @@
 package delivery
 
 import (
+	"context"
+	"errors"
+	"io"
+	"math"
 	"net/http"
+	"online-shop/middleware"
+	"online-shop/model/dto"
 	"online-shop/model/entity"
+	"online-shop/payment"
+	"online-shop/repository"
 	"online-shop/usecase"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type OrderDelivery interface {
 	CreateOrder(c *gin.Context)
+	PayOrder(c *gin.Context)
 	ConfirmOrder(c *gin.Context)
+	PaymentCallback(c *gin.Context)
 	GetDetailOrder(c *gin.Context)
+	UpdateStatus(c *gin.Context)
+	GetStatusHistory(c *gin.Context)
+	CancelOrder(c *gin.Context)
+	RefundOrder(c *gin.Context)
+	GetRefunds(c *gin.Context)
+	ListOrders(c *gin.Context)
+	ListCustomerOrders(c *gin.Context)
 }
 
 type orderDelivery struct {
 	usecase      usecase.Usecase
 	orderUsecase usecase.OrderUsecase
+	auditUsecase usecase.AuditUsecase
 }
 
-func NewOrderDelivery(usecase usecase.Usecase, orderUsecase usecase.OrderUsecase) OrderDelivery {
-	return &orderDelivery{usecase, orderUsecase}
+func NewOrderDelivery(usecase usecase.Usecase, orderUsecase usecase.OrderUsecase, auditUsecase usecase.AuditUsecase) OrderDelivery {
+	return &orderDelivery{usecase, orderUsecase, auditUsecase}
 }
 
 func (d *orderDelivery) CreateOrder(c *gin.Context) {
@@ -34,7 +53,17 @@ func (d *orderDelivery) CreateOrder(c *gin.Context) {
 		return
 	}
 
+	input.CustomerID = c.GetString(middleware.CustomerIDKey)
+
 	result, errResult := d.usecase.Checkout(c, input)
+	var outOfStock *repository.OutOfStockError
+	if errors.As(errResult, &outOfStock) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      errResult.Error(),
+			"productIds": outOfStock.ProductIDs,
+		})
+		return
+	}
 	if errResult != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": errResult.Error(),
@@ -45,6 +74,67 @@ func (d *orderDelivery) CreateOrder(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+func (d *orderDelivery) PayOrder(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqPayOrder
+
+	errBind := c.ShouldBindJSON(&input)
+	if errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errBind.Error(),
+		})
+		return
+	}
+
+	result, errResult := d.orderUsecase.Pay(c, id, input.Passcode, c.ClientIP())
+	if passcodeLocked(c, errResult) {
+		return
+	}
+	var invalidTransition *usecase.InvalidTransitionError
+	if errors.As(errResult, &invalidTransition) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *orderDelivery) PaymentCallback(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	err = d.orderUsecase.HandlePaymentCallback(c, payload, c.GetHeader(payment.SignatureHeader))
+	if errors.Is(err, payment.ErrInvalidSignature) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "callback processed",
+	})
+}
+
 func (d *orderDelivery) ConfirmOrder(c *gin.Context) {
 	id := c.Param("id")
 	var order entity.Confirm
@@ -57,7 +147,17 @@ func (d *orderDelivery) ConfirmOrder(c *gin.Context) {
 		return
 	}
 
-	result, errResult := d.orderUsecase.Confirm(c, id, order)
+	result, errResult := d.orderUsecase.Confirm(c, id, order, c.ClientIP())
+	if passcodeLocked(c, errResult) {
+		return
+	}
+	var invalidTransition *usecase.InvalidTransitionError
+	if errors.As(errResult, &invalidTransition) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
 	if errResult != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": errResult.Error(),
@@ -71,8 +171,12 @@ func (d *orderDelivery) ConfirmOrder(c *gin.Context) {
 func (d *orderDelivery) GetDetailOrder(c *gin.Context) {
 	id := c.Param("id")
 	passcode := c.Query("passcode")
+	customerID := c.GetString(middleware.CustomerIDKey)
 
-	result, err := d.orderUsecase.GetDetailOrder(c, id, passcode)
+	result, err := d.orderUsecase.GetDetailOrder(c, id, passcode, customerID, c.ClientIP())
+	if passcodeLocked(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
@@ -82,3 +186,203 @@ func (d *orderDelivery) GetDetailOrder(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+func (d *orderDelivery) UpdateStatus(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqOrderStatus
+
+	errBind := c.ShouldBindJSON(&input)
+	if errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errBind.Error(),
+		})
+		return
+	}
+
+	var result entity.Order
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.orderUsecase.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		result, err = d.orderUsecase.UpdateStatus(ctx, id, input, c.GetString(middleware.ActorKey))
+		if err != nil {
+			return err
+		}
+
+		return record("order.update_status", "order", id, before, result)
+	})
+	var invalidTransition *usecase.InvalidTransitionError
+	if errors.As(errResult, &invalidTransition) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *orderDelivery) GetStatusHistory(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := d.orderUsecase.GetStatusHistory(c, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *orderDelivery) CancelOrder(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqCancelOrder
+
+	errBind := c.ShouldBindJSON(&input)
+	if errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errBind.Error(),
+		})
+		return
+	}
+
+	result, errResult := d.orderUsecase.Cancel(c, id, input.Passcode, c.ClientIP())
+	if passcodeLocked(c, errResult) {
+		return
+	}
+	var invalidTransition *usecase.InvalidTransitionError
+	if errors.As(errResult, &invalidTransition) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *orderDelivery) RefundOrder(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqRefund
+
+	errBind := c.ShouldBindJSON(&input)
+	if errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errBind.Error(),
+		})
+		return
+	}
+
+	// Refund ke payment provider tidak bisa dibatalkan, jadi audit dicatat
+	// usecase bersama status akhir refund, bukan dalam satu transaksi dengan
+	// seluruh proses refund
+	result, errResult := d.orderUsecase.Refund(c, id, input, auditEntry(c, "order.refund", "order", id))
+	if errors.Is(errResult, repository.ErrRefundConflict) || errors.Is(errResult, repository.ErrOrderStatusConflict) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *orderDelivery) GetRefunds(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := d.orderUsecase.GetRefunds(c, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *orderDelivery) ListOrders(c *gin.Context) {
+	var query dto.ReqOrderQuery
+
+	errBind := c.ShouldBindQuery(&query)
+	if errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errBind.Error(),
+		})
+		return
+	}
+
+	result, err := d.orderUsecase.List(c, query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *orderDelivery) ListCustomerOrders(c *gin.Context) {
+	var query dto.ReqOrderQuery
+
+	errBind := c.ShouldBindQuery(&query)
+	if errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errBind.Error(),
+		})
+		return
+	}
+
+	// Pelanggan hanya bisa melihat pesanannya sendiri
+	query.CustomerID = c.GetString(middleware.CustomerIDKey)
+	query.Email = ""
+
+	result, err := d.orderUsecase.List(c, query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// passcodeLocked mengirim 423 beserta Retry-After jika percobaan passcode sedang dikunci
+func passcodeLocked(c *gin.Context, err error) bool {
+	var locked *usecase.PasscodeLockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(locked.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusLocked, gin.H{
+		"error":      err.Error(),
+		"retryAfter": retryAfter,
+	})
+	return true
+}
@@ -1,33 +1,65 @@
 package delivery
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"online-shop/middleware"
 	"online-shop/model/dto"
 	"online-shop/model/entity"
+	"online-shop/repository"
 	"online-shop/usecase"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
 )
 
 type Delivery interface {
 	Checkout(c *gin.Context)
 	GetProducts(c *gin.Context)
+	SearchProducts(c *gin.Context)
 	GetProductbyID(c *gin.Context)
 	CreateProduct(c *gin.Context)
 	UpdateProduct(c *gin.Context)
 	DeleteProduct(c *gin.Context)
+	AdjustStock(c *gin.Context)
+	GetStockMovements(c *gin.Context)
+	ImportProducts(c *gin.Context)
+	ExportProducts(c *gin.Context)
+	GetProductPrices(c *gin.Context)
+	SetProductPrices(c *gin.Context)
 }
 
 type delivery struct {
-	usecase usecase.Usecase
+	usecase      usecase.Usecase
+	auditUsecase usecase.AuditUsecase
 }
 
-func NewDelivery(usecase usecase.Usecase) Delivery {
-	return &delivery{usecase}
+func NewDelivery(usecase usecase.Usecase, auditUsecase usecase.AuditUsecase) Delivery {
+	return &delivery{usecase, auditUsecase}
 }
 
 func (d *delivery) GetProducts(c *gin.Context) {
-	result, err := d.usecase.GetAll(c)
+	var query dto.ReqProductQuery
+
+	errBind := c.ShouldBindQuery(&query)
+	if errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errBind.Error(),
+		})
+		return
+	}
+
+	result, err := d.usecase.GetAll(c, query)
+	if errors.Is(err, usecase.ErrInvalidPriceRange) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
@@ -38,6 +70,28 @@ func (d *delivery) GetProducts(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+func (d *delivery) SearchProducts(c *gin.Context) {
+	var query dto.ReqProductSearch
+
+	errBind := c.ShouldBindQuery(&query)
+	if errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errBind.Error(),
+		})
+		return
+	}
+
+	result, err := d.usecase.Search(c, query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func (d *delivery) GetProductbyID(c *gin.Context) {
 	id := c.Param("id")
 
@@ -63,7 +117,16 @@ func (d *delivery) CreateProduct(c *gin.Context) {
 		return
 	}
 
-	result, errResult := d.usecase.Create(c, input)
+	var result entity.Product
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		var err error
+		result, err = d.usecase.Create(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		return record("product.create", "product", result.ID, nil, result)
+	})
 	if errResult != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": errResult.Error(),
@@ -86,7 +149,20 @@ func (d *delivery) UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	result, errResult := d.usecase.Update(c, id, input)
+	var result entity.Product
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.usecase.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		result, err = d.usecase.Update(ctx, id, input)
+		if err != nil {
+			return err
+		}
+
+		return record("product.update", "product", id, before, result)
+	})
 	if errResult != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": errResult.Error(),
@@ -100,7 +176,19 @@ func (d *delivery) UpdateProduct(c *gin.Context) {
 func (d *delivery) DeleteProduct(c *gin.Context) {
 	id := c.Param("id")
 
-	err := d.usecase.Delete(c, id)
+	err := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.usecase.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = d.usecase.Delete(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return record("product.delete", "product", id, before, nil)
+	})
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
@@ -113,6 +201,176 @@ func (d *delivery) DeleteProduct(c *gin.Context) {
 	})
 }
 
+func (d *delivery) AdjustStock(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqStockAdjustment
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.Product
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.usecase.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		result, err = d.usecase.AdjustStock(ctx, id, input)
+		if err != nil {
+			return err
+		}
+
+		return record("product.adjust_stock", "product", id, before, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *delivery) GetStockMovements(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := d.usecase.GetStockMovements(c, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *delivery) GetProductPrices(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := d.usecase.GetPrices(c, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *delivery) SetProductPrices(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqProductPrices
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result []entity.ProductPrice
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.usecase.GetPrices(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		result, err = d.usecase.SetPrices(ctx, id, input)
+		if err != nil {
+			return err
+		}
+
+		return record("product.set_prices", "product", id, before, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *delivery) ImportProducts(c *gin.Context) {
+	var query dto.ReqProductImport
+
+	errBind := c.ShouldBindQuery(&query)
+	if errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errBind.Error(),
+		})
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, viper.GetInt64("PRODUCT_IMPORT_MAX_SIZE"))
+
+	var result dto.ResProductImport
+	err := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		var err error
+		result, err = d.usecase.Import(ctx, query, body)
+		if err != nil || !result.Applied {
+			return err
+		}
+
+		return record("product.import", "product", result.Reference, nil, result)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Tidak ada perubahan yang disimpan jika masih ada baris yang gagal
+	if len(result.Errors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *delivery) ExportProducts(c *gin.Context) {
+	var query dto.ReqProductExport
+
+	errBind := c.ShouldBindQuery(&query)
+	if errBind != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errBind.Error(),
+		})
+		return
+	}
+
+	if query.Format == "" {
+		query.Format = "csv"
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if query.Format == "jsonl" {
+		contentType = "application/x-ndjson"
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="products-%s.%s"`, time.Now().Format("20060102"), query.Format))
+	c.Status(http.StatusOK)
+
+	// Header sudah terkirim, jadi error di tengah stream hanya bisa di-log
+	err := d.usecase.Export(c, query.Format, c.Writer)
+	if err != nil {
+		log.Println("error export products:", err)
+	}
+}
+
 func (d *delivery) Checkout(c *gin.Context) {
 	var input entity.Checkout
 
@@ -124,7 +382,18 @@ func (d *delivery) Checkout(c *gin.Context) {
 		return
 	}
 
+	input.CustomerID = c.GetString(middleware.CustomerIDKey)
+
 	result, errResult := d.usecase.Checkout(c, input)
+	var outOfStock *repository.OutOfStockError
+	if errors.As(errResult, &outOfStock) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      errResult.Error(),
+			"productIds": outOfStock.ProductIDs,
+			"variantIds": outOfStock.VariantIDs,
+		})
+		return
+	}
 	if errResult != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": errResult.Error(),
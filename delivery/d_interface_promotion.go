@@ -0,0 +1,149 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionDelivery interface {
+	ListPromotions(c *gin.Context)
+	GetPromotion(c *gin.Context)
+	CreatePromotion(c *gin.Context)
+	UpdatePromotion(c *gin.Context)
+	DeletePromotion(c *gin.Context)
+}
+
+type promotionDelivery struct {
+	promotionUsecase usecase.PromotionUsecase
+	auditUsecase     usecase.AuditUsecase
+}
+
+func NewPromotionDelivery(promotionUsecase usecase.PromotionUsecase, auditUsecase usecase.AuditUsecase) PromotionDelivery {
+	return &promotionDelivery{promotionUsecase, auditUsecase}
+}
+
+func (d *promotionDelivery) ListPromotions(c *gin.Context) {
+	result, err := d.promotionUsecase.List(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *promotionDelivery) GetPromotion(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := d.promotionUsecase.GetByID(c, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *promotionDelivery) CreatePromotion(c *gin.Context) {
+	var input dto.ReqPromotion
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.Promotion
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		var err error
+		result, err = d.promotionUsecase.Create(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		return record("promotion.create", "promotion", result.ID, nil, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (d *promotionDelivery) UpdatePromotion(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqPromotion
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.Promotion
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.promotionUsecase.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		result, err = d.promotionUsecase.Update(ctx, id, input)
+		if err != nil {
+			return err
+		}
+
+		return record("promotion.update", "promotion", id, before, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *promotionDelivery) DeletePromotion(c *gin.Context) {
+	id := c.Param("id")
+
+	err := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.promotionUsecase.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = d.promotionUsecase.Delete(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return record("promotion.delete", "promotion", id, before, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "promotion successfully deleted",
+	})
+}
@@ -0,0 +1,172 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShippingDelivery interface {
+	ListShippingMethods(c *gin.Context)
+	GetShippingMethod(c *gin.Context)
+	CreateShippingMethod(c *gin.Context)
+	UpdateShippingMethod(c *gin.Context)
+	DeleteShippingMethod(c *gin.Context)
+	QuoteShipping(c *gin.Context)
+}
+
+type shippingDelivery struct {
+	shippingUsecase usecase.ShippingUsecase
+	auditUsecase    usecase.AuditUsecase
+}
+
+func NewShippingDelivery(shippingUsecase usecase.ShippingUsecase, auditUsecase usecase.AuditUsecase) ShippingDelivery {
+	return &shippingDelivery{shippingUsecase, auditUsecase}
+}
+
+func (d *shippingDelivery) ListShippingMethods(c *gin.Context) {
+	result, err := d.shippingUsecase.List(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *shippingDelivery) GetShippingMethod(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := d.shippingUsecase.GetByID(c, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *shippingDelivery) CreateShippingMethod(c *gin.Context) {
+	var input dto.ReqShippingMethod
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.ShippingMethod
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		var err error
+		result, err = d.shippingUsecase.Create(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		return record("shipping_method.create", "shipping_method", result.ID, nil, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (d *shippingDelivery) UpdateShippingMethod(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqShippingMethod
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.ShippingMethod
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.shippingUsecase.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		result, err = d.shippingUsecase.Update(ctx, id, input)
+		if err != nil {
+			return err
+		}
+
+		return record("shipping_method.update", "shipping_method", id, before, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *shippingDelivery) DeleteShippingMethod(c *gin.Context) {
+	id := c.Param("id")
+
+	err := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.shippingUsecase.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = d.shippingUsecase.Delete(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return record("shipping_method.delete", "shipping_method", id, before, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "shipping method successfully deleted",
+	})
+}
+
+func (d *shippingDelivery) QuoteShipping(c *gin.Context) {
+	var input dto.ReqShippingQuote
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, errResult := d.shippingUsecase.Quote(c, input)
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
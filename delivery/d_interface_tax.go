@@ -0,0 +1,203 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TaxDelivery interface {
+	ListTaxClasses(c *gin.Context)
+	CreateTaxClass(c *gin.Context)
+	DeleteTaxClass(c *gin.Context)
+	ListTaxRates(c *gin.Context)
+	CreateTaxRate(c *gin.Context)
+	UpdateTaxRate(c *gin.Context)
+	DeleteTaxRate(c *gin.Context)
+}
+
+type taxDelivery struct {
+	taxUsecase   usecase.TaxUsecase
+	auditUsecase usecase.AuditUsecase
+}
+
+func NewTaxDelivery(taxUsecase usecase.TaxUsecase, auditUsecase usecase.AuditUsecase) TaxDelivery {
+	return &taxDelivery{taxUsecase, auditUsecase}
+}
+
+func (d *taxDelivery) ListTaxClasses(c *gin.Context) {
+	result, err := d.taxUsecase.ListClasses(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *taxDelivery) CreateTaxClass(c *gin.Context) {
+	var input dto.ReqTaxClass
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.TaxClass
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		var err error
+		result, err = d.taxUsecase.CreateClass(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		return record("tax_class.create", "tax_class", result.Code, nil, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (d *taxDelivery) DeleteTaxClass(c *gin.Context) {
+	code := c.Param("code")
+
+	err := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		err := d.taxUsecase.DeleteClass(ctx, code)
+		if err != nil {
+			return err
+		}
+
+		return record("tax_class.delete", "tax_class", code, gin.H{"code": code}, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "tax class successfully deleted",
+	})
+}
+
+func (d *taxDelivery) ListTaxRates(c *gin.Context) {
+	result, err := d.taxUsecase.ListRates(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *taxDelivery) CreateTaxRate(c *gin.Context) {
+	var input dto.ReqTaxRate
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.TaxRate
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		var err error
+		result, err = d.taxUsecase.CreateRate(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		return record("tax_rate.create", "tax_rate", result.ID, nil, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (d *taxDelivery) UpdateTaxRate(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqTaxRate
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.TaxRate
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.taxUsecase.GetRate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		result, err = d.taxUsecase.UpdateRate(ctx, id, input)
+		if err != nil {
+			return err
+		}
+
+		return record("tax_rate.update", "tax_rate", id, before, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *taxDelivery) DeleteTaxRate(c *gin.Context) {
+	id := c.Param("id")
+
+	err := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.taxUsecase.GetRate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = d.taxUsecase.DeleteRate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return record("tax_rate.delete", "tax_rate", id, before, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "tax rate successfully deleted",
+	})
+}
@@ -0,0 +1,215 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VariantDelivery interface {
+	GetVariants(c *gin.Context)
+	SetOptions(c *gin.Context)
+	CreateVariant(c *gin.Context)
+	UpdateVariant(c *gin.Context)
+	DeleteVariant(c *gin.Context)
+	AdjustStock(c *gin.Context)
+}
+
+type variantDelivery struct {
+	variantUsecase usecase.VariantUsecase
+	auditUsecase   usecase.AuditUsecase
+}
+
+func NewVariantDelivery(variantUsecase usecase.VariantUsecase, auditUsecase usecase.AuditUsecase) VariantDelivery {
+	return &variantDelivery{variantUsecase, auditUsecase}
+}
+
+func (d *variantDelivery) GetVariants(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := d.variantUsecase.GetVariants(c, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *variantDelivery) SetOptions(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqProductOptions
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result []entity.ProductOption
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.variantUsecase.GetVariants(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		result, err = d.variantUsecase.SetOptions(ctx, id, input)
+		if err != nil {
+			return err
+		}
+
+		return record("product.set_options", "product", id,
+			gin.H{"options": before.Options}, gin.H{"options": result})
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *variantDelivery) CreateVariant(c *gin.Context) {
+	id := c.Param("id")
+	var input dto.ReqVariant
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.ProductVariant
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		var err error
+		result, err = d.variantUsecase.Create(ctx, id, input)
+		if err != nil {
+			return err
+		}
+
+		return record("variant.create", "variant", result.ID, nil, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (d *variantDelivery) UpdateVariant(c *gin.Context) {
+	id := c.Param("id")
+	variantID := c.Param("variantId")
+	var input dto.ReqVariant
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.ProductVariant
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.variantUsecase.GetByID(ctx, id, variantID)
+		if err != nil {
+			return err
+		}
+
+		result, err = d.variantUsecase.Update(ctx, id, variantID, input)
+		if err != nil {
+			return err
+		}
+
+		return record("variant.update", "variant", variantID, before, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (d *variantDelivery) DeleteVariant(c *gin.Context) {
+	id := c.Param("id")
+	variantID := c.Param("variantId")
+
+	err := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.variantUsecase.GetByID(ctx, id, variantID)
+		if err != nil {
+			return err
+		}
+
+		err = d.variantUsecase.Delete(ctx, id, variantID)
+		if err != nil {
+			return err
+		}
+
+		return record("variant.delete", "variant", variantID, before, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "variant successfully deleted",
+	})
+}
+
+func (d *variantDelivery) AdjustStock(c *gin.Context) {
+	id := c.Param("id")
+	variantID := c.Param("variantId")
+	var input dto.ReqStockAdjustment
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var result entity.ProductVariant
+	errResult := audited(c, d.auditUsecase, func(ctx context.Context, record auditRecorder) error {
+		before, err := d.variantUsecase.GetByID(ctx, id, variantID)
+		if err != nil {
+			return err
+		}
+
+		result, err = d.variantUsecase.AdjustStock(ctx, id, variantID, input)
+		if err != nil {
+			return err
+		}
+
+		return record("variant.adjust_stock", "variant", variantID, before, result)
+	})
+	if errResult != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,57 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+)
+
+// Thumbnail memperkecil gambar agar sisi terpanjangnya tidak melebihi
+// maxSize dengan mempertahankan rasio. Setiap piksel hasil adalah rata-rata
+// piksel sumber yang diwakilinya, sehingga hasilnya tidak bergerigi.
+func Thumbnail(src image.Image, maxSize int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	targetWidth, targetHeight := width, height
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			targetWidth = maxSize
+			targetHeight = max(height*maxSize/width, 1)
+		} else {
+			targetHeight = maxSize
+			targetWidth = max(width*maxSize/height, 1)
+		}
+	}
+
+	dst := image.NewRGBA64(image.Rect(0, 0, targetWidth, targetHeight))
+	for y := 0; y < targetHeight; y++ {
+		y0 := bounds.Min.Y + y*height/targetHeight
+		y1 := max(bounds.Min.Y+(y+1)*height/targetHeight, y0+1)
+
+		for x := 0; x < targetWidth; x++ {
+			x0 := bounds.Min.X + x*width/targetWidth
+			x1 := max(bounds.Min.X+(x+1)*width/targetWidth, x0+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+
+			dst.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
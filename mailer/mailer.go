@@ -0,0 +1,24 @@
+package mailer
+
+import (
+	"context"
+	"log"
+)
+
+// Mailer mengirim email transaksional ke pelanggan
+type Mailer interface {
+	Send(c context.Context, to string, subject string, body string) error
+}
+
+type logMailer struct{}
+
+// NewLogMailer membuat mailer yang hanya menulis email ke log, dipakai
+// selama layanan email belum diintegrasikan
+func NewLogMailer() Mailer {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(c context.Context, to string, subject string, body string) error {
+	log.Printf("mail to %s: %s\n%s", to, subject, body)
+	return nil
+}
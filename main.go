@@ -3,10 +3,13 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"online-shop/app"
 	"online-shop/config"
+	"online-shop/migration"
+	"online-shop/model/entity"
 	"os"
 	"os/signal"
 	"time"
@@ -16,11 +19,20 @@ import (
 
 func main() {
 	envF := flag.String("env", "local", "define environment")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-env <environment>] [migrate up|down [steps]|status]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	env := *envF
 	config.InitConfig(env)
 
+	// Mata uang dasar untuk harga produk dan data lama
+	if err := entity.SetBaseCurrency(viper.GetString("CURRENCY_DEFAULT")); err != nil {
+		log.Fatal(err)
+	}
+
 	// Inisialisasi koneksi PostgreSQL
 	postgresConn, errPostgres := config.ConnectPostgreSQL()
 	if errPostgres != nil {
@@ -32,13 +44,36 @@ func main() {
 		}
 	}()
 
+	// Subcommand migrate dijalankan tanpa menyalakan server
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(postgresConn, flag.Args()[1:]); err != nil {
+			log.Fatal("migrate: ", err)
+		}
+		return
+	}
+
+	// Server tidak dijalankan jika skema database belum terbaru
+	if err := migration.CheckLatest(postgresConn); err != nil {
+		log.Fatal(err)
+	}
+
 	// Inisialisasi koneksi Redis
 	redisClient := config.NewRedisClient()
 
+	// Inisialisasi payment provider
+	paymentProvider := app.InitPaymentProvider()
+
+	// Inisialisasi storage untuk file media
+	store := app.InitStorage()
+
 	// Inisialisasi router dengan koneksi PostgreSQL dan Redis
-	router := app.InitRouter(postgresConn, redisClient)
+	router := app.InitRouter(postgresConn, redisClient, paymentProvider, store)
 	log.Println("routes initialized")
 
+	// Menjalankan worker untuk pesanan yang tidak dibayar
+	expiryWorker := app.InitWorker(postgresConn, redisClient, paymentProvider)
+	expiryWorker.Start()
+
 	// Mendapatkan port dari konfigurasi
 	port := viper.GetString("PORT")
 
@@ -69,5 +104,8 @@ func main() {
 		log.Fatal("Server Shutdown:", err)
 	}
 
+	// Menghentikan worker setelah server berhenti menerima request
+	expiryWorker.Stop()
+
 	log.Println("Server exiting")
 }
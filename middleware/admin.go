@@ -1,26 +1,88 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"online-shop/model/entity"
+	"online-shop/usecase"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/spf13/viper"
 )
 
-func HeaderMiddleware() gin.HandlerFunc {
+// Key di gin.Context untuk admin yang sudah terautentikasi
+const (
+	AdminKey      = "admin"
+	AdminTokenKey = "adminToken"
+	ActorKey      = "actor"
+)
+
+// HeaderMiddleware memverifikasi token admin dari header Authorization: Bearer
+func HeaderMiddleware(adminUsecase usecase.AdminUsecase) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		key := viper.GetString("AUTHORIZATION_KEY")
+		// Sudah diautentikasi oleh APIKeyMiddleware
+		if _, ok := c.Get(APIKeyKey); ok {
+			c.Next()
+			return
+		}
+
 		auth := c.Request.Header.Get("Authorization")
 		if auth == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid 'Authorization' header."})
 			return
 		}
 
-		if auth != key {
+		token, ok := strings.CutPrefix(auth, "Bearer ")
+		if !ok || token == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid 'Authorization' header."})
 			return
 		}
 
+		admin, adminToken, err := adminUsecase.Authenticate(c, token)
+		if errors.Is(err, usecase.ErrInvalidAdminToken) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid 'Authorization' header."})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(AdminKey, admin)
+		c.Set(AdminTokenKey, adminToken)
+		c.Set(ActorKey, "admin:"+admin.Username)
+		c.Next()
+	}
+}
+
+// RequirePermission menolak request jika role admin atau scope API key tidak
+// memiliki permission
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed := false
+		if admin, ok := c.Get(AdminKey); ok {
+			allowed = entity.HasPermission(admin.(entity.AdminUser).Role, permission)
+		} else if key, ok := c.Get(APIKeyKey); ok {
+			allowed = key.(entity.APIKey).HasScope(permission)
+		}
+
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden - missing permission " + permission})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AdminOnly menolak request yang diautentikasi dengan API key
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(AdminKey); !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden - admin session required"})
+			return
+		}
+
 		c.Next()
 	}
 }
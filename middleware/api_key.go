@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"online-shop/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyKey adalah key di gin.Context untuk API key yang sudah terautentikasi
+const APIKeyKey = "apiKey"
+
+// APIKeyMiddleware mengautentikasi integrasi mesin lewat header X-API-Key.
+// Request tanpa header tersebut diteruskan ke HeaderMiddleware.
+func APIKeyMiddleware(apiKeyUsecase usecase.APIKeyUsecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawKey := c.Request.Header.Get("X-API-Key")
+		if rawKey == "" {
+			c.Next()
+			return
+		}
+
+		key, err := apiKeyUsecase.Authenticate(c, rawKey, c.ClientIP())
+		if errors.Is(err, usecase.ErrInvalidAPIKey) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid 'X-API-Key' header."})
+			return
+		}
+		if errors.Is(err, usecase.ErrAPIKeyIPBlocked) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(APIKeyKey, key)
+		c.Set(ActorKey, "api_key:"+key.Name)
+		c.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"online-shop/auth"
+	"online-shop/repository"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// Key di gin.Context untuk data pelanggan yang sudah login
+const (
+	CustomerIDKey     = "customerID"
+	CustomerClaimsKey = "customerClaims"
+)
+
+// CustomerAuthMiddleware memverifikasi access token pelanggan dari header
+// Authorization: Bearer. Jika required bernilai false, request tanpa token
+// tetap diteruskan sebagai tamu.
+func CustomerAuthMiddleware(tokenRepo repository.TokenRepository, required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.Request.Header.Get("Authorization")
+		if header == "" && !required {
+			c.Next()
+			return
+		}
+
+		tokenString, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || tokenString == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid 'Authorization' header."})
+			return
+		}
+
+		claims, err := auth.ParseToken(viper.GetString("CUSTOMER_JWT_SECRET"), auth.AudienceCustomer, tokenString, auth.TokenTypeAccess)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		revoked, err := tokenRepo.IsRevoked(c, claims.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": auth.ErrInvalidToken.Error()})
+			return
+		}
+
+		c.Set(CustomerIDKey, claims.Subject)
+		c.Set(CustomerClaimsKey, claims)
+		c.Next()
+	}
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+type idempotencyRecord struct {
+	Processing  bool   `json:"processing"`
+	RequestHash string `json:"requestHash"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// idempotencyWriter menyalin response ke buffer agar bisa disimpan dan diputar ulang
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware menyimpan response pertama untuk setiap header
+// Idempotency-Key dan memutarnya ulang untuk request yang sama. Key dicatat
+// per pemanggil sehingga key milik klien lain tidak bisa dipakai untuk
+// membaca response-nya. Key yang dipakai ulang dengan body berbeda ditolak
+// dengan 422, sedangkan request yang masih diproses ditolak dengan 409.
+func IdempotencyMiddleware(rdb *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader("Idempotency-Key")
+		if idempotencyKey == "" {
+			c.Next()
+			return
+		}
+
+		if len(idempotencyKey) > 255 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid 'Idempotency-Key' header."})
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, viper.GetInt64("IDEMPOTENCY_MAX_BODY_SIZE")))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.Sum256(append([]byte(c.Request.Method+" "+c.Request.URL.Path+"\n"), body...))
+		requestHash := hex.EncodeToString(hash[:])
+		scope, identity := requestIdentity(c)
+		redisKey := viper.GetString("IDEMPOTENCY_KEY_PREFIX") + scope + ":" + identity + ":" + c.Request.URL.Path + ":" + idempotencyKey
+
+		// Mengunci key, hanya satu request yang boleh diproses
+		processing, _ := json.Marshal(idempotencyRecord{Processing: true, RequestHash: requestHash})
+		acquired, err := rdb.SetNX(c, redisKey, processing, viper.GetDuration("IDEMPOTENCY_LOCK_TTL")).Result()
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !acquired {
+			var record idempotencyRecord
+			cached, err := rdb.Get(c, redisKey).Bytes()
+			if err == nil {
+				err = json.Unmarshal(cached, &record)
+			}
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "request with the same 'Idempotency-Key' is being processed"})
+				return
+			}
+
+			if record.RequestHash != requestHash {
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "'Idempotency-Key' has already been used with a different request"})
+				return
+			}
+
+			if record.Processing {
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "request with the same 'Idempotency-Key' is being processed"})
+				return
+			}
+
+			c.Header("Idempotent-Replayed", strconv.FormatBool(true))
+			c.Data(record.Status, record.ContentType, record.Body)
+			c.Abort()
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+
+		c.Next()
+
+		// Hanya response yang pasti sama jika diulang yang disimpan. Status
+		// lain bisa berasal dari gangguan sementara sehingga boleh dicoba lagi.
+		if !isReplayableStatus(writer.Status()) {
+			rdb.Del(c, redisKey)
+			return
+		}
+
+		completed, _ := json.Marshal(idempotencyRecord{
+			RequestHash: requestHash,
+			Status:      writer.Status(),
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		})
+		rdb.Set(c, redisKey, completed, viper.GetDuration("IDEMPOTENCY_TTL"))
+	}
+}
+
+// isReplayableStatus melaporkan apakah response dengan status ini disimpan
+// untuk diputar ulang
+func isReplayableStatus(status int) bool {
+	if status >= http.StatusOK && status < http.StatusMultipleChoices {
+		return true
+	}
+	return status == http.StatusConflict || status == http.StatusUnprocessableEntity
+}
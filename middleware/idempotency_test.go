@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+// newIdempotencyRouter menyiapkan router dengan IdempotencyMiddleware di atas
+// Redis sementara. Pemanggil dibedakan lewat header X-Customer-ID.
+func newIdempotencyRouter(t *testing.T, handler gin.HandlerFunc) (*gin.Engine, *miniredis.Miniredis) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	viper.Set("IDEMPOTENCY_KEY_PREFIX", "idempotency_")
+	viper.Set("IDEMPOTENCY_LOCK_TTL", "1m")
+	viper.Set("IDEMPOTENCY_TTL", "24h")
+	viper.Set("IDEMPOTENCY_MAX_BODY_SIZE", 1024)
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	router := gin.New()
+	router.POST("/orders", func(c *gin.Context) {
+		if customerID := c.GetHeader("X-Customer-ID"); customerID != "" {
+			c.Set(CustomerIDKey, customerID)
+		}
+	}, IdempotencyMiddleware(rdb), handler)
+
+	return router, mr
+}
+
+func postOrder(router *gin.Engine, customerID string, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	if customerID != "" {
+		req.Header.Set("X-Customer-ID", customerID)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysCompletedResponse(t *testing.T) {
+	var calls atomic.Int32
+	router, _ := newIdempotencyRouter(t, func(c *gin.Context) {
+		calls.Add(1)
+		c.JSON(http.StatusCreated, gin.H{"call": calls.Load()})
+	})
+
+	first := postOrder(router, "customer-1", "key-1", `{"qty":1}`)
+	second := postOrder(router, "customer-1", "key-1", `{"qty":1}`)
+
+	if calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want 1", calls.Load())
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("replay Content-Type = %q, want %q", second.Header().Get("Content-Type"), first.Header().Get("Content-Type"))
+	}
+	if first.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("first response marked as replayed")
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Idempotent-Replayed = %q, want true", second.Header().Get("Idempotent-Replayed"))
+	}
+}
+
+func TestIdempotencyRejectsDifferentBody(t *testing.T) {
+	var calls atomic.Int32
+	router, _ := newIdempotencyRouter(t, func(c *gin.Context) {
+		calls.Add(1)
+		c.Status(http.StatusCreated)
+	})
+
+	postOrder(router, "customer-1", "key-1", `{"qty":1}`)
+	w := postOrder(router, "customer-1", "key-1", `{"qty":2}`)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler called %d times, want 1", calls.Load())
+	}
+}
+
+func TestIdempotencyRejectsRequestInProgress(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	router, _ := newIdempotencyRouter(t, func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusCreated)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- postOrder(router, "customer-1", "key-1", `{"qty":1}`)
+	}()
+	<-started
+
+	w := postOrder(router, "customer-1", "key-1", `{"qty":1}`)
+	close(release)
+	first := <-done
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	if first.Code != http.StatusCreated {
+		t.Errorf("first status = %d, want %d", first.Code, http.StatusCreated)
+	}
+}
+
+func TestIdempotencyKeysAreScopedPerCaller(t *testing.T) {
+	var calls atomic.Int32
+	router, mr := newIdempotencyRouter(t, func(c *gin.Context) {
+		calls.Add(1)
+		c.JSON(http.StatusCreated, gin.H{"customer": c.GetString(CustomerIDKey)})
+	})
+
+	postOrder(router, "customer-1", "key-1", `{"qty":1}`)
+	w := postOrder(router, "customer-2", "key-1", `{"qty":1}`)
+
+	if calls.Load() != 2 {
+		t.Fatalf("handler called %d times, want 2", calls.Load())
+	}
+	if w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("response of another caller was replayed")
+	}
+	if w.Body.String() != `{"customer":"customer-2"}` {
+		t.Errorf("body = %s, want the response for customer-2", w.Body)
+	}
+
+	for _, key := range []string{
+		"idempotency_customer:customer-1:/orders:key-1",
+		"idempotency_customer:customer-2:/orders:key-1",
+	} {
+		if !mr.Exists(key) {
+			t.Errorf("key %q not stored", key)
+		}
+	}
+}
+
+func TestIdempotencyReleasesKeyForNonReplayableStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		stored bool
+	}{
+		{"server error", http.StatusInternalServerError, false},
+		{"bad request", http.StatusBadRequest, false},
+		{"too many requests", http.StatusTooManyRequests, false},
+		{"conflict", http.StatusConflict, true},
+		{"unprocessable entity", http.StatusUnprocessableEntity, true},
+		{"created", http.StatusCreated, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			router, mr := newIdempotencyRouter(t, func(c *gin.Context) {
+				calls.Add(1)
+				c.Status(tt.status)
+			})
+
+			postOrder(router, "customer-1", "key-1", `{"qty":1}`)
+			stored := mr.Exists("idempotency_customer:customer-1:/orders:key-1")
+			if stored != tt.stored {
+				t.Fatalf("key stored = %v, want %v", stored, tt.stored)
+			}
+
+			// Key yang dilepas boleh dicoba lagi dan handler dijalankan ulang
+			postOrder(router, "customer-1", "key-1", `{"qty":1}`)
+			wantCalls := int32(2)
+			if tt.stored {
+				wantCalls = 1
+			}
+			if calls.Load() != wantCalls {
+				t.Errorf("handler called %d times, want %d", calls.Load(), wantCalls)
+			}
+		})
+	}
+}
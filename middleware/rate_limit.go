@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"online-shop/model/entity"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+// rateLimitScript menjalankan sliding window log secara atomik. Setiap request
+// disimpan di sorted set dengan skor waktu dalam milidetik.
+// Mengembalikan {diizinkan, jumlah request di window, skor request tertua}.
+var rateLimitScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+return {allowed, count, tonumber(oldest[2]) or now}
+`)
+
+type rateLimit struct {
+	limit  int
+	window time.Duration
+}
+
+// Jenis identitas yang dapat diberi batas berbeda
+var rateLimitIdentities = []string{"ip", "customer", "admin", "api_key"}
+
+// RateLimitMiddleware membatasi jumlah request per identitas untuk satu grup
+// route. Batas dibaca dari RATE_LIMIT_<GROUP> dengan format "<limit>/<window>",
+// misalnya "10/1m", dan dapat diganti per identitas lewat
+// RATE_LIMIT_<GROUP>_<IP|CUSTOMER|ADMIN|API_KEY>. Grup tanpa konfigurasi
+// tidak dibatasi. Middleware dipasang setelah middleware autentikasi agar
+// identitas pelanggan, admin, atau API key sudah tersedia.
+func RateLimitMiddleware(rdb *redis.Client, group string) gin.HandlerFunc {
+	configKey := "RATE_LIMIT_" + strings.ToUpper(group)
+	defaultLimit := mustParseRateLimit(configKey)
+
+	limits := make(map[string]*rateLimit)
+	for _, identity := range rateLimitIdentities {
+		limits[identity] = defaultLimit
+		if override := mustParseRateLimit(configKey + "_" + strings.ToUpper(identity)); override != nil {
+			limits[identity] = override
+		}
+	}
+
+	return func(c *gin.Context) {
+		kind, identity := requestIdentity(c)
+		policy := limits[kind]
+		if policy == nil {
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		redisKey := viper.GetString("RATE_LIMIT_KEY_PREFIX") + group + ":" + kind + ":" + identity
+		result, err := rateLimitScript.Run(c, rdb, []string{redisKey},
+			now.UnixMilli(), policy.window.Milliseconds(), policy.limit, uuid.NewString()).Int64Slice()
+		if err != nil {
+			// Redis bermasalah, request tetap dilayani
+			log.Println("error rate limit:", err)
+			c.Next()
+			return
+		}
+
+		allowed, count, oldest := result[0] == 1, result[1], result[2]
+		resetAt := time.UnixMilli(oldest).Add(policy.window)
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(policy.limit))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(max(int64(policy.limit)-count, 0), 10))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+
+		if !allowed {
+			retryAfter := int64(resetAt.Sub(now).Seconds()) + 1
+			c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":      "too many requests",
+				"retryAfter": retryAfter,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// requestIdentity menentukan identitas pemanggil, dari yang paling spesifik
+func requestIdentity(c *gin.Context) (string, string) {
+	if key, ok := c.Get(APIKeyKey); ok {
+		return "api_key", key.(entity.APIKey).ID
+	}
+	if admin, ok := c.Get(AdminKey); ok {
+		return "admin", admin.(entity.AdminUser).ID
+	}
+	if customerID := c.GetString(CustomerIDKey); customerID != "" {
+		return "customer", customerID
+	}
+	return "ip", c.ClientIP()
+}
+
+func mustParseRateLimit(configKey string) *rateLimit {
+	value := viper.GetString(configKey)
+	if value == "" {
+		return nil
+	}
+
+	limit, window, ok := strings.Cut(value, "/")
+	policy := &rateLimit{}
+	var err error
+	if ok {
+		policy.limit, err = strconv.Atoi(limit)
+		if err == nil {
+			policy.window, err = time.ParseDuration(window)
+		}
+	}
+	if !ok || err != nil || policy.limit <= 0 || policy.window <= 0 {
+		log.Fatalf("invalid %s %q, expected <limit>/<window>", configKey, value)
+	}
+
+	return policy
+}
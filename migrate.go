@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"online-shop/migration"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+func runMigrate(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command, expected up, down or status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migration.Up(db)
+		if err != nil {
+			return err
+		}
+		log.Println("applied migrations:", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid steps: %s", args[1])
+			}
+			steps = n
+		}
+
+		reverted, err := migration.Down(db, steps)
+		if err != nil {
+			return err
+		}
+		log.Println("reverted migrations:", reverted)
+
+	case "status":
+		statuses, err := migration.GetStatus(db)
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-30s  %s\n", status.Version, status.Name, appliedAt)
+		}
+
+	default:
+		return fmt.Errorf("unknown command %q, expected up, down or status", args[0])
+	}
+
+	return nil
+}
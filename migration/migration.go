@@ -0,0 +1,307 @@
+package migration
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// advisoryLockID dipakai agar hanya satu proses yang menjalankan migrasi
+const advisoryLockID = 4815162342
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
+
+// Load membaca seluruh migrasi yang di-embed, diurutkan berdasarkan versi.
+// Nama file mengikuti format <versi>_<nama>.<up|down>.sql
+func Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make(map[int64]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		base, direction, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("invalid migration file name: %s", name)
+		}
+
+		versionStr, title, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name: %s", name)
+		}
+
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", name, err)
+		}
+
+		content, err := fs.ReadFile(files, "sql/"+name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, exists := migrations[version]
+		if !exists {
+			m = &Migration{Version: version, Name: title}
+			migrations[version] = m
+		}
+
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(migrations))
+	for _, m := range migrations {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		result = append(result, *m)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+// Up menjalankan semua migrasi yang belum diterapkan dan mengembalikan jumlahnya
+func Up(db *gorm.DB) (int, error) {
+	migrations, err := Load()
+	if err != nil {
+		return 0, err
+	}
+
+	err = ensureTable(db)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, m := range migrations {
+		ran, err := apply(db, m)
+		if err != nil {
+			return applied, fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		if ran {
+			applied++
+		}
+	}
+
+	return applied, nil
+}
+
+// Down membatalkan sejumlah steps migrasi terakhir yang sudah diterapkan
+func Down(db *gorm.DB, steps int) (int, error) {
+	migrations, err := Load()
+	if err != nil {
+		return 0, err
+	}
+
+	err = ensureTable(db)
+	if err != nil {
+		return 0, err
+	}
+
+	reverted := 0
+	for i := len(migrations) - 1; i >= 0 && reverted < steps; i-- {
+		m := migrations[i]
+		ran, err := revert(db, m)
+		if err != nil {
+			return reverted, fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		if ran {
+			reverted++
+		}
+	}
+
+	return reverted, nil
+}
+
+// GetStatus mengembalikan status setiap migrasi
+func GetStatus(db *gorm.DB) ([]Status, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	err = ensureTable(db)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sqlDB.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	appliedAt := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		err := rows.Scan(&version, &at)
+		if err != nil {
+			return nil, err
+		}
+		appliedAt[version] = at
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		status := Status{Version: m.Version, Name: m.Name}
+		if at, ok := appliedAt[m.Version]; ok {
+			status.AppliedAt = &at
+		}
+		result = append(result, status)
+	}
+
+	return result, nil
+}
+
+// CheckLatest mengembalikan error jika masih ada migrasi yang belum diterapkan
+func CheckLatest(db *gorm.DB) error {
+	statuses, err := GetStatus(db)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+
+	if pending > 0 {
+		return fmt.Errorf("database schema is behind: %d pending migration(s), run 'migrate up' first", pending)
+	}
+
+	return nil
+}
+
+// Migrasi dijalankan lewat *sql.DB karena satu file bisa berisi beberapa
+// statement, yang tidak didukung oleh prepared statement milik gorm
+func ensureTable(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlDB.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    BIGINT PRIMARY KEY,
+		name       VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+	return err
+}
+
+func apply(db *gorm.DB, m Migration) (bool, error) {
+	return inLockedTx(db, func(tx *sql.Tx) (bool, error) {
+		// Dicek ulang setelah lock, bisa saja proses lain sudah menerapkannya
+		applied, err := isApplied(tx, m.Version)
+		if err != nil || applied {
+			return false, err
+		}
+
+		_, err = tx.Exec(m.Up)
+		if err != nil {
+			return false, err
+		}
+
+		_, err = tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)", m.Version, m.Name, time.Now())
+		return err == nil, err
+	})
+}
+
+func revert(db *gorm.DB, m Migration) (bool, error) {
+	return inLockedTx(db, func(tx *sql.Tx) (bool, error) {
+		applied, err := isApplied(tx, m.Version)
+		if err != nil || !applied {
+			return false, err
+		}
+
+		_, err = tx.Exec(m.Down)
+		if err != nil {
+			return false, err
+		}
+
+		_, err = tx.Exec("DELETE FROM schema_migrations WHERE version = $1", m.Version)
+		return err == nil, err
+	})
+}
+
+func isApplied(tx *sql.Tx, version int64) (bool, error) {
+	var count int64
+	err := tx.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = $1", version).Scan(&count)
+	return count > 0, err
+}
+
+// inLockedTx menjalankan fn di dalam transaksi yang memegang advisory lock
+// sehingga beberapa replika tidak menjalankan migrasi yang sama bersamaan
+func inLockedTx(db *gorm.DB, fn func(tx *sql.Tx) (bool, error)) (bool, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := sqlDB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec("SELECT pg_advisory_xact_lock($1)", advisoryLockID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	ran, err := fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	return ran, nil
+}
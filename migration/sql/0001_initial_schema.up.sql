@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS products (
+    id         VARCHAR(36) PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    price      BIGINT NOT NULL,
+    is_deleted BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id           VARCHAR(36) PRIMARY KEY,
+    email        VARCHAR(255) NOT NULL,
+    address      TEXT NOT NULL,
+    grand_total  BIGINT NOT NULL,
+    passcode     VARCHAR(255),
+    paid_at      TIMESTAMPTZ,
+    paid_bank    VARCHAR(100),
+    paid_account VARCHAR(100)
+);
+
+CREATE TABLE IF NOT EXISTS order_details (
+    id         VARCHAR(36) PRIMARY KEY,
+    order_id   VARCHAR(36) NOT NULL REFERENCES orders (id),
+    product_id VARCHAR(36) NOT NULL REFERENCES products (id),
+    quantity   INTEGER NOT NULL,
+    price      BIGINT NOT NULL,
+    total      BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_details_order_id ON order_details (order_id);
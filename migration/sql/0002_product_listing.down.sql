@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_products_created_at;
+DROP INDEX IF EXISTS idx_products_name;
+DROP INDEX IF EXISTS idx_products_price;
+
+ALTER TABLE products DROP COLUMN IF EXISTS created_at;
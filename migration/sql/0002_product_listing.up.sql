@@ -0,0 +1,5 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_products_price ON products (price) WHERE is_deleted = FALSE;
+CREATE INDEX IF NOT EXISTS idx_products_name ON products (name) WHERE is_deleted = FALSE;
+CREATE INDEX IF NOT EXISTS idx_products_created_at ON products (created_at) WHERE is_deleted = FALSE;
@@ -0,0 +1,14 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS stock BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE products ADD CONSTRAINT chk_products_stock CHECK (stock >= 0);
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id         VARCHAR(36) PRIMARY KEY,
+    product_id VARCHAR(36) NOT NULL REFERENCES products (id),
+    change     BIGINT NOT NULL,
+    reason     VARCHAR(50) NOT NULL,
+    reference  VARCHAR(36),
+    note       VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_id ON stock_movements (product_id, created_at);
@@ -0,0 +1,15 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'pending';
+
+UPDATE orders SET status = 'paid' WHERE paid_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS order_status_histories (
+    id          VARCHAR(36) PRIMARY KEY,
+    order_id    VARCHAR(36) NOT NULL REFERENCES orders (id),
+    from_status VARCHAR(20) NOT NULL DEFAULT '',
+    to_status   VARCHAR(20) NOT NULL,
+    actor       VARCHAR(100) NOT NULL,
+    note        VARCHAR(255),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_histories_order_id ON order_status_histories (order_id, created_at);
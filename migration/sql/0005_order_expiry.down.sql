@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_orders_pending_expires_at;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS payments (
+    id          VARCHAR(36) PRIMARY KEY,
+    order_id    VARCHAR(36) NOT NULL REFERENCES orders (id),
+    provider    VARCHAR(50) NOT NULL,
+    intent_id   VARCHAR(100) NOT NULL,
+    amount      BIGINT NOT NULL,
+    status      VARCHAR(20) NOT NULL,
+    payment_url TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, intent_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments (order_id, created_at);
+
+CREATE TABLE IF NOT EXISTS payment_events (
+    id         VARCHAR(100) PRIMARY KEY,
+    payment_id VARCHAR(36) NOT NULL REFERENCES payments (id),
+    status     VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
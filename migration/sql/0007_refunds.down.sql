@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS refund_lines;
+DROP TABLE IF EXISTS refunds;
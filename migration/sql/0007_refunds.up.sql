@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS refunds (
+    id                 VARCHAR(36) PRIMARY KEY,
+    order_id           VARCHAR(36) NOT NULL REFERENCES orders (id),
+    payment_id         VARCHAR(36) NOT NULL REFERENCES payments (id),
+    provider_refund_id VARCHAR(100),
+    amount             BIGINT NOT NULL CHECK (amount > 0),
+    reason             VARCHAR(255) NOT NULL,
+    status             VARCHAR(20) NOT NULL,
+    actor              VARCHAR(100) NOT NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_order_id ON refunds (order_id, created_at);
+
+CREATE TABLE IF NOT EXISTS refund_lines (
+    id              VARCHAR(36) PRIMARY KEY,
+    refund_id       VARCHAR(36) NOT NULL REFERENCES refunds (id),
+    order_detail_id VARCHAR(36) NOT NULL REFERENCES order_details (id),
+    quantity        INTEGER NOT NULL CHECK (quantity >= 0),
+    amount          BIGINT NOT NULL CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refund_lines_refund_id ON refund_lines (refund_id);
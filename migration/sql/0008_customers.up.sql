@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS customers (
+    id                 VARCHAR(36) PRIMARY KEY,
+    email              VARCHAR(255) NOT NULL,
+    password_hash      VARCHAR(255) NOT NULL,
+    verification_token VARCHAR(64),
+    email_verified_at  TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_email ON customers (LOWER(email));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_verification_token ON customers (verification_token) WHERE verification_token IS NOT NULL;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS customer_id VARCHAR(36) REFERENCES customers (id);
+
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id ON orders (customer_id, created_at) WHERE customer_id IS NOT NULL;
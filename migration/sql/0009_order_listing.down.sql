@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_orders_grand_total;
+DROP INDEX IF EXISTS idx_orders_email_created_at;
+DROP INDEX IF EXISTS idx_orders_status_created_at;
+DROP INDEX IF EXISTS idx_orders_created_at;
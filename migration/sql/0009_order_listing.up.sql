@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders (created_at);
+CREATE INDEX IF NOT EXISTS idx_orders_status_created_at ON orders (status, created_at);
+CREATE INDEX IF NOT EXISTS idx_orders_email_created_at ON orders (LOWER(email), created_at);
+CREATE INDEX IF NOT EXISTS idx_orders_grand_total ON orders (grand_total);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS admin_users (
+    id            VARCHAR(36) PRIMARY KEY,
+    username      VARCHAR(100) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    role          VARCHAR(30) NOT NULL,
+    is_active     BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admin_users_username ON admin_users (LOWER(username));
+
+CREATE TABLE IF NOT EXISTS admin_tokens (
+    id           VARCHAR(36) PRIMARY KEY,
+    admin_id     VARCHAR(36) NOT NULL REFERENCES admin_users (id),
+    token_hash   VARCHAR(64) NOT NULL,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    revoked_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admin_tokens_token_hash ON admin_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_admin_tokens_admin_id ON admin_tokens (admin_id);
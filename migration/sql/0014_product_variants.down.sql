@@ -0,0 +1,8 @@
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS variant_id;
+
+ALTER TABLE order_details DROP COLUMN IF EXISTS options;
+ALTER TABLE order_details DROP COLUMN IF EXISTS sku;
+ALTER TABLE order_details DROP COLUMN IF EXISTS variant_id;
+
+DROP TABLE IF EXISTS product_variants;
+DROP TABLE IF EXISTS product_options;
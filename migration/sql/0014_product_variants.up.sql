@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS product_options (
+    id            VARCHAR(36) PRIMARY KEY,
+    product_id    VARCHAR(36) NOT NULL REFERENCES products (id),
+    name          VARCHAR(50) NOT NULL,
+    option_values JSONB NOT NULL DEFAULT '[]',
+    position      INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_options_product_name ON product_options (product_id, name);
+
+CREATE TABLE IF NOT EXISTS product_variants (
+    id         VARCHAR(36) PRIMARY KEY,
+    product_id VARCHAR(36) NOT NULL REFERENCES products (id),
+    sku        VARCHAR(64) NOT NULL,
+    options    JSONB NOT NULL DEFAULT '{}',
+    price      BIGINT CHECK (price >= 0),
+    stock      BIGINT NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_sku ON product_variants (sku) WHERE is_deleted = FALSE;
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON product_variants (product_id) WHERE is_deleted = FALSE;
+
+ALTER TABLE order_details ADD COLUMN IF NOT EXISTS variant_id VARCHAR(36) REFERENCES product_variants (id);
+ALTER TABLE order_details ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+ALTER TABLE order_details ADD COLUMN IF NOT EXISTS options JSONB;
+
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS variant_id VARCHAR(36) REFERENCES product_variants (id);
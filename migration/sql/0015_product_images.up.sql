@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS product_images (
+    id            VARCHAR(36) PRIMARY KEY,
+    product_id    VARCHAR(36) NOT NULL REFERENCES products (id),
+    url           VARCHAR(500) NOT NULL,
+    thumbnail_url VARCHAR(500) NOT NULL,
+    storage_key   VARCHAR(255) NOT NULL,
+    thumbnail_key VARCHAR(255) NOT NULL,
+    content_type  VARCHAR(50) NOT NULL,
+    size          BIGINT NOT NULL,
+    width         INTEGER NOT NULL,
+    height        INTEGER NOT NULL,
+    position      INTEGER NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product_id ON product_images (product_id, position);
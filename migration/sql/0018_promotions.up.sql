@@ -0,0 +1,56 @@
+CREATE TABLE IF NOT EXISTS promotions (
+    id                    VARCHAR(36) PRIMARY KEY,
+    code                  VARCHAR(50) NOT NULL,
+    description           VARCHAR(255),
+    type                  VARCHAR(20) NOT NULL CHECK (type IN ('percentage', 'fixed', 'free_shipping', 'buy_x_get_y')),
+    value                 BIGINT NOT NULL DEFAULT 0 CHECK (value >= 0),
+    max_discount          BIGINT CHECK (max_discount > 0),
+    buy_quantity          INTEGER NOT NULL DEFAULT 0,
+    get_quantity          INTEGER NOT NULL DEFAULT 0,
+    min_subtotal          BIGINT NOT NULL DEFAULT 0,
+    starts_at             TIMESTAMPTZ,
+    ends_at               TIMESTAMPTZ,
+    usage_limit           BIGINT CHECK (usage_limit > 0),
+    usage_limit_per_email BIGINT CHECK (usage_limit_per_email > 0),
+    product_ids           JSONB NOT NULL DEFAULT '[]',
+    category_ids          JSONB NOT NULL DEFAULT '[]',
+    is_active             BOOLEAN NOT NULL DEFAULT TRUE,
+    is_deleted            BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promotions_code ON promotions (code) WHERE is_deleted = FALSE;
+
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id           VARCHAR(36) PRIMARY KEY,
+    promotion_id VARCHAR(36) NOT NULL REFERENCES promotions (id),
+    order_id     VARCHAR(36) NOT NULL REFERENCES orders (id),
+    email        VARCHAR(255) NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promotion_redemptions_order ON promotion_redemptions (order_id);
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_email ON promotion_redemptions (promotion_id, email);
+
+CREATE TABLE IF NOT EXISTS order_discounts (
+    id              VARCHAR(36) PRIMARY KEY,
+    order_id        VARCHAR(36) NOT NULL REFERENCES orders (id),
+    order_detail_id VARCHAR(36) REFERENCES order_details (id),
+    promotion_id    VARCHAR(36) NOT NULL REFERENCES promotions (id),
+    code            VARCHAR(50) NOT NULL,
+    type            VARCHAR(20) NOT NULL,
+    description     VARCHAR(255) NOT NULL,
+    amount          BIGINT NOT NULL CHECK (amount >= 0),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_discounts_order_id ON order_discounts (order_id);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS subtotal BIGINT;
+UPDATE orders SET subtotal = grand_total WHERE subtotal IS NULL;
+ALTER TABLE orders ALTER COLUMN subtotal SET NOT NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_total BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS promo_code VARCHAR(50);
+
+ALTER TABLE order_details ADD COLUMN IF NOT EXISTS discount BIGINT NOT NULL DEFAULT 0;
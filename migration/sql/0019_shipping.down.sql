@@ -0,0 +1,11 @@
+ALTER TABLE refunds DROP COLUMN IF EXISTS shipping_amount;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_cost;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_weight;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_method;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_method_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_address;
+
+DROP TABLE IF EXISTS shipping_methods;
+
+ALTER TABLE products DROP COLUMN IF EXISTS weight;
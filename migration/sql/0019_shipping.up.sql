@@ -0,0 +1,27 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS weight BIGINT NOT NULL DEFAULT 0 CHECK (weight >= 0);
+
+CREATE TABLE IF NOT EXISTS shipping_methods (
+    id          VARCHAR(36) PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL,
+    description VARCHAR(255),
+    rates       JSONB NOT NULL DEFAULT '[]',
+    sort_order  INTEGER NOT NULL DEFAULT 0,
+    is_active   BOOLEAN NOT NULL DEFAULT TRUE,
+    is_deleted  BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Metode bawaan tanpa ongkos kirim agar checkout tetap berjalan seperti sebelumnya
+INSERT INTO shipping_methods (id, name, description, rates)
+VALUES ('00000000-0000-0000-0000-000000000001', 'Standard', 'Standard delivery',
+        '[{"zone": "All", "provinces": [], "minWeight": 0, "price": 0}]')
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_address JSONB;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_method_id VARCHAR(36) REFERENCES shipping_methods (id);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_method VARCHAR(100);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_weight BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_cost BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS shipping_amount BIGINT NOT NULL DEFAULT 0;
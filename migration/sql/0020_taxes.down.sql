@@ -0,0 +1,11 @@
+ALTER TABLE order_details DROP COLUMN IF EXISTS tax;
+ALTER TABLE order_details DROP COLUMN IF EXISTS tax_rate;
+ALTER TABLE order_details DROP COLUMN IF EXISTS tax_class;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS prices_include_tax;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_total;
+
+ALTER TABLE products DROP COLUMN IF EXISTS tax_class;
+
+DROP TABLE IF EXISTS tax_rates;
+DROP TABLE IF EXISTS tax_classes;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS tax_classes (
+    code       VARCHAR(50) PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO tax_classes (code, name) VALUES ('standard', 'Standard'), ('exempt', 'Tax exempt')
+ON CONFLICT (code) DO NOTHING;
+
+-- Tarif dalam basis poin (1100 = 11%), province kosong berlaku untuk semua provinsi
+CREATE TABLE IF NOT EXISTS tax_rates (
+    id         VARCHAR(36) PRIMARY KEY,
+    tax_class  VARCHAR(50) NOT NULL REFERENCES tax_classes (code),
+    province   VARCHAR(100) NOT NULL DEFAULT '',
+    name       VARCHAR(100) NOT NULL,
+    rate       INTEGER NOT NULL CHECK (rate >= 0 AND rate <= 10000),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tax_rates_class_province ON tax_rates (tax_class, LOWER(province));
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_class VARCHAR(50) NOT NULL DEFAULT 'standard' REFERENCES tax_classes (code);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_total BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS prices_include_tax BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE order_details ADD COLUMN IF NOT EXISTS tax_class VARCHAR(50);
+ALTER TABLE order_details ADD COLUMN IF NOT EXISTS tax_rate INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE order_details ADD COLUMN IF NOT EXISTS tax BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+ALTER TABLE promotions DROP COLUMN IF EXISTS currency;
+ALTER TABLE refund_lines DROP COLUMN IF EXISTS currency;
+ALTER TABLE refunds DROP COLUMN IF EXISTS currency;
+ALTER TABLE payments DROP COLUMN IF EXISTS currency;
+ALTER TABLE order_discounts DROP COLUMN IF EXISTS currency;
+ALTER TABLE order_details DROP COLUMN IF EXISTS currency;
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+
+DROP TABLE IF EXISTS product_prices;
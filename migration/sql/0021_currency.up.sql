@@ -0,0 +1,22 @@
+-- Harga produk dan varian dalam mata uang selain mata uang dasar
+CREATE TABLE IF NOT EXISTS product_prices (
+    id         VARCHAR(36) PRIMARY KEY,
+    product_id VARCHAR(36) NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    variant_id VARCHAR(36) REFERENCES product_variants (id) ON DELETE CASCADE,
+    currency   VARCHAR(3) NOT NULL,
+    price      BIGINT NOT NULL CHECK (price >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_prices_unique
+    ON product_prices (product_id, COALESCE(variant_id, ''), currency);
+
+-- Data lama tercatat dalam rupiah, mata uang dasar bawaan
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE order_details ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE order_discounts ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE refund_lines ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE promotions ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
@@ -0,0 +1,2 @@
+ALTER TABLE audit_logs ALTER COLUMN actor TYPE VARCHAR(100);
+ALTER TABLE audit_logs ALTER COLUMN entity_id TYPE VARCHAR(36);
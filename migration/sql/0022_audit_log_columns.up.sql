@@ -0,0 +1,5 @@
+-- Kode tax class (sampai 50 karakter) dipakai sebagai entity_id dan actor
+-- berbentuk "admin:<username>" atau "api_key:<nama>" yang masing-masing
+-- sampai 100 karakter
+ALTER TABLE audit_logs ALTER COLUMN entity_id TYPE VARCHAR(255);
+ALTER TABLE audit_logs ALTER COLUMN actor TYPE VARCHAR(255);
@@ -0,0 +1,18 @@
+package dto
+
+type ReqAdminLogin struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ReqAdminUser struct {
+	Username string `json:"username" binding:"required,min=3,max=100"`
+	Password string `json:"password" binding:"required,min=12,max=72"`
+	Role     string `json:"role" binding:"required,oneof=catalog_manager order_operator finance superadmin"`
+}
+
+type ReqUpdateAdminUser struct {
+	Role     string `json:"role" binding:"omitempty,oneof=catalog_manager order_operator finance superadmin"`
+	Password string `json:"password" binding:"omitempty,min=12,max=72"`
+	IsActive *bool  `json:"isActive"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+import "time"
+
+type ReqAPIKey struct {
+	Name       string     `json:"name" binding:"required,max=100"`
+	Scopes     []string   `json:"scopes" binding:"required,min=1,dive,oneof=products:read products:write orders:read orders:write refunds:write promotions:manage taxes:manage"`
+	AllowedIPs []string   `json:"allowedIps" binding:"omitempty,dive,cidr|ip"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+type ReqAuditQuery struct {
+	Page       int        `form:"page" binding:"omitempty,min=1"`
+	Limit      int        `form:"limit" binding:"omitempty,min=1,max=100"`
+	Actor      string     `form:"actor" binding:"omitempty,max=100"`
+	Action     string     `form:"action" binding:"omitempty,max=100"`
+	EntityType string     `form:"entity_type" binding:"omitempty,max=50"`
+	EntityID   string     `form:"entity_id" binding:"omitempty,max=36"`
+	RequestID  string     `form:"request_id" binding:"omitempty,max=64"`
+	DateFrom   *time.Time `form:"date_from" time_format:"2006-01-02"`
+	DateTo     *time.Time `form:"date_to" time_format:"2006-01-02"`
+}
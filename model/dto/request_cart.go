@@ -0,0 +1,29 @@
+package dto
+
+import "online-shop/model/entity"
+
+// Quantity pada POST ditambahkan ke jumlah yang sudah ada, pada PATCH
+// menggantikan jumlah tersebut dan 0 menghapus barang dari keranjang
+type ReqCartItem struct {
+	ProductID string `json:"productId" binding:"required,max=36"`
+	VariantID string `json:"variantId" binding:"omitempty,max=36"`
+	Quantity  int32  `json:"quantity" binding:"min=0,max=1000"`
+}
+
+type ReqCartItems struct {
+	Items []ReqCartItem `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// Currency dan PromoCode yang tidak dikirim tidak mengubah keranjang, string
+// kosong menghapus nilainya
+type ReqCartUpdate struct {
+	Items     []ReqCartItem `json:"items" binding:"omitempty,max=100,dive"`
+	Currency  *string       `json:"currency" binding:"omitempty,max=3"`
+	PromoCode *string       `json:"promoCode" binding:"omitempty,max=50"`
+}
+
+type ReqCartCheckout struct {
+	Email            string         `json:"email" binding:"required,email,max=255"`
+	Address          entity.Address `json:"address" binding:"required"`
+	ShippingMethodID string         `json:"shippingMethodId" binding:"required"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+type ReqCategory struct {
+	Name      string  `json:"name" binding:"required,max=100"`
+	Slug      string  `json:"slug" binding:"omitempty,max=100"`
+	ParentID  *string `json:"parentId" binding:"omitempty,max=36"`
+	SortOrder int     `json:"sortOrder"`
+}
+
+type ReqProductCategories struct {
+	CategoryIDs []string `json:"categoryIds" binding:"omitempty,max=50,dive,required,max=36"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+type ReqRegister struct {
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+type ReqVerifyEmail struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ReqLogin struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+	// CartID adalah keranjang tamu yang digabung ke keranjang pelanggan
+	CartID string `json:"cartId" binding:"omitempty,max=36"`
+}
+
+type ReqRefreshToken struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type ReqLogout struct {
+	RefreshToken string `json:"refreshToken"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type ReqImageOrder struct {
+	ImageIDs []string `json:"imageIds" binding:"required,min=1,dive,required"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+type ReqOrderStatus struct {
+	Status string `json:"status" binding:"required,oneof=shipped delivered cancelled"`
+	Note   string `json:"note" binding:"omitempty,max=255"`
+}
+
+type ReqOrderQuery struct {
+	Page       int        `form:"page" binding:"omitempty,min=1"`
+	Limit      int        `form:"limit" binding:"omitempty,min=1,max=100"`
+	Status     string     `form:"status" binding:"omitempty,oneof=pending paid shipped delivered cancelled expired refunded"`
+	Email      string     `form:"email" binding:"omitempty,max=255"`
+	DateFrom   *time.Time `form:"date_from" time_format:"2006-01-02"`
+	DateTo     *time.Time `form:"date_to" time_format:"2006-01-02"`
+	Currency   string     `form:"currency" binding:"omitempty,len=3"`
+	MinTotal   *int64     `form:"min_total" binding:"omitempty,min=0"`
+	MaxTotal   *int64     `form:"max_total" binding:"omitempty,min=0"`
+	SortBy     string     `form:"sort" binding:"omitempty,oneof=created_at grand_total"`
+	Order      string     `form:"order" binding:"omitempty,oneof=asc desc"`
+	CustomerID string     `form:"-"`
+}
+
+type ReqPayOrder struct {
+	Passcode string `json:"passcode" binding:"required"`
+}
+
+type ReqCancelOrder struct {
+	Passcode string `json:"passcode" binding:"required"`
+}
+
+type ReqRefund struct {
+	Reason  string          `json:"reason" binding:"required,max=255"`
+	Restock bool            `json:"restock"`
+	Lines   []ReqRefundLine `json:"lines" binding:"omitempty,dive"`
+}
+
+type ReqRefundLine struct {
+	OrderDetailID string `json:"orderDetailId" binding:"required"`
+	Quantity      int32  `json:"quantity" binding:"required,min=1"`
+	Amount        *int64 `json:"amount" binding:"omitempty,min=1"`
+}
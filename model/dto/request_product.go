@@ -1,6 +1,68 @@
 package dto
 
+// Weight adalah berat produk dalam gram dan Price dalam mata uang dasar
 type ReqProduct struct {
-	Name  string `json:"name" binding:"required"`
-	Price int64  `json:"price" binding:"required"`
+	SKU      string `json:"sku" binding:"omitempty,max=64"`
+	Name     string `json:"name" binding:"required"`
+	Price    int64  `json:"price" binding:"required"`
+	Stock    int64  `json:"stock" binding:"omitempty,min=0"`
+	Weight   int64  `json:"weight" binding:"omitempty,min=0"`
+	TaxClass string `json:"taxClass" binding:"omitempty,max=50"`
+}
+
+// ReqProductPrices mengganti seluruh daftar harga produk dalam mata uang lain
+type ReqProductPrices struct {
+	Prices []ReqProductPrice `json:"prices" binding:"omitempty,max=200,dive"`
+}
+
+// Price dalam minor unit Currency, VariantID kosong berarti harga produk
+type ReqProductPrice struct {
+	VariantID string `json:"variantId" binding:"omitempty,max=36"`
+	Currency  string `json:"currency" binding:"required,len=3"`
+	Price     int64  `json:"price" binding:"min=0"`
+}
+
+type ReqStockAdjustment struct {
+	Change int64  `json:"change" binding:"required"`
+	Reason string `json:"reason" binding:"required,oneof=restock correction damaged lost returned"`
+	Note   string `json:"note" binding:"omitempty,max=255"`
+}
+
+type ReqProductQuery struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Name     string `form:"name" binding:"omitempty,max=100"`
+	Category string `form:"category" binding:"omitempty,max=100"`
+	MinPrice *int64 `form:"min_price" binding:"omitempty,min=0"`
+	MaxPrice *int64 `form:"max_price" binding:"omitempty,min=0"`
+	SortBy   string `form:"sort" binding:"omitempty,oneof=price name created_at"`
+	Order    string `form:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+type ReqProductSearch struct {
+	Query string `form:"q" binding:"required,max=100"`
+	Page  int    `form:"page" binding:"omitempty,min=1"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// Format "csv" memakai baris header, format "jsonl" berisi satu objek JSON per baris
+type ReqProductImport struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv jsonl"`
+	DryRun bool   `form:"dry_run"`
+}
+
+type ReqProductExport struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv jsonl"`
+}
+
+// ProductImportRow adalah satu baris import/export produk. Baris dengan id
+// atau sku yang sudah ada akan mengubah produk tersebut, selain itu dibuat
+// produk baru. Stok yang kosong tidak mengubah stok produk yang sudah ada.
+// Price dalam mata uang dasar.
+type ProductImportRow struct {
+	ID    string `json:"id"`
+	SKU   string `json:"sku"`
+	Name  string `json:"name"`
+	Price int64  `json:"price"`
+	Stock *int64 `json:"stock"`
 }
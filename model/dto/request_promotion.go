@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// Value untuk jenis fixed, MaxDiscount, dan MinSubtotal dalam Currency,
+// Currency kosong berarti mata uang dasar
+type ReqPromotion struct {
+	Code               string     `json:"code" binding:"required,max=50"`
+	Description        string     `json:"description" binding:"omitempty,max=255"`
+	Type               string     `json:"type" binding:"required,oneof=percentage fixed free_shipping buy_x_get_y"`
+	Value              int64      `json:"value" binding:"omitempty,min=0"`
+	Currency           string     `json:"currency" binding:"omitempty,len=3"`
+	MaxDiscount        *int64     `json:"maxDiscount" binding:"omitempty,min=1"`
+	BuyQuantity        int32      `json:"buyQuantity" binding:"omitempty,min=1"`
+	GetQuantity        int32      `json:"getQuantity" binding:"omitempty,min=1"`
+	MinSubtotal        int64      `json:"minSubtotal" binding:"omitempty,min=0"`
+	StartsAt           *time.Time `json:"startsAt"`
+	EndsAt             *time.Time `json:"endsAt"`
+	UsageLimit         *int64     `json:"usageLimit" binding:"omitempty,min=1"`
+	UsageLimitPerEmail *int64     `json:"usageLimitPerEmail" binding:"omitempty,min=1"`
+	ProductIDs         []string   `json:"productIds" binding:"omitempty,max=100,dive,required,max=36"`
+	CategoryIDs        []string   `json:"categoryIds" binding:"omitempty,max=50,dive,required,max=36"`
+	IsActive           *bool      `json:"isActive"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "online-shop/model/entity"
+
+type ReqShippingMethod struct {
+	Name        string            `json:"name" binding:"required,max=100"`
+	Description string            `json:"description" binding:"omitempty,max=255"`
+	Rates       []ReqShippingRate `json:"rates" binding:"required,min=1,max=200,dive"`
+	SortOrder   int               `json:"sortOrder"`
+	IsActive    *bool             `json:"isActive"`
+}
+
+// Berat dalam gram, MaxWeight eksklusif. Price dalam Currency, Currency kosong
+// berarti mata uang dasar.
+type ReqShippingRate struct {
+	Zone      string   `json:"zone" binding:"required,max=100"`
+	Provinces []string `json:"provinces" binding:"omitempty,max=50,dive,required,max=100"`
+	MinWeight int64    `json:"minWeight" binding:"omitempty,min=0"`
+	MaxWeight *int64   `json:"maxWeight" binding:"omitempty,min=1"`
+	Price     int64    `json:"price" binding:"omitempty,min=0"`
+	Currency  string   `json:"currency" binding:"omitempty,len=3"`
+}
+
+type ReqShippingQuote struct {
+	Address  entity.Address           `json:"address" binding:"required"`
+	Products []entity.ProductQuantity `json:"products" binding:"required,min=1"`
+	Currency string                   `json:"currency" binding:"omitempty,len=3"`
+}
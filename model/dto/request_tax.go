@@ -0,0 +1,14 @@
+package dto
+
+type ReqTaxClass struct {
+	Code string `json:"code" binding:"required,max=50"`
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// Rate dalam basis poin, 1100 berarti 11%
+type ReqTaxRate struct {
+	TaxClass string `json:"taxClass" binding:"required,max=50"`
+	Province string `json:"province" binding:"omitempty,max=100"`
+	Name     string `json:"name" binding:"required,max=100"`
+	Rate     int64  `json:"rate" binding:"omitempty,min=0,max=10000"`
+}
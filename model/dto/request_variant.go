@@ -0,0 +1,17 @@
+package dto
+
+type ReqProductOption struct {
+	Name   string   `json:"name" binding:"required,max=50"`
+	Values []string `json:"values" binding:"required,min=1,max=50,dive,required,max=50"`
+}
+
+type ReqProductOptions struct {
+	Options []ReqProductOption `json:"options" binding:"omitempty,max=3,dive"`
+}
+
+type ReqVariant struct {
+	SKU     string            `json:"sku" binding:"required,max=64"`
+	Options map[string]string `json:"options"`
+	Price   *int64            `json:"price" binding:"omitempty,min=0"`
+	Stock   int64             `json:"stock" binding:"omitempty,min=0"`
+}
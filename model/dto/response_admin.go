@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+type ResAdminToken struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"tokenType"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+import "online-shop/model/entity"
+
+type ResAuditLogs struct {
+	Data       []entity.AuditLog `json:"data"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	Total      int64             `json:"total"`
+	TotalPages int               `json:"totalPages"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"online-shop/model/entity"
+	"time"
+)
+
+// ResCart adalah keranjang dengan harga dan stok terbaru. Subtotal hanya
+// menjumlahkan barang yang tersedia, CanCheckout false jika ada barang yang
+// bermasalah atau keranjang kosong.
+type ResCart struct {
+	ID          string        `json:"id"`
+	CustomerID  *string       `json:"customerId,omitempty"`
+	Currency    string        `json:"currency"`
+	PromoCode   string        `json:"promoCode,omitempty"`
+	Items       []ResCartItem `json:"items"`
+	ItemCount   int64         `json:"itemCount"`
+	Subtotal    entity.Money  `json:"subtotal"`
+	CanCheckout bool          `json:"canCheckout"`
+	CreatedAt   time.Time     `json:"createdAt"`
+	UpdatedAt   time.Time     `json:"updatedAt"`
+	ExpiresAt   time.Time     `json:"expiresAt"`
+}
+
+// Issue menjelaskan kenapa barang tidak bisa di-checkout, misalnya produk
+// sudah dihapus atau stok tidak mencukupi
+type ResCartItem struct {
+	ProductID string            `json:"productId"`
+	VariantID string            `json:"variantId,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	SKU       *string           `json:"sku,omitempty"`
+	Options   map[string]string `json:"options,omitempty"`
+	Quantity  int32             `json:"quantity"`
+	Price     *entity.Money     `json:"price,omitempty"`
+	Total     *entity.Money     `json:"total,omitempty"`
+	Available bool              `json:"available"`
+	Stock     int64             `json:"stock"`
+	Issue     string            `json:"issue,omitempty"`
+}
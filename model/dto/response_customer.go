@@ -0,0 +1,9 @@
+package dto
+
+type ResToken struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int64  `json:"expiresIn"`
+	CartID       string `json:"cartId,omitempty"`
+}
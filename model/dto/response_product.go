@@ -0,0 +1,37 @@
+package dto
+
+import "online-shop/model/entity"
+
+type ResProducts struct {
+	Data       []entity.Product `json:"data"`
+	Page       int              `json:"page"`
+	Limit      int              `json:"limit"`
+	Total      int64            `json:"total"`
+	TotalPages int              `json:"totalPages"`
+}
+
+type ResProductSearch struct {
+	Data       []entity.ProductSearchResult `json:"data"`
+	Page       int                          `json:"page"`
+	Limit      int                          `json:"limit"`
+	Total      int64                        `json:"total"`
+	TotalPages int                          `json:"totalPages"`
+}
+
+// Reference diisi saat import diterapkan dan dipakai sebagai referensi
+// stock movement dari import tersebut
+type ResProductImport struct {
+	Reference string               `json:"reference,omitempty"`
+	DryRun    bool                 `json:"dryRun"`
+	Applied   bool                 `json:"applied"`
+	Total     int                  `json:"total"`
+	Created   int                  `json:"created"`
+	Updated   int                  `json:"updated"`
+	Unchanged int                  `json:"unchanged"`
+	Errors    []ProductImportError `json:"errors"`
+}
+
+type ProductImportError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+import "online-shop/model/entity"
+
+type ResProductVariants struct {
+	Options  []entity.ProductOption  `json:"options"`
+	Variants []entity.ProductVariant `json:"variants"`
+}
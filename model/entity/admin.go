@@ -0,0 +1,64 @@
+package entity
+
+import "time"
+
+// Role admin
+const (
+	RoleCatalogManager = "catalog_manager"
+	RoleOrderOperator  = "order_operator"
+	RoleFinance        = "finance"
+	RoleSuperadmin     = "superadmin"
+)
+
+// Permission yang diperiksa pada setiap route admin
+const (
+	PermissionProductsRead     = "products:read"
+	PermissionProductsWrite    = "products:write"
+	PermissionOrdersRead       = "orders:read"
+	PermissionOrdersWrite      = "orders:write"
+	PermissionRefundsWrite     = "refunds:write"
+	PermissionAdminsManage     = "admins:manage"
+	PermissionPromotionsManage = "promotions:manage"
+	PermissionTaxesManage      = "taxes:manage"
+)
+
+var rolePermissions = map[string][]string{
+	RoleCatalogManager: {PermissionProductsRead, PermissionProductsWrite, PermissionPromotionsManage},
+	RoleOrderOperator:  {PermissionProductsRead, PermissionOrdersRead, PermissionOrdersWrite},
+	RoleFinance:        {PermissionOrdersRead, PermissionRefundsWrite, PermissionTaxesManage},
+}
+
+// HasPermission melaporkan apakah role memiliki permission tertentu.
+// Superadmin memiliki semua permission.
+func HasPermission(role string, permission string) bool {
+	if role == RoleSuperadmin {
+		return true
+	}
+
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type AdminUser struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role"`
+	IsActive     bool      `json:"isActive"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type AdminToken struct {
+	ID         string     `json:"id"`
+	AdminID    string     `json:"adminId"`
+	TokenHash  string     `json:"-"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+// APIKeyScopes adalah scope yang boleh diberikan ke API key. Pengelolaan
+// admin dan API key sengaja tidak termasuk.
+var APIKeyScopes = []string{
+	PermissionProductsRead,
+	PermissionProductsWrite,
+	PermissionOrdersRead,
+	PermissionOrdersWrite,
+	PermissionRefundsWrite,
+}
+
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json"`
+	AllowedIPs []string   `json:"allowedIps" gorm:"column:allowed_ips;serializer:json"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedBy  string     `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// HasScope melaporkan apakah API key memiliki scope tertentu
+func (k APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyWithSecret dikembalikan sekali saat API key dibuat
+type APIKeyWithSecret struct {
+	APIKey
+	Key string `json:"key"`
+}
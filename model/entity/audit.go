@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// AuditLog mencatat setiap perubahan yang dilakukan lewat endpoint admin.
+// Before dan After hanya berisi field yang berubah.
+type AuditLog struct {
+	ID         string         `json:"id"`
+	Actor      string         `json:"actor"`
+	Action     string         `json:"action"`
+	EntityType string         `json:"entityType"`
+	EntityID   string         `json:"entityId"`
+	Before     map[string]any `json:"before" gorm:"serializer:json"`
+	After      map[string]any `json:"after" gorm:"serializer:json"`
+	RequestID  string         `json:"requestId"`
+	ClientIP   string         `json:"clientIp"`
+	CreatedAt  time.Time      `json:"createdAt"`
+}
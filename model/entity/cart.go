@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// MaxCartQuantity adalah jumlah maksimum satu baris keranjang
+const MaxCartQuantity = 1000
+
+var ErrCartQuantity = errors.New("quantity per cart item must not exceed 1000")
+
+// Cart adalah keranjang belanja yang disimpan di Redis. Harga dan stok tidak
+// disimpan di sini karena selalu dihitung ulang saat keranjang dibaca.
+// Currency kosong berarti mata uang dasar.
+type Cart struct {
+	ID         string     `json:"id"`
+	CustomerID *string    `json:"customerId,omitempty"`
+	Currency   string     `json:"currency,omitempty"`
+	PromoCode  string     `json:"promoCode,omitempty"`
+	Items      []CartItem `json:"items"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+}
+
+type CartItem struct {
+	ProductID string `json:"productId"`
+	VariantID string `json:"variantId,omitempty"`
+	Quantity  int32  `json:"quantity"`
+}
+
+func (c *Cart) find(productID string, variantID string) int {
+	for i, item := range c.Items {
+		if item.ProductID == productID && item.VariantID == variantID {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddItem menambah jumlah barang, baris baru dibuat jika belum ada
+func (c *Cart) AddItem(productID string, variantID string, quantity int32) error {
+	i := c.find(productID, variantID)
+	if i < 0 {
+		if quantity > MaxCartQuantity {
+			return ErrCartQuantity
+		}
+		c.Items = append(c.Items, CartItem{ProductID: productID, VariantID: variantID, Quantity: quantity})
+		return nil
+	}
+
+	if c.Items[i].Quantity+quantity > MaxCartQuantity {
+		return ErrCartQuantity
+	}
+	c.Items[i].Quantity += quantity
+	return nil
+}
+
+// SetItem mengganti jumlah barang, jumlah 0 menghapus baris tersebut
+func (c *Cart) SetItem(productID string, variantID string, quantity int32) error {
+	if quantity > MaxCartQuantity {
+		return ErrCartQuantity
+	}
+
+	i := c.find(productID, variantID)
+	switch {
+	case i < 0 && quantity > 0:
+		c.Items = append(c.Items, CartItem{ProductID: productID, VariantID: variantID, Quantity: quantity})
+	case i >= 0 && quantity > 0:
+		c.Items[i].Quantity = quantity
+	case i >= 0:
+		c.Items = append(c.Items[:i], c.Items[i+1:]...)
+	}
+	return nil
+}
+
+// Merge menambahkan isi keranjang lain, jumlah yang melebihi batas dipotong
+func (c *Cart) Merge(other Cart) {
+	for _, item := range other.Items {
+		if c.AddItem(item.ProductID, item.VariantID, item.Quantity) != nil {
+			c.SetItem(item.ProductID, item.VariantID, MaxCartQuantity)
+		}
+	}
+
+	if c.Currency == "" {
+		c.Currency = other.Currency
+	}
+	if c.PromoCode == "" {
+		c.PromoCode = other.PromoCode
+	}
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+type Category struct {
+	ID        string    `json:"id"`
+	ParentID  *string   `json:"parentId"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	SortOrder int       `json:"sortOrder"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CategoryTree adalah kategori beserta seluruh sub-kategorinya
+type CategoryTree struct {
+	Category
+	Children []CategoryTree `json:"children"`
+}
+
+type ProductCategory struct {
+	ProductID  string `json:"productId"`
+	CategoryID string `json:"categoryId"`
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+type Customer struct {
+	ID                string     `json:"id"`
+	Email             string     `json:"email"`
+	PasswordHash      string     `json:"-"`
+	VerificationToken *string    `json:"-"`
+	EmailVerifiedAt   *time.Time `json:"emailVerifiedAt,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+}
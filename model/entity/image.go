@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+type ProductImage struct {
+	ID           string    `json:"id"`
+	ProductID    string    `json:"productId"`
+	URL          string    `json:"url" gorm:"column:url"`
+	ThumbnailURL string    `json:"thumbnailUrl" gorm:"column:thumbnail_url"`
+	StorageKey   string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	Position     int       `json:"position"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// ProductImageURL adalah ringkasan gambar yang ditampilkan pada data produk
+type ProductImageURL struct {
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+}
@@ -0,0 +1,270 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrAmountOverflow   = errors.New("amount overflow")
+	ErrUnknownCurrency  = errors.New("unknown currency")
+)
+
+// currencies memetakan kode ISO 4217 yang didukung ke jumlah digit minor
+// unit. Rupiah dicatat tanpa sen karena sen sudah tidak dipakai.
+var currencies = map[string]int{
+	"IDR": 0,
+	"USD": 2,
+	"EUR": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"AUD": 2,
+	"JPY": 0,
+}
+
+// baseCurrency adalah mata uang harga produk, tarif, dan promo yang tidak
+// menyebut mata uangnya sendiri
+var baseCurrency = "IDR"
+
+// SetBaseCurrency mengganti mata uang dasar, dipanggil sekali saat aplikasi mulai
+func SetBaseCurrency(code string) error {
+	code = NormalizeCurrency(code)
+	if !IsCurrency(code) {
+		return fmt.Errorf("%w: %s", ErrUnknownCurrency, code)
+	}
+	baseCurrency = code
+	return nil
+}
+
+func BaseCurrency() string {
+	return baseCurrency
+}
+
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func IsCurrency(code string) bool {
+	_, ok := currencies[code]
+	return ok
+}
+
+// MinorUnits mengembalikan jumlah digit di belakang koma untuk mata uang
+func MinorUnits(code string) int {
+	return currencies[code]
+}
+
+// Money adalah nominal dalam minor unit mata uangnya, misalnya sen untuk USD.
+// Di database hanya Amount yang disimpan, mata uangnya mengikuti kolom
+// currency milik baris tersebut atau mata uang dasar.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Zero membuat nominal nol dalam mata uang yang sama
+func (m Money) Zero() Money {
+	return Money{Currency: m.currency()}
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) currency() string {
+	if m.Currency == "" {
+		return baseCurrency
+	}
+	return m.Currency
+}
+
+// Add menjumlahkan dua nominal dengan mata uang yang sama
+func (m Money) Add(other Money) (Money, error) {
+	if m.currency() != other.currency() {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency(), other.currency())
+	}
+
+	result := m.Amount + other.Amount
+	if (result > m.Amount) != (other.Amount > 0) {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return Money{Amount: result, Currency: m.currency()}, nil
+}
+
+// Sub mengurangi nominal dengan nominal lain dengan mata uang yang sama
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, ErrAmountOverflow
+	}
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+// Mul mengalikan nominal dengan bilangan bulat, misalnya harga dengan jumlah barang
+func (m Money) Mul(n int64) (Money, error) {
+	if m.Amount == 0 || n == 0 {
+		return m.Zero(), nil
+	}
+
+	result := m.Amount * n
+	if result/n != m.Amount || (m.Amount == -1 && n == math.MinInt64) || (n == -1 && m.Amount == math.MinInt64) {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return Money{Amount: result, Currency: m.currency()}, nil
+}
+
+// MulDiv menghitung m * num / den tanpa overflow di perkalian antara dan
+// membulatkan ke bawah, dipakai untuk pembagian proporsional
+func (m Money) MulDiv(num int64, den int64) (Money, error) {
+	return m.mulDiv(num, den, false)
+}
+
+// MulDivRound sama dengan MulDiv tetapi membulatkan setengah ke atas
+func (m Money) MulDivRound(num int64, den int64) (Money, error) {
+	return m.mulDiv(num, den, true)
+}
+
+func (m Money) mulDiv(num int64, den int64, round bool) (Money, error) {
+	if den == 0 {
+		return Money{}, errors.New("division by zero")
+	}
+
+	result := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num))
+	divisor := big.NewInt(den)
+	if round {
+		result.Add(result, new(big.Int).Quo(divisor, big.NewInt(2)))
+	}
+	result.Div(result, divisor)
+
+	if !result.IsInt64() {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return Money{Amount: result.Int64(), Currency: m.currency()}, nil
+}
+
+// Min mengembalikan nominal yang lebih kecil, keduanya harus bermata uang sama
+func (m Money) Min(other Money) Money {
+	if other.Amount < m.Amount {
+		return other
+	}
+	return m
+}
+
+// String menampilkan nominal dengan digit minor unit, misalnya "USD 12.50"
+func (m Money) String() string {
+	currency := m.currency()
+	digits := MinorUnits(currency)
+	if digits == 0 {
+		return currency + " " + strconv.FormatInt(m.Amount, 10)
+	}
+
+	amount := new(big.Int).Abs(big.NewInt(m.Amount)).String()
+	if len(amount) <= digits {
+		amount = strings.Repeat("0", digits-len(amount)+1) + amount
+	}
+
+	sign := ""
+	if m.Amount < 0 {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s %s%s.%s", currency, sign, amount[:len(amount)-digits], amount[len(amount)-digits:])
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	type money Money
+	return json.Marshal(money{Amount: m.Amount, Currency: m.currency()})
+}
+
+// UnmarshalJSON menerima objek {"amount", "currency"} atau angka biasa yang
+// dianggap dalam mata uang dasar, misalnya tarif kirim yang tersimpan sebelum
+// ada multi mata uang
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var amount int64
+	if err := json.Unmarshal(data, &amount); err == nil {
+		*m = Money{Amount: amount, Currency: baseCurrency}
+		return nil
+	}
+
+	type money Money
+	var value money
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	value.Currency = NormalizeCurrency(value.Currency)
+	if value.Currency == "" {
+		value.Currency = baseCurrency
+	}
+	if !IsCurrency(value.Currency) {
+		return fmt.Errorf("%w: %s", ErrUnknownCurrency, value.Currency)
+	}
+
+	*m = Money(value)
+	return nil
+}
+
+// Scan membaca kolom BIGINT. Mata uang diisi mata uang dasar dan diganti oleh
+// ApplyCurrency milik entity yang menyimpan kolom currency.
+func (m *Money) Scan(value interface{}) error {
+	var amount int64
+	switch v := value.(type) {
+	case int64:
+		amount = v
+	case []byte:
+		parsed, err := strconv.ParseInt(string(v), 10, 64)
+		if err != nil {
+			return err
+		}
+		amount = parsed
+	case string:
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return err
+		}
+		amount = parsed
+	default:
+		return fmt.Errorf("cannot scan %T into Money", value)
+	}
+
+	*m = Money{Amount: amount, Currency: baseCurrency}
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.Amount, nil
+}
+
+// GormDataType menjaga kolom Money tetap BIGINT
+func (Money) GormDataType() string {
+	return "bigint"
+}
+
+// Sum menjumlahkan beberapa nominal dengan mata uang currency
+func Sum(currency string, values ...Money) (Money, error) {
+	total := Money{Currency: currency}
+	for _, value := range values {
+		var err error
+		total, err = total.Add(value)
+		if err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,224 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMoneyAdd(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       Money
+		b       Money
+		want    Money
+		wantErr error
+	}{
+		{"same currency", NewMoney(150, "USD"), NewMoney(250, "USD"), NewMoney(400, "USD"), nil},
+		{"negative", NewMoney(150, "IDR"), NewMoney(-200, "IDR"), NewMoney(-50, "IDR"), nil},
+		{"empty currency is base currency", NewMoney(1, ""), NewMoney(2, "IDR"), NewMoney(3, "IDR"), nil},
+		{"up to max", NewMoney(math.MaxInt64-1, "IDR"), NewMoney(1, "IDR"), NewMoney(math.MaxInt64, "IDR"), nil},
+		{"down to min", NewMoney(math.MinInt64+1, "IDR"), NewMoney(-1, "IDR"), NewMoney(math.MinInt64, "IDR"), nil},
+		{"overflow", NewMoney(math.MaxInt64, "IDR"), NewMoney(1, "IDR"), Money{}, ErrAmountOverflow},
+		{"underflow", NewMoney(math.MinInt64, "IDR"), NewMoney(-1, "IDR"), Money{}, ErrAmountOverflow},
+		{"currency mismatch", NewMoney(1, "IDR"), NewMoney(1, "USD"), Money{}, ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Add(tt.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("%s + %s = %#v, want %#v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneySub(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       Money
+		b       Money
+		want    Money
+		wantErr error
+	}{
+		{"same currency", NewMoney(500, "IDR"), NewMoney(200, "IDR"), NewMoney(300, "IDR"), nil},
+		{"below zero", NewMoney(100, "IDR"), NewMoney(200, "IDR"), NewMoney(-100, "IDR"), nil},
+		{"subtract min int", NewMoney(0, "IDR"), NewMoney(math.MinInt64, "IDR"), Money{}, ErrAmountOverflow},
+		{"underflow", NewMoney(math.MinInt64, "IDR"), NewMoney(1, "IDR"), Money{}, ErrAmountOverflow},
+		{"currency mismatch", NewMoney(1, "EUR"), NewMoney(1, "USD"), Money{}, ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Sub(tt.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("%s - %s = %#v, want %#v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyMul(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       Money
+		n       int64
+		want    Money
+		wantErr error
+	}{
+		{"quantity", NewMoney(1250, "USD"), 3, NewMoney(3750, "USD"), nil},
+		{"zero", NewMoney(math.MaxInt64, "IDR"), 0, NewMoney(0, "IDR"), nil},
+		{"negative", NewMoney(100, "IDR"), -2, NewMoney(-200, "IDR"), nil},
+		{"max", NewMoney(math.MaxInt64, "IDR"), 1, NewMoney(math.MaxInt64, "IDR"), nil},
+		{"overflow", NewMoney(math.MaxInt64/2+1, "IDR"), 2, Money{}, ErrAmountOverflow},
+		{"large overflow", NewMoney(math.MaxInt64, "IDR"), math.MaxInt64, Money{}, ErrAmountOverflow},
+		{"min times minus one", NewMoney(math.MinInt64, "IDR"), -1, Money{}, ErrAmountOverflow},
+		{"minus one times min", NewMoney(-1, "IDR"), math.MinInt64, Money{}, ErrAmountOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.Mul(tt.n)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("%s * %d = %#v, want %#v", tt.m, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyMulDiv(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       Money
+		num     int64
+		den     int64
+		round   bool
+		want    Money
+		wantErr error
+	}{
+		{"floor", NewMoney(999, "IDR"), 10, 100, false, NewMoney(99, "IDR"), nil},
+		{"round half up", NewMoney(995, "IDR"), 10, 100, true, NewMoney(100, "IDR"), nil},
+		{"round below half", NewMoney(994, "IDR"), 10, 100, true, NewMoney(99, "IDR"), nil},
+		{"no overflow in intermediate product", NewMoney(math.MaxInt64, "IDR"), math.MaxInt64, math.MaxInt64, false, NewMoney(math.MaxInt64, "IDR"), nil},
+		{"result overflow", NewMoney(math.MaxInt64, "IDR"), 3, 2, false, Money{}, ErrAmountOverflow},
+		{"keeps currency", NewMoney(1000, "USD"), 1, 3, true, NewMoney(333, "USD"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+			var err error
+			if tt.round {
+				got, err = tt.m.MulDivRound(tt.num, tt.den)
+			} else {
+				got, err = tt.m.MulDiv(tt.num, tt.den)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("%s * %d / %d = %#v, want %#v", tt.m, tt.num, tt.den, got, tt.want)
+			}
+		})
+	}
+
+	_, err := NewMoney(1, "IDR").MulDiv(1, 0)
+	if err == nil {
+		t.Error("MulDiv by zero: err = nil, want error")
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{NewMoney(1234, "USD"), "USD 12.34"},
+		{NewMoney(5, "USD"), "USD 0.05"},
+		{NewMoney(-1250, "EUR"), "EUR -12.50"},
+		{NewMoney(15000, "IDR"), "IDR 15000"},
+		{NewMoney(15000, ""), "IDR 15000"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Money
+		wantErr bool
+	}{
+		{"bare number is base currency", `15000`, NewMoney(15000, "IDR"), false},
+		{"object", `{"amount": 1250, "currency": "USD"}`, NewMoney(1250, "USD"), false},
+		{"object with lowercase currency", `{"amount": 1250, "currency": " usd "}`, NewMoney(1250, "USD"), false},
+		{"object without currency", `{"amount": 1250}`, NewMoney(1250, "IDR"), false},
+		{"unknown currency", `{"amount": 1250, "currency": "XXX"}`, Money{}, true},
+		{"fractional number", `12.5`, Money{}, true},
+		{"string", `"15000"`, Money{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Unmarshal(%s) = %#v, want %#v", tt.data, got, tt.want)
+			}
+		})
+	}
+
+	var unknown Money
+	err := json.Unmarshal([]byte(`{"amount": 1, "currency": "XXX"}`), &unknown)
+	if !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("unknown currency: err = %v, want %v", err, ErrUnknownCurrency)
+	}
+}
+
+func TestMoneyMarshalJSON(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{NewMoney(1250, "USD"), `{"amount":1250,"currency":"USD"}`},
+		{NewMoney(15000, ""), `{"amount":15000,"currency":"IDR"}`},
+	}
+
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("Marshal(%#v) = %s, want %s", tt.m, data, tt.want)
+		}
+
+		var roundTrip Money
+		err = json.Unmarshal(data, &roundTrip)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if roundTrip.Amount != tt.m.Amount || roundTrip.Currency != tt.m.currency() {
+			t.Errorf("round trip of %#v = %#v", tt.m, roundTrip)
+		}
+	}
+}
@@ -1,46 +1,161 @@
 package entity
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type Checkout struct {
-	Email    string            `json:"email"`
-	Address  string            `json:"address"`
-	Products []ProductQuantity `json:"products"`
+	CustomerID       string            `json:"-"`
+	Email            string            `json:"email"`
+	Address          Address           `json:"address"`
+	ShippingMethodID string            `json:"shippingMethodId" binding:"required"`
+	Products         []ProductQuantity `json:"products"`
+	PromoCode        string            `json:"promoCode,omitempty"`
+	Currency         string            `json:"currency,omitempty" binding:"omitempty,len=3"`
+}
+
+// Address adalah alamat pengiriman terstruktur
+type Address struct {
+	Recipient  string `json:"recipient" binding:"required,max=100"`
+	Phone      string `json:"phone" binding:"required,max=20"`
+	Street     string `json:"street" binding:"required,max=255"`
+	City       string `json:"city" binding:"required,max=100"`
+	Province   string `json:"province" binding:"required,max=100"`
+	PostalCode string `json:"postalCode" binding:"required,max=10"`
+}
+
+// String menggabungkan alamat menjadi satu baris untuk kolom address pesanan
+func (a Address) String() string {
+	return fmt.Sprintf("%s (%s), %s, %s, %s %s", a.Recipient, a.Phone, a.Street, a.City, a.Province, a.PostalCode)
 }
 
 type ProductQuantity struct {
-	ID       string `json:"id"`
-	Quantity int32  `json:"quantity"`
+	ID        string `json:"id"`
+	VariantID string `json:"variantId,omitempty"`
+	Quantity  int32  `json:"quantity"`
 }
 
+// Order menyimpan rincian total pesanan. Data pengiriman disalin saat
+// checkout agar tidak berubah jika metode pengiriman diubah. Jika harga sudah
+// termasuk pajak (PricesIncludeTax), TaxTotal sudah terhitung di Subtotal.
+// Seluruh nominal pesanan dan turunannya memakai Currency pesanan.
 type Order struct {
-	ID          string     `json:"id"`
-	Email       string     `json:"email"`
-	Address     string     `json:"address"`
-	GrandTotal  int64      `json:"grandTotal"`
-	Passcode    *string    `json:"passcode,omitempty"`
-	PaidAt      *time.Time `json:"paidAt,omitempty"`
-	PaidBank    *string    `json:"paidBank,omitempty"`
-	PaidAccount *string    `json:"paidAccountNumber,omitempty"`
+	ID               string     `json:"id"`
+	CustomerID       *string    `json:"customerId,omitempty"`
+	Email            string     `json:"email"`
+	Address          string     `json:"address"`
+	Currency         string     `json:"currency"`
+	Subtotal         Money      `json:"subtotal"`
+	DiscountTotal    Money      `json:"discountTotal"`
+	PromoCode        *string    `json:"promoCode,omitempty"`
+	ShippingAddress  *Address   `json:"shippingAddress,omitempty" gorm:"serializer:json"`
+	ShippingMethodID *string    `json:"shippingMethodId,omitempty"`
+	ShippingMethod   *string    `json:"shippingMethod,omitempty"`
+	ShippingWeight   int64      `json:"shippingWeight"`
+	ShippingCost     Money      `json:"shippingCost"`
+	TaxTotal         Money      `json:"taxTotal"`
+	PricesIncludeTax bool       `json:"pricesIncludeTax"`
+	GrandTotal       Money      `json:"grandTotal"`
+	Status           string     `json:"status"`
+	Passcode         *string    `json:"passcode,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	ExpiresAt        *time.Time `json:"expiresAt,omitempty"`
+	PaidAt           *time.Time `json:"paidAt,omitempty"`
+	PaidBank         *string    `json:"paidBank,omitempty"`
+	PaidAccount      *string    `json:"paidAccountNumber,omitempty"`
+}
+
+// ApplyCurrency menyalin Currency pesanan ke setiap nominalnya setelah dibaca
+// dari database
+func (o *Order) ApplyCurrency() {
+	if o.Currency == "" {
+		return
+	}
+	for _, money := range []*Money{&o.Subtotal, &o.DiscountTotal, &o.ShippingCost, &o.TaxTotal, &o.GrandTotal} {
+		money.Currency = o.Currency
+	}
+}
+
+// Status pesanan beserta transisi yang diizinkan
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusExpired   = "expired"
+	OrderStatusRefunded  = "refunded"
+)
+
+var orderTransitions = map[string][]string{
+	OrderStatusPending:   {OrderStatusPaid, OrderStatusCancelled, OrderStatusExpired},
+	OrderStatusPaid:      {OrderStatusShipped, OrderStatusRefunded},
+	OrderStatusShipped:   {OrderStatusDelivered, OrderStatusRefunded},
+	OrderStatusDelivered: {OrderStatusRefunded},
 }
 
+// CanTransition melaporkan apakah pesanan boleh berpindah dari status from ke status to
+func CanTransition(from, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+type OrderStatusHistory struct {
+	ID         string    `json:"id"`
+	OrderID    string    `json:"orderId"`
+	FromStatus string    `json:"fromStatus"`
+	ToStatus   string    `json:"toStatus"`
+	Actor      string    `json:"actor"`
+	Note       *string   `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// OrderDetail adalah satu baris pesanan. Discount adalah bagian diskon promo
+// untuk baris ini dan TaxRate adalah tarif pajak dalam basis poin. Total
+// adalah nominal yang ditagihkan untuk baris ini: sudah dikurangi Discount
+// dan, jika harga belum termasuk pajak, sudah ditambah Tax.
 type OrderDetail struct {
-	ID        string `json:"id"`
-	OrderID   string `json:"orderId"`
-	ProductID string `json:"productId"`
-	Quantity  int32  `json:"quantity"`
-	Price     int64  `json:"price"`
-	Total     int64  `json:"total"`
+	ID        string            `json:"id"`
+	OrderID   string            `json:"orderId"`
+	ProductID string            `json:"productId"`
+	VariantID *string           `json:"variantId,omitempty"`
+	SKU       *string           `json:"sku,omitempty" gorm:"column:sku"`
+	Options   map[string]string `json:"options,omitempty" gorm:"serializer:json"`
+	Quantity  int32             `json:"quantity"`
+	Currency  string            `json:"-"`
+	Price     Money             `json:"price"`
+	Discount  Money             `json:"discount"`
+	TaxClass  *string           `json:"taxClass,omitempty"`
+	TaxRate   int64             `json:"taxRate"`
+	Tax       Money             `json:"tax"`
+	Total     Money             `json:"total"`
+}
+
+func (d *OrderDetail) ApplyCurrency() {
+	if d.Currency == "" {
+		return
+	}
+	for _, money := range []*Money{&d.Price, &d.Discount, &d.Tax, &d.Total} {
+		money.Currency = d.Currency
+	}
+}
+
+type OrderSummary struct {
+	Order
+	LineCount int64 `json:"lineCount"`
 }
 
 type OrderWithDetail struct {
 	Order
-	Details []OrderDetail `json:"detail,omitempty"`
+	Details   []OrderDetail   `json:"detail,omitempty"`
+	Discounts []OrderDiscount `json:"discounts,omitempty"`
 }
 
 type Confirm struct {
-	Amount        int64  `json:"amount" binding:"required"`
-	Bank          string `json:"bank" binding:"required"`
-	AccountNumber string `json:"accountNumber" binding:"required"`
-	Passcode      string `json:"passcode" binding:"required"`
+	Passcode string `json:"passcode" binding:"required"`
 }
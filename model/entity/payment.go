@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+type Payment struct {
+	ID         string    `json:"id"`
+	OrderID    string    `json:"orderId"`
+	Provider   string    `json:"provider"`
+	IntentID   string    `json:"intentId"`
+	Currency   string    `json:"-"`
+	Amount     Money     `json:"amount"`
+	Status     string    `json:"status"`
+	PaymentURL *string   `json:"paymentUrl,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+func (p *Payment) ApplyCurrency() {
+	if p.Currency != "" {
+		p.Amount.Currency = p.Currency
+	}
+}
+
+type PaymentEvent struct {
+	ID        string    `json:"id"`
+	PaymentID string    `json:"paymentId"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
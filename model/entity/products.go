@@ -1,8 +1,66 @@
 package entity
 
+import "time"
+
+// Product adalah produk katalog. Price dalam mata uang dasar dan Prices
+// berisi daftar harga produk dalam mata uang lain. Weight adalah berat produk
+// dalam gram untuk perhitungan ongkos kirim.
 type Product struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Price     int64  `json:"price"`
-	IsDeleted *bool  `json:"is_deleted,omitempty"`
+	ID        string            `json:"id"`
+	SKU       *string           `json:"sku,omitempty" gorm:"column:sku"`
+	Name      string            `json:"name"`
+	Price     Money             `json:"price"`
+	Prices    []Money           `json:"prices,omitempty" gorm:"serializer:json;->"`
+	Stock     int64             `json:"stock"`
+	Weight    int64             `json:"weight"`
+	TaxClass  string            `json:"taxClass"`
+	Images    []ProductImageURL `json:"images,omitempty" gorm:"serializer:json;->"`
+	CreatedAt *time.Time        `json:"created_at,omitempty"`
+	IsDeleted *bool             `json:"is_deleted,omitempty"`
+}
+
+// ProductSearchResult adalah produk hasil pencarian beserta skor relevansi
+// dan nama produk dengan kata yang cocok ditandai <mark>
+type ProductSearchResult struct {
+	Product
+	Highlight string  `json:"highlight"`
+	Rank      float64 `json:"rank"`
+}
+
+// ProductImport adalah rencana import produk yang diterapkan dalam satu transaksi
+type ProductImport struct {
+	Reference string
+	Creates   []Product
+	Updates   []Product
+	// Stok tujuan produk yang diubah, produk yang tidak ada di sini stoknya tetap
+	Stocks map[string]int64
+}
+
+// ProductPrice adalah harga produk atau varian dalam mata uang selain mata
+// uang dasar. VariantID kosong berarti harga untuk produk.
+type ProductPrice struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"productId"`
+	VariantID *string   `json:"variantId,omitempty"`
+	Currency  string    `json:"-"`
+	Price     Money     `json:"price"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (p *ProductPrice) ApplyCurrency() {
+	if p.Currency != "" {
+		p.Price.Currency = p.Currency
+	}
+}
+
+type StockMovement struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"productId"`
+	VariantID *string   `json:"variantId,omitempty"`
+	Change    int64     `json:"change"`
+	Reason    string    `json:"reason"`
+	Reference *string   `json:"reference,omitempty"`
+	Note      *string   `json:"note,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
 }
@@ -0,0 +1,84 @@
+package entity
+
+import "time"
+
+// Jenis promosi
+const (
+	PromotionPercentage   = "percentage"
+	PromotionFixed        = "fixed"
+	PromotionFreeShipping = "free_shipping"
+	PromotionBuyXGetY     = "buy_x_get_y"
+)
+
+// Promotion adalah kode promo yang dikelola admin. Value berisi persen
+// untuk jenis percentage dan nominal dalam Currency untuk jenis fixed.
+// ProductIDs dan CategoryIDs yang kosong berarti promo berlaku untuk semua
+// produk.
+type Promotion struct {
+	ID                 string     `json:"id"`
+	Code               string     `json:"code"`
+	Description        *string    `json:"description,omitempty"`
+	Type               string     `json:"type"`
+	Value              int64      `json:"value"`
+	Currency           string     `json:"currency"`
+	MaxDiscount        *Money     `json:"maxDiscount,omitempty"`
+	BuyQuantity        int32      `json:"buyQuantity,omitempty"`
+	GetQuantity        int32      `json:"getQuantity,omitempty"`
+	MinSubtotal        Money      `json:"minSubtotal"`
+	StartsAt           *time.Time `json:"startsAt,omitempty"`
+	EndsAt             *time.Time `json:"endsAt,omitempty"`
+	UsageLimit         *int64     `json:"usageLimit,omitempty"`
+	UsageLimitPerEmail *int64     `json:"usageLimitPerEmail,omitempty"`
+	ProductIDs         []string   `json:"productIds" gorm:"serializer:json"`
+	CategoryIDs        []string   `json:"categoryIds" gorm:"serializer:json"`
+	IsActive           bool       `json:"isActive"`
+	IsDeleted          bool       `json:"-"`
+	UsedCount          int64      `json:"usedCount" gorm:"->"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+}
+
+func (p *Promotion) ApplyCurrency() {
+	if p.Currency == "" {
+		return
+	}
+	p.MinSubtotal.Currency = p.Currency
+	if p.MaxDiscount != nil {
+		p.MaxDiscount.Currency = p.Currency
+	}
+}
+
+// RequiresCurrency melaporkan apakah promo memuat nominal sehingga hanya bisa
+// dipakai pada pesanan dengan mata uang yang sama
+func (p Promotion) RequiresCurrency() bool {
+	return p.Type == PromotionFixed || p.MaxDiscount != nil || p.MinSubtotal.IsPositive()
+}
+
+type PromotionRedemption struct {
+	ID          string    `json:"id"`
+	PromotionID string    `json:"promotionId"`
+	OrderID     string    `json:"orderId"`
+	Email       string    `json:"email"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// OrderDiscount adalah baris diskon yang tercatat pada pesanan. Diskon
+// buy_x_get_y terhubung ke baris pesanan yang mendapat barang gratis.
+type OrderDiscount struct {
+	ID            string    `json:"id"`
+	OrderID       string    `json:"orderId"`
+	OrderDetailID *string   `json:"orderDetailId,omitempty"`
+	PromotionID   string    `json:"promotionId"`
+	Code          string    `json:"code"`
+	Type          string    `json:"type"`
+	Description   string    `json:"description"`
+	Currency      string    `json:"-"`
+	Amount        Money     `json:"amount"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+func (d *OrderDiscount) ApplyCurrency() {
+	if d.Currency != "" {
+		d.Amount.Currency = d.Currency
+	}
+}
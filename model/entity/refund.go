@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+// Status refund
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+)
+
+// Refund adalah pengembalian dana pesanan. ShippingAmount adalah bagian
+// Amount yang mengembalikan ongkos kirim.
+type Refund struct {
+	ID               string       `json:"id"`
+	OrderID          string       `json:"orderId"`
+	PaymentID        string       `json:"paymentId"`
+	ProviderRefundID *string      `json:"providerRefundId,omitempty"`
+	Currency         string       `json:"-"`
+	Amount           Money        `json:"amount"`
+	ShippingAmount   Money        `json:"shippingAmount"`
+	Reason           string       `json:"reason"`
+	Status           string       `json:"status"`
+	Actor            string       `json:"actor"`
+	CreatedAt        time.Time    `json:"createdAt"`
+	UpdatedAt        time.Time    `json:"updatedAt"`
+	Lines            []RefundLine `json:"lines" gorm:"foreignKey:RefundID"`
+}
+
+// ApplyCurrency juga berlaku untuk baris refund yang memakai mata uang refund
+func (r *Refund) ApplyCurrency() {
+	if r.Currency == "" {
+		return
+	}
+	r.Amount.Currency = r.Currency
+	r.ShippingAmount.Currency = r.Currency
+	for i := range r.Lines {
+		r.Lines[i].Currency = r.Currency
+		r.Lines[i].Amount.Currency = r.Currency
+	}
+}
+
+type RefundLine struct {
+	ID            string `json:"id"`
+	RefundID      string `json:"refundId"`
+	OrderDetailID string `json:"orderDetailId"`
+	Quantity      int32  `json:"quantity"`
+	Currency      string `json:"-"`
+	Amount        Money  `json:"amount"`
+}
@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// ShippingMethod adalah metode pengiriman yang dikelola admin beserta tabel
+// tarifnya berdasarkan provinsi tujuan dan berat paket
+type ShippingMethod struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description *string        `json:"description,omitempty"`
+	Rates       []ShippingRate `json:"rates" gorm:"serializer:json"`
+	SortOrder   int            `json:"sortOrder"`
+	IsActive    bool           `json:"isActive"`
+	IsDeleted   bool           `json:"-"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+}
+
+// ShippingRate adalah satu baris tabel tarif. Provinces yang kosong berlaku
+// untuk semua provinsi, MaxWeight yang kosong berarti tanpa batas atas.
+// Berat dalam gram, MinWeight inklusif dan MaxWeight eksklusif. Tarif hanya
+// berlaku untuk pesanan dengan mata uang yang sama dengan Price.
+type ShippingRate struct {
+	Zone      string   `json:"zone"`
+	Provinces []string `json:"provinces"`
+	MinWeight int64    `json:"minWeight"`
+	MaxWeight *int64   `json:"maxWeight,omitempty"`
+	Price     Money    `json:"price"`
+}
+
+// Rate mencari tarif untuk provinsi, berat, dan mata uang tertentu. Tarif yang
+// menyebut provinsi secara khusus didahulukan daripada tarif untuk semua
+// provinsi.
+func (m ShippingMethod) Rate(province string, weight int64, currency string) (ShippingRate, bool) {
+	var fallback *ShippingRate
+	for i, rate := range m.Rates {
+		if rate.Price.Currency != currency {
+			continue
+		}
+		if weight < rate.MinWeight || (rate.MaxWeight != nil && weight >= *rate.MaxWeight) {
+			continue
+		}
+
+		if len(rate.Provinces) == 0 {
+			if fallback == nil {
+				fallback = &m.Rates[i]
+			}
+			continue
+		}
+
+		for _, p := range rate.Provinces {
+			if strings.EqualFold(strings.TrimSpace(p), strings.TrimSpace(province)) {
+				return rate, true
+			}
+		}
+	}
+
+	if fallback != nil {
+		return *fallback, true
+	}
+	return ShippingRate{}, false
+}
+
+type ShippingQuote struct {
+	MethodID    string  `json:"methodId"`
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+	Zone        string  `json:"zone"`
+	Weight      int64   `json:"weight"`
+	Cost        Money   `json:"cost"`
+}
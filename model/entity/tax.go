@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// DefaultTaxClass dipakai untuk produk yang tidak menentukan kelas pajak
+const DefaultTaxClass = "standard"
+
+type TaxClass struct {
+	Code      string    `json:"code" gorm:"primaryKey"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TaxRate adalah tarif pajak sebuah kelas pajak di suatu provinsi. Province
+// kosong berlaku untuk provinsi yang tidak memiliki tarif sendiri. Rate dalam
+// basis poin, 1100 berarti 11%.
+type TaxRate struct {
+	ID        string    `json:"id"`
+	TaxClass  string    `json:"taxClass"`
+	Province  string    `json:"province"`
+	Name      string    `json:"name"`
+	Rate      int64     `json:"rate"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// ProductOption adalah jenis opsi produk (misalnya ukuran atau warna)
+// beserta nilai yang tersedia
+type ProductOption struct {
+	ID        string   `json:"id"`
+	ProductID string   `json:"productId"`
+	Name      string   `json:"name"`
+	Values    []string `json:"values" gorm:"column:option_values;serializer:json"`
+	Position  int      `json:"position"`
+}
+
+type ProductVariant struct {
+	ID        string            `json:"id"`
+	ProductID string            `json:"productId"`
+	SKU       string            `json:"sku" gorm:"column:sku"`
+	Options   map[string]string `json:"options" gorm:"serializer:json"`
+	Price     *Money            `json:"price,omitempty"`
+	Stock     int64             `json:"stock"`
+	IsDeleted bool              `json:"-"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// EffectivePrice mengembalikan harga varian, atau harga produk jika varian
+// tidak memiliki harga sendiri
+func (v ProductVariant) EffectivePrice(productPrice Money) Money {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return productPrice
+}
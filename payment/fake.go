@@ -0,0 +1,179 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Skenario yang bisa disimulasikan oleh fake provider
+const (
+	ScenarioSuccess = "success"
+	ScenarioFailure = "failure"
+	ScenarioDelayed = "delayed"
+)
+
+type FakeOptions struct {
+	Scenario    string
+	Delay       time.Duration
+	CallbackURL string
+}
+
+type fakeIntent struct {
+	intent    Intent
+	settleAt  time.Time
+	settledTo string
+}
+
+// fakeProvider menyimpan intent di memori dan menyelesaikannya sesuai
+// skenario. Hanya untuk pengembangan lokal dan pengujian.
+type fakeProvider struct {
+	secret  string
+	options FakeOptions
+	client  *http.Client
+	mu      sync.Mutex
+	intents map[string]*fakeIntent
+}
+
+func NewFakeProvider(secret string, options FakeOptions) Provider {
+	if options.Scenario == "" {
+		options.Scenario = ScenarioSuccess
+	}
+
+	return &fakeProvider{
+		secret:  secret,
+		options: options,
+		client:  &http.Client{Timeout: 5 * time.Second},
+		intents: make(map[string]*fakeIntent),
+	}
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) CreateIntent(c context.Context, orderID string, amount int64, currency string) (Intent, error) {
+	intent := Intent{
+		ID:         "fake_" + uuid.NewString(),
+		OrderID:    orderID,
+		Amount:     amount,
+		Currency:   currency,
+		Status:     StatusPending,
+		PaymentURL: "https://payment.fake.local/pay/" + orderID,
+	}
+
+	settle := &fakeIntent{intent: intent, settleAt: time.Now(), settledTo: StatusSucceeded}
+	switch p.options.Scenario {
+	case ScenarioFailure:
+		settle.settledTo = StatusFailed
+	case ScenarioDelayed:
+		settle.settleAt = time.Now().Add(p.options.Delay)
+	}
+
+	p.mu.Lock()
+	p.intents[intent.ID] = settle
+	p.mu.Unlock()
+
+	if p.options.CallbackURL != "" {
+		go p.sendCallback(intent.ID, time.Until(settle.settleAt))
+	}
+
+	return intent, nil
+}
+
+func (p *fakeProvider) ParseCallback(payload []byte, signature string) (Event, error) {
+	var event Event
+
+	if !Verify(payload, signature, p.secret) {
+		return event, ErrInvalidSignature
+	}
+
+	err := json.Unmarshal(payload, &event)
+	if err != nil {
+		return event, err
+	}
+
+	return event, nil
+}
+
+func (p *fakeProvider) GetStatus(c context.Context, intentID string) (Intent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	settle, exists := p.intents[intentID]
+	if !exists {
+		return Intent{}, errors.New("payment intent not found")
+	}
+
+	if settle.intent.Status == StatusPending && !time.Now().Before(settle.settleAt) {
+		settle.intent.Status = settle.settledTo
+	}
+
+	return settle.intent, nil
+}
+
+func (p *fakeProvider) Refund(c context.Context, intentID string, amount int64) (Refund, error) {
+	intent, err := p.GetStatus(c, intentID)
+	if err != nil {
+		return Refund{}, err
+	}
+
+	if intent.Status != StatusSucceeded {
+		return Refund{}, errors.New("payment has not been settled")
+	}
+
+	refund := Refund{
+		ID:       "fake_refund_" + uuid.NewString(),
+		IntentID: intentID,
+		Amount:   amount,
+		Status:   StatusSucceeded,
+	}
+
+	return refund, nil
+}
+
+// sendCallback mengirim callback bertanda tangan ke aplikasi setelah
+// intent diselesaikan, meniru webhook dari payment gateway sungguhan
+func (p *fakeProvider) sendCallback(intentID string, delay time.Duration) {
+	time.Sleep(delay)
+
+	intent, err := p.GetStatus(context.Background(), intentID)
+	if err != nil {
+		log.Println("fake payment callback:", err)
+		return
+	}
+
+	payload, err := json.Marshal(Event{
+		ID:         uuid.NewString(),
+		IntentID:   intent.ID,
+		Status:     intent.Status,
+		Amount:     intent.Amount,
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		log.Println("fake payment callback:", err)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.options.CallbackURL, bytes.NewReader(payload))
+	if err != nil {
+		log.Println("fake payment callback:", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(payload, p.secret))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		log.Println("fake payment callback:", err)
+		return
+	}
+	resp.Body.Close()
+}
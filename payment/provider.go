@@ -0,0 +1,81 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Status pembayaran yang dilaporkan oleh provider
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusRefunded  = "refunded"
+)
+
+// SignatureHeader adalah header HTTP yang memuat signature callback
+const SignatureHeader = "X-Payment-Signature"
+
+var ErrInvalidSignature = errors.New("invalid payment callback signature")
+
+type Intent struct {
+	ID         string `json:"id"`
+	OrderID    string `json:"orderId"`
+	Amount     int64  `json:"amount"`
+	Currency   string `json:"currency"`
+	Status     string `json:"status"`
+	PaymentURL string `json:"paymentUrl,omitempty"`
+}
+
+type Event struct {
+	ID         string    `json:"id"`
+	IntentID   string    `json:"intentId"`
+	Status     string    `json:"status"`
+	Amount     int64     `json:"amount"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+type Refund struct {
+	ID       string `json:"id"`
+	IntentID string `json:"intentId"`
+	Amount   int64  `json:"amount"`
+	Status   string `json:"status"`
+}
+
+// Provider adalah abstraksi payment gateway yang dipakai oleh alur pesanan.
+// Nominal dalam minor unit mata uang intent, refund memakai mata uang intent.
+type Provider interface {
+	Name() string
+	CreateIntent(c context.Context, orderID string, amount int64, currency string) (Intent, error)
+	ParseCallback(payload []byte, signature string) (Event, error)
+	GetStatus(c context.Context, intentID string) (Intent, error)
+	Refund(c context.Context, intentID string, amount int64) (Refund, error)
+}
+
+// NewProvider membuat provider berdasarkan nama yang dikonfigurasi
+func NewProvider(name string, secret string, options FakeOptions) (Provider, error) {
+	switch name {
+	case "fake":
+		return NewFakeProvider(secret, options), nil
+	default:
+		return nil, fmt.Errorf("payment provider %s not supported", name)
+	}
+}
+
+// Sign menghasilkan signature HMAC-SHA256 untuk payload callback
+func Sign(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify membandingkan signature secara constant-time
+func Verify(payload []byte, signature string, secret string) bool {
+	expected := Sign(payload, secret)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"online-shop/model/entity"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type AdminRepository interface {
+	CountUsers(c context.Context) (int64, error)
+	CreateUser(c context.Context, user entity.AdminUser) (entity.AdminUser, error)
+	GetUserByID(c context.Context, id string) (entity.AdminUser, error)
+	GetUserByUsername(c context.Context, username string) (entity.AdminUser, error)
+	ListUsers(c context.Context) ([]entity.AdminUser, error)
+	UpdateUser(c context.Context, user entity.AdminUser) (entity.AdminUser, error)
+	CreateToken(c context.Context, token entity.AdminToken) (entity.AdminToken, error)
+	GetTokenByHash(c context.Context, tokenHash string) (entity.AdminToken, error)
+	TouchToken(c context.Context, id string, usedAt time.Time) error
+	RevokeToken(c context.Context, id string) error
+	RevokeUserTokens(c context.Context, adminID string) error
+}
+
+type adminRepository struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+func NewAdminRepository(db *gorm.DB, redis *redis.Client) AdminRepository {
+	return &adminRepository{db, redis}
+}
+
+func (r *adminRepository) CountUsers(c context.Context) (int64, error) {
+	var count int64
+
+	err := conn(c, r.db).Model(&entity.AdminUser{}).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *adminRepository) CreateUser(c context.Context, user entity.AdminUser) (entity.AdminUser, error) {
+	existing, err := r.GetUserByUsername(c, user.Username)
+	if err != nil {
+		return user, err
+	}
+
+	if existing.ID != "" {
+		return user, errors.New("username is already taken")
+	}
+
+	err = conn(c, r.db).Create(&user).Error
+	if err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (r *adminRepository) GetUserByID(c context.Context, id string) (entity.AdminUser, error) {
+	var user entity.AdminUser
+
+	err := conn(c, r.db).Where("id = ?", id).Limit(1).Find(&user).Error
+	if err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (r *adminRepository) GetUserByUsername(c context.Context, username string) (entity.AdminUser, error) {
+	var user entity.AdminUser
+
+	err := conn(c, r.db).Where("LOWER(username) = ?", strings.ToLower(username)).Limit(1).Find(&user).Error
+	if err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (r *adminRepository) ListUsers(c context.Context) ([]entity.AdminUser, error) {
+	users := []entity.AdminUser{}
+
+	err := conn(c, r.db).Order("username ASC").Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *adminRepository) UpdateUser(c context.Context, user entity.AdminUser) (entity.AdminUser, error) {
+	err := conn(c, r.db).
+		Model(&user).
+		Select("password_hash", "role", "is_active", "updated_at").
+		Updates(&user).Error
+	if err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (r *adminRepository) CreateToken(c context.Context, token entity.AdminToken) (entity.AdminToken, error) {
+	err := conn(c, r.db).Create(&token).Error
+	if err != nil {
+		return token, err
+	}
+
+	return token, nil
+}
+
+func (r *adminRepository) GetTokenByHash(c context.Context, tokenHash string) (entity.AdminToken, error) {
+	var token entity.AdminToken
+
+	err := conn(c, r.db).Where("token_hash = ?", tokenHash).Limit(1).Find(&token).Error
+	if err != nil {
+		return token, err
+	}
+
+	return token, nil
+}
+
+func (r *adminRepository) TouchToken(c context.Context, id string, usedAt time.Time) error {
+	return conn(c, r.db).
+		Model(&entity.AdminToken{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt).Error
+}
+
+func (r *adminRepository) RevokeToken(c context.Context, id string) error {
+	return conn(c, r.db).
+		Model(&entity.AdminToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *adminRepository) RevokeUserTokens(c context.Context, adminID string) error {
+	return conn(c, r.db).
+		Model(&entity.AdminToken{}).
+		Where("admin_id = ? AND revoked_at IS NULL", adminID).
+		Update("revoked_at", time.Now()).Error
+}
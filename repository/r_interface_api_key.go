@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"online-shop/model/entity"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(c context.Context, key entity.APIKey) (entity.APIKey, error)
+	GetByID(c context.Context, id string) (entity.APIKey, error)
+	GetByHash(c context.Context, keyHash string) (entity.APIKey, error)
+	List(c context.Context) ([]entity.APIKey, error)
+	Revoke(c context.Context, id string) error
+	Touch(c context.Context, id string, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+func NewAPIKeyRepository(db *gorm.DB, redis *redis.Client) APIKeyRepository {
+	return &apiKeyRepository{db, redis}
+}
+
+func (r *apiKeyRepository) Create(c context.Context, key entity.APIKey) (entity.APIKey, error) {
+	err := conn(c, r.db).Create(&key).Error
+	if err != nil {
+		return key, err
+	}
+
+	return key, nil
+}
+
+func (r *apiKeyRepository) GetByID(c context.Context, id string) (entity.APIKey, error) {
+	var key entity.APIKey
+
+	err := conn(c, r.db).Where("id = ?", id).Limit(1).Find(&key).Error
+	if err != nil {
+		return key, err
+	}
+
+	return key, nil
+}
+
+func (r *apiKeyRepository) GetByHash(c context.Context, keyHash string) (entity.APIKey, error) {
+	var key entity.APIKey
+
+	err := conn(c, r.db).Where("key_hash = ?", keyHash).Limit(1).Find(&key).Error
+	if err != nil {
+		return key, err
+	}
+
+	return key, nil
+}
+
+func (r *apiKeyRepository) List(c context.Context) ([]entity.APIKey, error) {
+	keys := []entity.APIKey{}
+
+	err := conn(c, r.db).Order("created_at DESC").Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Revoke(c context.Context, id string) error {
+	return conn(c, r.db).
+		Model(&entity.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *apiKeyRepository) Touch(c context.Context, id string, usedAt time.Time) error {
+	return conn(c, r.db).
+		Model(&entity.APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt).Error
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepository interface {
+	Create(c context.Context, log entity.AuditLog) error
+	List(c context.Context, query dto.ReqAuditQuery) (dto.ResAuditLogs, error)
+	Transaction(c context.Context, fn func(c context.Context) error) error
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db}
+}
+
+func (r *auditRepository) Create(c context.Context, log entity.AuditLog) error {
+	return conn(c, r.db).Create(&log).Error
+}
+
+func (r *auditRepository) List(c context.Context, query dto.ReqAuditQuery) (dto.ResAuditLogs, error) {
+	result := dto.ResAuditLogs{Page: query.Page, Limit: query.Limit}
+
+	tx := conn(c, r.db).Model(&entity.AuditLog{})
+	if query.Actor != "" {
+		tx = tx.Where("actor = ?", query.Actor)
+	}
+	if query.Action != "" {
+		tx = tx.Where("action = ?", query.Action)
+	}
+	if query.EntityType != "" {
+		tx = tx.Where("entity_type = ?", query.EntityType)
+	}
+	if query.EntityID != "" {
+		tx = tx.Where("entity_id = ?", query.EntityID)
+	}
+	if query.RequestID != "" {
+		tx = tx.Where("request_id = ?", query.RequestID)
+	}
+	if query.DateFrom != nil {
+		tx = tx.Where("created_at >= ?", *query.DateFrom)
+	}
+	if query.DateTo != nil {
+		// date_to inklusif sampai akhir hari
+		tx = tx.Where("created_at < ?", query.DateTo.AddDate(0, 0, 1))
+	}
+	tx = tx.Session(&gorm.Session{})
+
+	err := tx.Count(&result.Total).Error
+	if err != nil {
+		return result, err
+	}
+
+	result.Data = []entity.AuditLog{}
+	err = tx.Order("created_at DESC, id ASC").
+		Limit(query.Limit).
+		Offset((query.Page - 1) * query.Limit).
+		Find(&result.Data).Error
+	if err != nil {
+		return result, err
+	}
+
+	result.TotalPages = int((result.Total + int64(query.Limit) - 1) / int64(query.Limit))
+
+	return result, nil
+}
+
+// Transaction menjalankan fn dalam satu transaksi database. Repository lain
+// yang dipanggil dengan context dari fn ikut transaksi yang sama.
+func (r *auditRepository) Transaction(c context.Context, fn func(c context.Context) error) error {
+	return runInTransaction(c, r.db, fn)
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"online-shop/model/entity"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+var ErrCartConflict = errors.New("cart was modified concurrently, please retry")
+
+// cartUpdateRetries adalah jumlah percobaan ulang saat keranjang diubah
+// oleh request lain di tengah transaksi WATCH
+const cartUpdateRetries = 5
+
+// CartRepository menyimpan keranjang di Redis dengan TTL yang diperpanjang
+// setiap kali keranjang diubah. Keranjang pelanggan juga dicatat di key
+// terpisah agar bisa ditemukan dari id pelanggan.
+type CartRepository interface {
+	Get(c context.Context, id string) (entity.Cart, error)
+	GetByCustomerID(c context.Context, customerID string) (entity.Cart, error)
+	Update(c context.Context, id string, fn func(cart *entity.Cart) error) (entity.Cart, error)
+	Delete(c context.Context, cart entity.Cart) error
+	Merge(c context.Context, guestID string, customerID string) (entity.Cart, error)
+}
+
+type cartRepository struct {
+	redis *redis.Client
+}
+
+func NewCartRepository(redis *redis.Client) CartRepository {
+	return &cartRepository{redis}
+}
+
+// Get mengembalikan keranjang kosong tanpa ID jika keranjang tidak ada
+func (r *cartRepository) Get(c context.Context, id string) (entity.Cart, error) {
+	return getCart(c, r.redis, id)
+}
+
+func (r *cartRepository) GetByCustomerID(c context.Context, customerID string) (entity.Cart, error) {
+	id, err := r.redis.Get(c, customerCartKey(customerID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return entity.Cart{}, nil
+	}
+	if err != nil {
+		return entity.Cart{}, err
+	}
+
+	return getCart(c, r.redis, id)
+}
+
+// Update membaca keranjang, menjalankan fn, lalu menyimpannya secara atomik.
+// fn menerima keranjang tanpa ID jika keranjang belum ada.
+func (r *cartRepository) Update(c context.Context, id string, fn func(cart *entity.Cart) error) (entity.Cart, error) {
+	var result entity.Cart
+
+	update := func(tx *redis.Tx) error {
+		cart, err := getCart(c, tx, id)
+		if err != nil {
+			return err
+		}
+
+		err = fn(&cart)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(c, func(pipe redis.Pipeliner) error {
+			setCart(c, pipe, &cart)
+			return nil
+		})
+		result = cart
+		return err
+	}
+
+	return result, r.watch(c, update, cartKey(id))
+}
+
+// Delete menghapus keranjang beserta penanda keranjang pelanggan jika masih
+// menunjuk ke keranjang ini
+func (r *cartRepository) Delete(c context.Context, cart entity.Cart) error {
+	err := r.redis.Del(c, cartKey(cart.ID)).Err()
+	if err != nil || cart.CustomerID == nil {
+		return err
+	}
+
+	current, err := r.redis.Get(c, customerCartKey(*cart.CustomerID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil || current != cart.ID {
+		return err
+	}
+
+	return r.redis.Del(c, customerCartKey(*cart.CustomerID)).Err()
+}
+
+// Merge menggabungkan keranjang tamu ke keranjang pelanggan saat login.
+// Jika pelanggan belum memiliki keranjang, keranjang tamu menjadi milik
+// pelanggan. Keranjang milik pelanggan lain tidak ikut digabung.
+func (r *cartRepository) Merge(c context.Context, guestID string, customerID string) (entity.Cart, error) {
+	customerCartID, err := r.redis.Get(c, customerCartKey(customerID)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return entity.Cart{}, err
+	}
+
+	if guestID == "" || guestID == customerCartID {
+		if customerCartID == "" {
+			return entity.Cart{}, nil
+		}
+		return getCart(c, r.redis, customerCartID)
+	}
+
+	var result entity.Cart
+	merge := func(tx *redis.Tx) error {
+		guest, err := getCart(c, tx, guestID)
+		if err != nil {
+			return err
+		}
+
+		target := entity.Cart{}
+		if customerCartID != "" {
+			target, err = getCart(c, tx, customerCartID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if guest.ID == "" || (guest.CustomerID != nil && *guest.CustomerID != customerID) {
+			result = target
+			return nil
+		}
+
+		if target.ID == "" {
+			target = guest
+			target.CustomerID = &customerID
+		} else {
+			target.Merge(guest)
+		}
+		target.UpdatedAt = time.Now()
+
+		_, err = tx.TxPipelined(c, func(pipe redis.Pipeliner) error {
+			setCart(c, pipe, &target)
+			if target.ID != guest.ID {
+				pipe.Del(c, cartKey(guest.ID))
+			}
+			return nil
+		})
+		result = target
+		return err
+	}
+
+	keys := []string{cartKey(guestID), customerCartKey(customerID)}
+	if customerCartID != "" {
+		keys = append(keys, cartKey(customerCartID))
+	}
+
+	return result, r.watch(c, merge, keys...)
+}
+
+// watch menjalankan transaksi optimistic dan mengulanginya jika key yang
+// diawasi berubah sebelum transaksi selesai
+func (r *cartRepository) watch(c context.Context, fn func(tx *redis.Tx) error, keys ...string) error {
+	for i := 0; i < cartUpdateRetries; i++ {
+		err := r.redis.Watch(c, fn, keys...)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		return err
+	}
+
+	return ErrCartConflict
+}
+
+func getCart(c context.Context, cmd redis.Cmdable, id string) (entity.Cart, error) {
+	var cart entity.Cart
+
+	data, err := cmd.Get(c, cartKey(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		return cart, nil
+	}
+	if err != nil {
+		return cart, err
+	}
+
+	err = json.Unmarshal([]byte(data), &cart)
+	if err != nil {
+		return cart, err
+	}
+
+	return cart, nil
+}
+
+// setCart menyimpan keranjang dan memperpanjang masa berlakunya
+func setCart(c context.Context, pipe redis.Pipeliner, cart *entity.Cart) {
+	ttl := viper.GetDuration("CART_TTL")
+	cart.ExpiresAt = time.Now().Add(ttl)
+
+	// Error marshal tidak mungkin terjadi karena Cart hanya berisi tipe dasar
+	data, _ := json.Marshal(cart)
+	pipe.Set(c, cartKey(cart.ID), data, ttl)
+	if cart.CustomerID != nil {
+		pipe.Set(c, customerCartKey(*cart.CustomerID), cart.ID, ttl)
+	}
+}
+
+func cartKey(id string) string {
+	return viper.GetString("CART_KEY") + id
+}
+
+func customerCartKey(customerID string) string {
+	return viper.GetString("CART_CUSTOMER_KEY") + customerID
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"online-shop/model/entity"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+func newTestCartRepository(t *testing.T) (CartRepository, *redis.Client) {
+	t.Helper()
+
+	viper.Set("CART_KEY", "cart_")
+	viper.Set("CART_CUSTOMER_KEY", "cart_customer_")
+	viper.Set("CART_TTL", "720h")
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	// Client terpisah dipakai untuk meniru request lain yang mengubah keranjang
+	other := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { other.Close() })
+
+	return NewCartRepository(rdb), other
+}
+
+// saveCart menyimpan keranjang lewat Update seperti yang dilakukan usecase
+func saveCart(t *testing.T, repo CartRepository, cart entity.Cart) {
+	t.Helper()
+
+	_, err := repo.Update(context.Background(), cart.ID, func(current *entity.Cart) error {
+		*current = cart
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func cartQuantities(cart entity.Cart) map[string]int32 {
+	quantities := make(map[string]int32, len(cart.Items))
+	for _, item := range cart.Items {
+		quantities[item.ProductID] = item.Quantity
+	}
+	return quantities
+}
+
+func TestCartUpdateCreatesCart(t *testing.T) {
+	repo, _ := newTestCartRepository(t)
+	c := context.Background()
+	customerID := "customer-1"
+
+	result, err := repo.Update(c, "cart-1", func(cart *entity.Cart) error {
+		if cart.ID != "" {
+			t.Errorf("new cart ID = %q, want empty", cart.ID)
+		}
+		cart.ID = "cart-1"
+		cart.CustomerID = &customerID
+		return cart.AddItem("product-1", "", 2)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExpiresAt.Before(time.Now().Add(719 * time.Hour)) {
+		t.Errorf("ExpiresAt = %v, want about CART_TTL from now", result.ExpiresAt)
+	}
+
+	got, err := repo.GetByCustomerID(c, customerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "cart-1" || cartQuantities(got)["product-1"] != 2 {
+		t.Errorf("GetByCustomerID() = %+v, want cart-1 with 2 x product-1", got)
+	}
+}
+
+func TestCartUpdateKeepsCartWhenFnFails(t *testing.T) {
+	repo, _ := newTestCartRepository(t)
+	c := context.Background()
+	saveCart(t, repo, entity.Cart{ID: "cart-1", Items: []entity.CartItem{{ProductID: "product-1", Quantity: 1}}})
+
+	errFn := errors.New("rejected")
+	_, err := repo.Update(c, "cart-1", func(cart *entity.Cart) error {
+		cart.Items = nil
+		return errFn
+	})
+	if !errors.Is(err, errFn) {
+		t.Fatalf("Update() error = %v, want %v", err, errFn)
+	}
+
+	got, err := repo.Get(c, "cart-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cartQuantities(got)["product-1"] != 1 {
+		t.Errorf("cart changed after failed update: %+v", got.Items)
+	}
+}
+
+func TestCartUpdateRetriesConcurrentChange(t *testing.T) {
+	repo, other := newTestCartRepository(t)
+	c := context.Background()
+	saveCart(t, repo, entity.Cart{ID: "cart-1", Items: []entity.CartItem{{ProductID: "product-1", Quantity: 1}}})
+
+	concurrent := NewCartRepository(other)
+	calls := 0
+	result, err := repo.Update(c, "cart-1", func(cart *entity.Cart) error {
+		calls++
+		if calls == 1 {
+			// Request lain menambah barang setelah keranjang dibaca
+			saveCart(t, concurrent, entity.Cart{ID: "cart-1", Items: []entity.CartItem{
+				{ProductID: "product-1", Quantity: 1},
+				{ProductID: "product-2", Quantity: 1},
+			}})
+		}
+		return cart.AddItem("product-1", "", 1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("fn called %d times, want 2", calls)
+	}
+
+	want := map[string]int32{"product-1": 2, "product-2": 1}
+	got, err := repo.Get(c, "cart-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cart := range []entity.Cart{result, got} {
+		quantities := cartQuantities(cart)
+		if len(quantities) != len(want) || quantities["product-1"] != want["product-1"] || quantities["product-2"] != want["product-2"] {
+			t.Errorf("cart items = %v, want %v", quantities, want)
+		}
+	}
+}
+
+func TestCartUpdateReturnsConflictAfterRetries(t *testing.T) {
+	repo, other := newTestCartRepository(t)
+	c := context.Background()
+	saveCart(t, repo, entity.Cart{ID: "cart-1"})
+
+	calls := 0
+	_, err := repo.Update(c, "cart-1", func(cart *entity.Cart) error {
+		calls++
+		return other.Set(c, cartKey("cart-1"), `{"id":"cart-1","items":[]}`, 0).Err()
+	})
+	if !errors.Is(err, ErrCartConflict) {
+		t.Fatalf("Update() error = %v, want %v", err, ErrCartConflict)
+	}
+	if calls != cartUpdateRetries {
+		t.Errorf("fn called %d times, want %d", calls, cartUpdateRetries)
+	}
+}
+
+func TestCartMerge(t *testing.T) {
+	customerID := "customer-1"
+	otherCustomerID := "customer-2"
+
+	tests := []struct {
+		name         string
+		guest        *entity.Cart
+		customer     *entity.Cart
+		guestID      string
+		wantID       string
+		wantItems    map[string]int32
+		wantGuestKey bool
+	}{
+		{
+			name:         "guest cart becomes the customer cart",
+			guest:        &entity.Cart{ID: "guest", Items: []entity.CartItem{{ProductID: "product-1", Quantity: 1}}},
+			guestID:      "guest",
+			wantID:       "guest",
+			wantItems:    map[string]int32{"product-1": 1},
+			wantGuestKey: true,
+		},
+		{
+			name:      "guest items are added to the customer cart",
+			guest:     &entity.Cart{ID: "guest", Items: []entity.CartItem{{ProductID: "product-1", Quantity: 1}, {ProductID: "product-2", Quantity: 3}}},
+			customer:  &entity.Cart{ID: "own", CustomerID: &customerID, Items: []entity.CartItem{{ProductID: "product-1", Quantity: 2}}},
+			guestID:   "guest",
+			wantID:    "own",
+			wantItems: map[string]int32{"product-1": 3, "product-2": 3},
+		},
+		{
+			name:         "cart of another customer is not merged",
+			guest:        &entity.Cart{ID: "guest", CustomerID: &otherCustomerID, Items: []entity.CartItem{{ProductID: "product-1", Quantity: 1}}},
+			customer:     &entity.Cart{ID: "own", CustomerID: &customerID, Items: []entity.CartItem{{ProductID: "product-2", Quantity: 1}}},
+			guestID:      "guest",
+			wantID:       "own",
+			wantItems:    map[string]int32{"product-2": 1},
+			wantGuestKey: true,
+		},
+		{
+			name:         "cart of another customer is not taken over",
+			guest:        &entity.Cart{ID: "guest", CustomerID: &otherCustomerID, Items: []entity.CartItem{{ProductID: "product-1", Quantity: 1}}},
+			guestID:      "guest",
+			wantID:       "",
+			wantItems:    map[string]int32{},
+			wantGuestKey: true,
+		},
+		{
+			name:      "missing guest cart keeps the customer cart",
+			customer:  &entity.Cart{ID: "own", CustomerID: &customerID, Items: []entity.CartItem{{ProductID: "product-2", Quantity: 1}}},
+			guestID:   "guest",
+			wantID:    "own",
+			wantItems: map[string]int32{"product-2": 1},
+		},
+		{
+			name:      "without guest cart returns the customer cart",
+			customer:  &entity.Cart{ID: "own", CustomerID: &customerID, Items: []entity.CartItem{{ProductID: "product-2", Quantity: 1}}},
+			wantID:    "own",
+			wantItems: map[string]int32{"product-2": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, other := newTestCartRepository(t)
+			c := context.Background()
+			if tt.guest != nil {
+				saveCart(t, repo, *tt.guest)
+			}
+			if tt.customer != nil {
+				saveCart(t, repo, *tt.customer)
+			}
+
+			result, err := repo.Merge(c, tt.guestID, customerID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.ID != tt.wantID {
+				t.Errorf("Merge() ID = %q, want %q", result.ID, tt.wantID)
+			}
+			quantities := cartQuantities(result)
+			if len(quantities) != len(tt.wantItems) {
+				t.Errorf("Merge() items = %v, want %v", quantities, tt.wantItems)
+			}
+			for productID, quantity := range tt.wantItems {
+				if quantities[productID] != quantity {
+					t.Errorf("Merge() items = %v, want %v", quantities, tt.wantItems)
+				}
+			}
+
+			// Keranjang hasil merge tercatat sebagai keranjang pelanggan
+			if tt.wantID != "" {
+				current, err := repo.GetByCustomerID(c, customerID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if current.ID != tt.wantID || current.CustomerID == nil || *current.CustomerID != customerID {
+					t.Errorf("GetByCustomerID() = %q owned by %v, want %q", current.ID, current.CustomerID, tt.wantID)
+				}
+			}
+
+			if tt.guestID != "" && tt.guestID != tt.wantID {
+				exists, err := other.Exists(c, cartKey(tt.guestID)).Result()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if (exists == 1) != tt.wantGuestKey {
+					t.Errorf("guest cart exists = %v, want %v", exists == 1, tt.wantGuestKey)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"online-shop/model/entity"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+type CategoryRepository interface {
+	GetAll(c context.Context) ([]entity.Category, error)
+	GetByID(c context.Context, id string) (entity.Category, error)
+	GetBySlug(c context.Context, slug string) (entity.Category, error)
+	GetByIDs(c context.Context, ids []string) ([]entity.Category, error)
+	GetDescendantIDs(c context.Context, id string) ([]string, error)
+	CountChildren(c context.Context, id string) (int64, error)
+	Create(c context.Context, category entity.Category) (entity.Category, error)
+	Update(c context.Context, category entity.Category) (entity.Category, error)
+	Delete(c context.Context, id string) error
+	GetByProductID(c context.Context, productID string) ([]entity.Category, error)
+	SetProductCategories(c context.Context, productID string, categoryIDs []string) error
+}
+
+// ErrCategoryCycle dikembalikan ketika parent baru berasal dari sub-kategori sendiri
+var ErrCategoryCycle = errors.New("parent category cannot be a descendant of the category")
+
+type categoryRepository struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+func NewCategoryRepository(db *gorm.DB, redis *redis.Client) CategoryRepository {
+	return &categoryRepository{db, redis}
+}
+
+func (r *categoryRepository) GetAll(c context.Context) ([]entity.Category, error) {
+	categories := []entity.Category{}
+	categoriesKey := viper.GetString("CATEGORIES_KEY")
+
+	// Pohon kategori jarang berubah, seluruhnya disimpan di satu key
+	cachedData, err := cacheGet(c, r.redis, categoriesKey)
+	if err == nil {
+		err := json.Unmarshal([]byte(cachedData), &categories)
+		if err != nil {
+			return nil, err
+		}
+		return categories, nil
+	}
+
+	err = conn(c, r.db).Order("sort_order ASC, name ASC").Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, err := json.Marshal(categories)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cacheSet(c, r.redis, categoriesKey, jsonData, 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (r *categoryRepository) GetByID(c context.Context, id string) (entity.Category, error) {
+	var category entity.Category
+
+	err := conn(c, r.db).Where("id = ?", id).Limit(1).Find(&category).Error
+	if err != nil {
+		return category, err
+	}
+
+	return category, nil
+}
+
+func (r *categoryRepository) GetBySlug(c context.Context, slug string) (entity.Category, error) {
+	var category entity.Category
+
+	err := conn(c, r.db).Where("slug = ?", slug).Limit(1).Find(&category).Error
+	if err != nil {
+		return category, err
+	}
+
+	return category, nil
+}
+
+func (r *categoryRepository) GetByIDs(c context.Context, ids []string) ([]entity.Category, error) {
+	categories := []entity.Category{}
+	if len(ids) == 0 {
+		return categories, nil
+	}
+
+	err := conn(c, r.db).Where("id IN ?", ids).Order("sort_order ASC, name ASC").Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// GetDescendantIDs mengembalikan id seluruh sub-kategori, tidak termasuk id itu sendiri
+func (r *categoryRepository) GetDescendantIDs(c context.Context, id string) ([]string, error) {
+	return descendantCategoryIDs(conn(c, r.db), id)
+}
+
+// descendantCategoryIDs memakai UNION agar query tetap berhenti walaupun
+// data kategori terlanjur membentuk siklus
+func descendantCategoryIDs(db *gorm.DB, id string) ([]string, error) {
+	ids := []string{}
+
+	err := db.Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT id FROM categories WHERE parent_id = ?
+			UNION
+			SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id
+		)
+		SELECT id FROM tree`, id).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *categoryRepository) CountChildren(c context.Context, id string) (int64, error) {
+	var count int64
+
+	err := conn(c, r.db).Model(&entity.Category{}).Where("parent_id = ?", id).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *categoryRepository) Create(c context.Context, category entity.Category) (entity.Category, error) {
+	err := conn(c, r.db).Create(&category).Error
+	if err != nil {
+		return category, err
+	}
+
+	return category, r.invalidateCache(c)
+}
+
+// Update menyimpan kategori. Perpindahan parent dikunci dengan advisory lock
+// agar dua perpindahan bersamaan (A ke bawah B dan B ke bawah A) tidak bisa
+// sama-sama lolos pemeriksaan siklus.
+func (r *categoryRepository) Update(c context.Context, category entity.Category) (entity.Category, error) {
+	err := conn(c, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('categories'))").Error
+		if err != nil {
+			return err
+		}
+
+		if category.ParentID != nil {
+			descendants, err := descendantCategoryIDs(tx, category.ID)
+			if err != nil {
+				return err
+			}
+
+			for _, id := range descendants {
+				if id == *category.ParentID {
+					return ErrCategoryCycle
+				}
+			}
+		}
+
+		return tx.Model(&category).
+			Select("parent_id", "name", "slug", "sort_order", "updated_at").
+			Updates(&category).Error
+	})
+	if err != nil {
+		return category, err
+	}
+
+	return category, r.invalidateCache(c)
+}
+
+func (r *categoryRepository) Delete(c context.Context, id string) error {
+	// Penetapan produk ke kategori ikut terhapus lewat ON DELETE CASCADE
+	err := conn(c, r.db).Where("id = ?", id).Delete(&entity.Category{}).Error
+	if err != nil {
+		return err
+	}
+
+	return r.invalidateCache(c)
+}
+
+func (r *categoryRepository) GetByProductID(c context.Context, productID string) ([]entity.Category, error) {
+	categories := []entity.Category{}
+
+	err := conn(c, r.db).
+		Joins("JOIN product_categories ON product_categories.category_id = categories.id").
+		Where("product_categories.product_id = ?", productID).
+		Order("categories.sort_order ASC, categories.name ASC").
+		Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// SetProductCategories mengganti seluruh kategori sebuah produk
+func (r *categoryRepository) SetProductCategories(c context.Context, productID string, categoryIDs []string) error {
+	err := conn(c, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("product_id = ?", productID).Delete(&entity.ProductCategory{}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(categoryIDs) == 0 {
+			return nil
+		}
+
+		assignments := make([]entity.ProductCategory, 0, len(categoryIDs))
+		for _, categoryID := range categoryIDs {
+			assignments = append(assignments, entity.ProductCategory{ProductID: productID, CategoryID: categoryID})
+		}
+
+		return tx.Create(&assignments).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// Daftar produk yang difilter per kategori ikut berubah
+	return invalidateProductCache(c, r.redis)
+}
+
+// invalidateCache menghapus cache pohon kategori dan daftar produk, karena
+// filter kategori pada daftar produk bergantung pada struktur pohon
+func (r *categoryRepository) invalidateCache(c context.Context) error {
+	err := cacheDel(c, r.redis, viper.GetString("CATEGORIES_KEY"))
+	if err != nil {
+		return err
+	}
+
+	return invalidateProductCache(c, r.redis)
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"online-shop/model/entity"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type CustomerRepository interface {
+	Create(c context.Context, customer entity.Customer) (entity.Customer, error)
+	GetByID(c context.Context, id string) (entity.Customer, error)
+	GetByEmail(c context.Context, email string) (entity.Customer, error)
+	GetByVerificationToken(c context.Context, token string) (entity.Customer, error)
+	MarkVerified(c context.Context, customer entity.Customer) (entity.Customer, error)
+}
+
+type customerRepository struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+func NewCustomerRepository(db *gorm.DB, redis *redis.Client) CustomerRepository {
+	return &customerRepository{db, redis}
+}
+
+func (r *customerRepository) Create(c context.Context, customer entity.Customer) (entity.Customer, error) {
+	var count int64
+	err := conn(c, r.db).Model(&entity.Customer{}).Where("LOWER(email) = ?", strings.ToLower(customer.Email)).Count(&count).Error
+	if err != nil {
+		return customer, err
+	}
+
+	if count > 0 {
+		return customer, errors.New("email is already registered")
+	}
+
+	err = conn(c, r.db).Create(&customer).Error
+	if err != nil {
+		return customer, err
+	}
+
+	return customer, nil
+}
+
+func (r *customerRepository) GetByID(c context.Context, id string) (entity.Customer, error) {
+	var customer entity.Customer
+
+	err := conn(c, r.db).Where("id = ?", id).Limit(1).Find(&customer).Error
+	if err != nil {
+		return customer, err
+	}
+
+	return customer, nil
+}
+
+func (r *customerRepository) GetByEmail(c context.Context, email string) (entity.Customer, error) {
+	var customer entity.Customer
+
+	err := conn(c, r.db).Where("LOWER(email) = ?", strings.ToLower(email)).Limit(1).Find(&customer).Error
+	if err != nil {
+		return customer, err
+	}
+
+	return customer, nil
+}
+
+func (r *customerRepository) GetByVerificationToken(c context.Context, token string) (entity.Customer, error) {
+	var customer entity.Customer
+
+	err := conn(c, r.db).Where("verification_token = ?", token).Limit(1).Find(&customer).Error
+	if err != nil {
+		return customer, err
+	}
+
+	return customer, nil
+}
+
+func (r *customerRepository) MarkVerified(c context.Context, customer entity.Customer) (entity.Customer, error) {
+	err := conn(c, r.db).
+		Model(&customer).
+		Updates(map[string]interface{}{"verification_token": nil, "email_verified_at": customer.EmailVerifiedAt}).Error
+	if err != nil {
+		return customer, err
+	}
+
+	customer.VerificationToken = nil
+	return customer, nil
+}
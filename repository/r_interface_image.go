@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"online-shop/model/entity"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type ImageRepository interface {
+	GetByProductID(c context.Context, productID string) ([]entity.ProductImage, error)
+	Create(c context.Context, images []entity.ProductImage) ([]entity.ProductImage, error)
+	Delete(c context.Context, image entity.ProductImage) error
+	Reorder(c context.Context, productID string, imageIDs []string) error
+}
+
+type imageRepository struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+func NewImageRepository(db *gorm.DB, redis *redis.Client) ImageRepository {
+	return &imageRepository{db, redis}
+}
+
+func (r *imageRepository) GetByProductID(c context.Context, productID string) ([]entity.ProductImage, error) {
+	images := []entity.ProductImage{}
+
+	err := conn(c, r.db).
+		Where("product_id = ?", productID).
+		Order("position ASC, created_at ASC").
+		Find(&images).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+func (r *imageRepository) Create(c context.Context, images []entity.ProductImage) ([]entity.ProductImage, error) {
+	if len(images) == 0 {
+		return images, nil
+	}
+
+	err := conn(c, r.db).Create(&images).Error
+	if err != nil {
+		return images, err
+	}
+
+	// Gambar ikut tersimpan di cache produk
+	refreshProductCache(c, r.redis, images[0].ProductID)
+
+	return images, nil
+}
+
+func (r *imageRepository) Delete(c context.Context, image entity.ProductImage) error {
+	err := conn(c, r.db).Delete(&image).Error
+	if err != nil {
+		return err
+	}
+
+	refreshProductCache(c, r.redis, image.ProductID)
+
+	return nil
+}
+
+// Reorder menyimpan urutan gambar sesuai urutan imageIDs
+func (r *imageRepository) Reorder(c context.Context, productID string, imageIDs []string) error {
+	err := conn(c, r.db).Transaction(func(tx *gorm.DB) error {
+		for position, id := range imageIDs {
+			err := tx.Model(&entity.ProductImage{}).
+				Where("id = ? AND product_id = ?", id, productID).
+				Update("position", position).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	refreshProductCache(c, r.redis, productID)
+
+	return nil
+}
@@ -3,19 +3,42 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"online-shop/model/dto"
 	"online-shop/model/entity"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepository interface {
-	CreateOrder(order entity.Order, details []entity.OrderDetail) error
+	CreateOrder(c context.Context, order entity.Order, details []entity.OrderDetail, discounts []entity.OrderDiscount) error
 	GetByID(c context.Context, id string) (entity.Order, error)
 	GetDetailOrders(c context.Context, orderID string) ([]entity.OrderDetail, error)
+	GetDiscounts(c context.Context, orderID string) ([]entity.OrderDiscount, error)
 	Update(c context.Context, order entity.Order) (entity.Order, error)
+	UpdateStatus(c context.Context, order entity.Order, from string, history entity.OrderStatusHistory) (entity.Order, error)
+	UpdateStatusAndRestock(c context.Context, order entity.Order, from string, history entity.OrderStatusHistory) (entity.Order, error)
+	GetExpiredOrders(c context.Context, now time.Time, limit int) ([]entity.Order, error)
+	GetStatusHistory(c context.Context, orderID string) ([]entity.OrderStatusHistory, error)
+	CreateRefund(c context.Context, refund entity.Refund, refundedTotal entity.Money) (entity.Refund, error)
+	UpdateRefund(c context.Context, refund entity.Refund, restock []entity.OrderDetail) (entity.Refund, error)
+	GetRefunds(c context.Context, orderID string) ([]entity.Refund, error)
+	List(c context.Context, query dto.ReqOrderQuery) (dto.ResOrders, error)
 }
 
+// ErrOrderStatusConflict dikembalikan ketika status pesanan sudah diubah oleh proses lain
+var ErrOrderStatusConflict = errors.New("order status has been changed by another request")
+
+// ErrRefundConflict dikembalikan ketika refund lain dibuat bersamaan untuk pesanan yang sama
+var ErrRefundConflict = errors.New("another refund has been created for this order, please retry")
+
 type orderRepository struct {
 	db    *gorm.DB
 	redis *redis.Client
@@ -25,8 +48,23 @@ func NewOrderRepository(db *gorm.DB, redis *redis.Client) OrderRepository {
 	return &orderRepository{db, redis}
 }
 
-func (r *orderRepository) CreateOrder(order entity.Order, details []entity.OrderDetail) error {
-	tx := r.db.Begin()
+func (r *orderRepository) CreateOrder(c context.Context, order entity.Order, details []entity.OrderDetail, discounts []entity.OrderDiscount) error {
+	quantities, variantQuantities := detailQuantities(details)
+
+	tx := r.db.WithContext(c).Begin()
+
+	// Mengurangi stok di transaksi yang sama dengan pembuatan pesanan
+	errStock := reserveStock(tx, quantities, order.ID)
+	if errStock != nil {
+		tx.Rollback()
+		return errStock
+	}
+
+	errStock = reserveVariantStock(tx, variantQuantities, order.ID)
+	if errStock != nil {
+		tx.Rollback()
+		return errStock
+	}
 
 	errOrder := tx.Create(&order).Error
 	if errOrder != nil {
@@ -34,18 +72,53 @@ func (r *orderRepository) CreateOrder(order entity.Order, details []entity.Order
 		return errOrder
 	}
 
+	history := entity.OrderStatusHistory{
+		ID:        uuid.NewString(),
+		OrderID:   order.ID,
+		ToStatus:  order.Status,
+		Actor:     "customer",
+		CreatedAt: time.Now(),
+	}
+	errHistory := tx.Create(&history).Error
+	if errHistory != nil {
+		tx.Rollback()
+		return errHistory
+	}
+
 	errDetails := tx.Create(&details).Error
 	if errDetails != nil {
 		tx.Rollback()
 		return errDetails
 	}
 
+	// Pemakaian kode promo dicatat di transaksi yang sama agar batasnya terjaga
+	if len(discounts) > 0 {
+		errRedeem := redeemPromotion(tx, discounts[0].PromotionID, order)
+		if errRedeem != nil {
+			tx.Rollback()
+			return errRedeem
+		}
+
+		errDiscounts := tx.Create(&discounts).Error
+		if errDiscounts != nil {
+			tx.Rollback()
+			return errDiscounts
+		}
+	}
+
 	errCommit := tx.Commit().Error
 	if errCommit != nil {
 		tx.Rollback()
 		return errCommit
 	}
 
+	productIDs := make([]string, 0, len(details))
+	for _, detail := range details {
+		productIDs = append(productIDs, detail.ProductID)
+	}
+
+	refreshProductCache(c, r.redis, productIDs...)
+
 	return nil
 }
 
@@ -53,15 +126,15 @@ func (r *orderRepository) GetByID(c context.Context, id string) (entity.Order, e
 	var order entity.Order
 
 	// Cek di Redis
-	cachedOrder, err := r.redis.Get(c, id).Result()
+	cachedOrder, err := cacheGet(c, r.redis, orderKey(id))
 	if err == nil {
 		json.Unmarshal([]byte(cachedOrder), &order)
 		return order, nil
 	}
 
 	// Jika tidak ada di Redis, ambil dari database
-	rows, err := r.db.Model(&entity.Order{}).
-		Select("id", "email", "address", "passcode", "grand_total", "paid_at", "paid_bank", "paid_account").
+	rows, err := conn(c, r.db).Model(&entity.Order{}).
+		Select("id", "customer_id", "email", "address", "passcode", "currency", "subtotal", "discount_total", "promo_code", "shipping_address", "shipping_method_id", "shipping_method", "shipping_weight", "shipping_cost", "tax_total", "prices_include_tax", "grand_total", "status", "created_at", "expires_at", "paid_at", "paid_bank", "paid_account").
 		Where("id = ?", id).
 		Rows()
 	if err != nil {
@@ -74,6 +147,7 @@ func (r *orderRepository) GetByID(c context.Context, id string) (entity.Order, e
 		if err != nil {
 			return order, err
 		}
+		order.ApplyCurrency()
 	}
 
 	err = rows.Err()
@@ -88,7 +162,7 @@ func (r *orderRepository) GetByID(c context.Context, id string) (entity.Order, e
 	}
 
 	// Menyimpan hasil serialisasi ke Redis
-	err = r.redis.Set(c, id, orderJson, 0).Err()
+	err = cacheSet(c, r.redis, orderKey(id), orderJson, 0)
 	if err != nil {
 		return order, err
 	}
@@ -99,8 +173,17 @@ func (r *orderRepository) GetByID(c context.Context, id string) (entity.Order, e
 func (r *orderRepository) GetDetailOrders(c context.Context, orderID string) ([]entity.OrderDetail, error) {
 	var orderDetails []entity.OrderDetail
 
-	rows, err := r.db.Model(&entity.OrderDetail{}).
-		Select("id", "order_id", "product_id", "quantity", "price", "total").
+	cachedDetails, err := cacheGet(c, r.redis, orderDetailKey(orderID))
+	if err == nil {
+		err := json.Unmarshal([]byte(cachedDetails), &orderDetails)
+		if err != nil {
+			return nil, err
+		}
+		return orderDetails, nil
+	}
+
+	rows, err := conn(c, r.db).Model(&entity.OrderDetail{}).
+		Select("id", "order_id", "product_id", "variant_id", "sku", "options", "quantity", "currency", "price", "discount", "tax_class", "tax_rate", "tax", "total").
 		Where("order_id = ?", orderID).
 		Rows()
 	if err != nil {
@@ -113,6 +196,7 @@ func (r *orderRepository) GetDetailOrders(c context.Context, orderID string) ([]
 		if err := r.db.ScanRows(rows, &orderDetail); err != nil {
 			return orderDetails, err
 		}
+		orderDetail.ApplyCurrency()
 		orderDetails = append(orderDetails, orderDetail)
 	}
 
@@ -126,7 +210,7 @@ func (r *orderRepository) GetDetailOrders(c context.Context, orderID string) ([]
 		return nil, err
 	}
 
-	err = r.redis.Set(c, orderID, detailsJson, 0).Err()
+	err = cacheSet(c, r.redis, orderDetailKey(orderID), detailsJson, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -135,16 +219,318 @@ func (r *orderRepository) GetDetailOrders(c context.Context, orderID string) ([]
 }
 
 func (r *orderRepository) Update(c context.Context, order entity.Order) (entity.Order, error) {
-	err := r.db.Updates(&order).Error
+	err := conn(c, r.db).Updates(&order).Error
 	if err != nil {
 		return order, err
 	}
 
 	// Hapus kunci-kunci tersebut dari Redis
-	err = r.redis.Del(c, order.ID).Err()
+	err = cacheDel(c, r.redis, orderKey(order.ID))
+	if err != nil {
+		return order, err
+	}
+
+	return order, nil
+}
+
+func (r *orderRepository) UpdateStatus(c context.Context, order entity.Order, from string, history entity.OrderStatusHistory) (entity.Order, error) {
+	err := conn(c, r.db).Transaction(func(tx *gorm.DB) error {
+		// Update hanya berhasil jika status di database masih sama dengan from
+		result := tx.Model(&order).Where("status = ?", from).Updates(&order)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrOrderStatusConflict
+		}
+
+		return tx.Create(&history).Error
+	})
+	if err != nil {
+		return order, err
+	}
+
+	err = cacheDel(c, r.redis, orderKey(order.ID))
+	if err != nil {
+		return order, err
+	}
+
+	return order, nil
+}
+
+func (r *orderRepository) UpdateStatusAndRestock(c context.Context, order entity.Order, from string, history entity.OrderStatusHistory) (entity.Order, error) {
+	var details []entity.OrderDetail
+
+	err := conn(c, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&order).Where("status = ?", from).Updates(&order)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrOrderStatusConflict
+		}
+
+		err := tx.Where("order_id = ?", order.ID).Find(&details).Error
+		if err != nil {
+			return err
+		}
+
+		quantities, variantQuantities := detailQuantities(details)
+
+		// Stok yang sebelumnya dipesan dikembalikan
+		err = releaseStock(tx, quantities, "order_"+history.ToStatus, order.ID)
+		if err != nil {
+			return err
+		}
+
+		err = releaseVariantStock(tx, variantQuantities, "order_"+history.ToStatus, order.ID)
+		if err != nil {
+			return err
+		}
+
+		// Kode promo dari pesanan yang batal bisa dipakai kembali
+		err = tx.Where("order_id = ?", order.ID).Delete(&entity.PromotionRedemption{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&history).Error
+	})
 	if err != nil {
 		return order, err
 	}
 
+	err = cacheDel(c, r.redis, orderKey(order.ID), orderDetailKey(order.ID))
+	if err != nil {
+		return order, err
+	}
+
+	productIDs := make([]string, 0, len(details))
+	for _, detail := range details {
+		productIDs = append(productIDs, detail.ProductID)
+	}
+
+	refreshProductCache(c, r.redis, productIDs...)
+
 	return order, nil
 }
+
+func (r *orderRepository) GetExpiredOrders(c context.Context, now time.Time, limit int) ([]entity.Order, error) {
+	orders := []entity.Order{}
+
+	err := conn(c, r.db).
+		Select("id", "email", "address", "currency", "grand_total", "status", "created_at", "expires_at").
+		Where("status = ? AND expires_at <= ?", entity.OrderStatusPending, now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range orders {
+		orders[i].ApplyCurrency()
+	}
+
+	return orders, nil
+}
+
+func (r *orderRepository) GetStatusHistory(c context.Context, orderID string) ([]entity.OrderStatusHistory, error) {
+	histories := []entity.OrderStatusHistory{}
+
+	err := conn(c, r.db).
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").
+		Find(&histories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
+
+// CreateRefund menyimpan refund berstatus pending. Baris pesanan dikunci,
+// statusnya harus masih bisa direfund, dan total refund yang sudah ada harus
+// sama dengan refundedTotal yang dipakai saat validasi, sehingga dua refund
+// bersamaan tidak bisa melebihi pembayaran.
+func (r *orderRepository) CreateRefund(c context.Context, refund entity.Refund, refundedTotal entity.Money) (entity.Refund, error) {
+	err := conn(c, r.db).Transaction(func(tx *gorm.DB) error {
+		var order entity.Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "status").
+			Where("id = ?", refund.OrderID).
+			Take(&order).Error
+		if err != nil {
+			return err
+		}
+
+		// Status bisa berubah sejak diperiksa oleh usecase
+		if !entity.CanTransition(order.Status, entity.OrderStatusRefunded) {
+			return ErrOrderStatusConflict
+		}
+
+		var current int64
+		err = tx.Model(&entity.Refund{}).
+			Select("COALESCE(SUM(amount), 0)").
+			Where("order_id = ? AND status <> ?", refund.OrderID, entity.RefundStatusFailed).
+			Scan(&current).Error
+		if err != nil {
+			return err
+		}
+
+		if current != refundedTotal.Amount {
+			return ErrRefundConflict
+		}
+
+		return tx.Create(&refund).Error
+	})
+	if err != nil {
+		return refund, err
+	}
+
+	return refund, nil
+}
+
+// UpdateRefund menyimpan hasil refund dari provider dan mengembalikan stok
+// baris pesanan pada restock jika refund berhasil. Quantity pada restock
+// adalah jumlah yang dikembalikan.
+func (r *orderRepository) UpdateRefund(c context.Context, refund entity.Refund, restock []entity.OrderDetail) (entity.Refund, error) {
+	err := conn(c, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&refund).
+			Omit(clause.Associations).
+			Select("status", "provider_refund_id", "updated_at").
+			Updates(&refund).Error
+		if err != nil {
+			return err
+		}
+
+		if refund.Status != entity.RefundStatusSucceeded || len(restock) == 0 {
+			return nil
+		}
+
+		quantities, variantQuantities := detailQuantities(restock)
+		err = releaseStock(tx, quantities, "refund", refund.OrderID)
+		if err != nil {
+			return err
+		}
+
+		return releaseVariantStock(tx, variantQuantities, "refund", refund.OrderID)
+	})
+	if err != nil {
+		return refund, err
+	}
+
+	if refund.Status == entity.RefundStatusSucceeded && len(restock) > 0 {
+		productIDs := make([]string, 0, len(restock))
+		for _, detail := range restock {
+			productIDs = append(productIDs, detail.ProductID)
+		}
+
+		refreshProductCache(c, r.redis, productIDs...)
+	}
+
+	return refund, nil
+}
+
+func (r *orderRepository) GetRefunds(c context.Context, orderID string) ([]entity.Refund, error) {
+	refunds := []entity.Refund{}
+
+	err := conn(c, r.db).
+		Preload("Lines").
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").
+		Find(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range refunds {
+		refunds[i].ApplyCurrency()
+	}
+
+	return refunds, nil
+}
+
+func (r *orderRepository) List(c context.Context, query dto.ReqOrderQuery) (dto.ResOrders, error) {
+	result := dto.ResOrders{Page: query.Page, Limit: query.Limit}
+
+	tx := conn(c, r.db).Model(&entity.Order{})
+	if query.CustomerID != "" {
+		tx = tx.Where("customer_id = ?", query.CustomerID)
+	}
+	if query.Status != "" {
+		tx = tx.Where("status = ?", query.Status)
+	}
+	if query.Email != "" {
+		tx = tx.Where("LOWER(email) = ?", strings.ToLower(query.Email))
+	}
+	if query.DateFrom != nil {
+		tx = tx.Where("created_at >= ?", *query.DateFrom)
+	}
+	if query.DateTo != nil {
+		// date_to inklusif sampai akhir hari
+		tx = tx.Where("created_at < ?", query.DateTo.AddDate(0, 0, 1))
+	}
+	if query.Currency != "" {
+		tx = tx.Where("currency = ?", query.Currency)
+	}
+	if query.MinTotal != nil {
+		tx = tx.Where("grand_total >= ?", *query.MinTotal)
+	}
+	if query.MaxTotal != nil {
+		tx = tx.Where("grand_total <= ?", *query.MaxTotal)
+	}
+	tx = tx.Session(&gorm.Session{})
+
+	err := tx.Count(&result.Total).Error
+	if err != nil {
+		return result, err
+	}
+
+	result.Data = []entity.OrderSummary{}
+	err = tx.Select("id, customer_id, email, address, currency, subtotal, discount_total, promo_code, shipping_method_id, shipping_method, shipping_cost, tax_total, prices_include_tax, grand_total, status, created_at, expires_at, paid_at, paid_bank, paid_account, " +
+		"(SELECT COUNT(*) FROM order_details WHERE order_details.order_id = orders.id) AS line_count").
+		Order(fmt.Sprintf("%s %s, id ASC", query.SortBy, strings.ToUpper(query.Order))).
+		Limit(query.Limit).
+		Offset((query.Page - 1) * query.Limit).
+		Scan(&result.Data).Error
+	if err != nil {
+		return result, err
+	}
+
+	for i := range result.Data {
+		result.Data[i].ApplyCurrency()
+	}
+
+	result.TotalPages = int((result.Total + int64(query.Limit) - 1) / int64(query.Limit))
+
+	return result, nil
+}
+
+func (r *orderRepository) GetDiscounts(c context.Context, orderID string) ([]entity.OrderDiscount, error) {
+	discounts := []entity.OrderDiscount{}
+
+	err := conn(c, r.db).
+		Where("order_id = ?", orderID).
+		Order("created_at ASC, id ASC").
+		Find(&discounts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range discounts {
+		discounts[i].ApplyCurrency()
+	}
+
+	return discounts, nil
+}
+
+func orderKey(id string) string {
+	return viper.GetString("ORDER_ID_KEY") + id
+}
+
+func orderDetailKey(id string) string {
+	return viper.GetString("ORDER_DETAIL_KEY") + id
+}
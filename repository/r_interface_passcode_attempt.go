@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+// PasscodeAttemptRepository menghitung percobaan passcode dan menyimpan
+// status kunci di Redis. scope berupa "order:<id>" atau "ip:<ip>".
+type PasscodeAttemptRepository interface {
+	Attempt(c context.Context, scope string, limit int64, ttl time.Duration, lock time.Duration) (PasscodeAttempt, error)
+	Release(c context.Context, scope string, unlock bool) error
+	Lock(c context.Context, scope string, duration time.Duration) error
+	Reset(c context.Context, scope string) error
+}
+
+// PasscodeAttempt adalah hasil pencatatan satu percobaan passcode. RetryAfter
+// terisi jika scope sedang dikunci dan percobaan tidak dicatat. Locked
+// bernilai true jika percobaan ini mencapai batas dan mengunci scope.
+type PasscodeAttempt struct {
+	Count      int64
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+// passcodeAttemptScript mencatat percobaan sebelum passcode diperiksa.
+// Percobaan yang mencapai batas langsung mengunci scope sehingga request
+// paralel berikutnya tertolak walaupun passcode belum selesai dibandingkan.
+var passcodeAttemptScript = redis.NewScript(`
+local lockTTL = redis.call('PTTL', KEYS[2])
+if lockTTL > 0 then
+	return {0, lockTTL, 0}
+end
+
+local count = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+
+local limit = tonumber(ARGV[2])
+if limit > 0 and count >= limit then
+	redis.call('SET', KEYS[2], 1, 'PX', ARGV[3])
+	return {count, 0, 1}
+end
+return {count, 0, 0}
+`)
+
+// passcodeReleaseScript membatalkan satu percobaan tanpa membuat counter negatif
+var passcodeReleaseScript = redis.NewScript(`
+local count = tonumber(redis.call('GET', KEYS[1]) or '0')
+if count > 0 then
+	redis.call('DECR', KEYS[1])
+end
+if ARGV[1] == '1' then
+	redis.call('DEL', KEYS[2])
+end
+return 0
+`)
+
+type passcodeAttemptRepository struct {
+	redis *redis.Client
+}
+
+func NewPasscodeAttemptRepository(redis *redis.Client) PasscodeAttemptRepository {
+	return &passcodeAttemptRepository{redis}
+}
+
+// Attempt mencatat satu percobaan secara atomik. Jika jumlah percobaan
+// mencapai limit, scope dikunci selama lock.
+func (r *passcodeAttemptRepository) Attempt(c context.Context, scope string, limit int64, ttl time.Duration, lock time.Duration) (PasscodeAttempt, error) {
+	keys := []string{passcodeAttemptKey(scope), passcodeLockKey(scope)}
+	values, err := passcodeAttemptScript.Run(c, r.redis, keys, ttl.Milliseconds(), limit, lock.Milliseconds()).Int64Slice()
+	if err != nil {
+		return PasscodeAttempt{}, err
+	}
+
+	return PasscodeAttempt{
+		Count:      values[0],
+		RetryAfter: time.Duration(values[1]) * time.Millisecond,
+		Locked:     values[2] == 1,
+	}, nil
+}
+
+// Release membatalkan percobaan yang tidak perlu dihitung, misalnya karena
+// passcode-nya benar. Kunci yang dipasang oleh percobaan tersebut ikut dilepas
+// jika unlock bernilai true.
+func (r *passcodeAttemptRepository) Release(c context.Context, scope string, unlock bool) error {
+	flag := "0"
+	if unlock {
+		flag = "1"
+	}
+
+	keys := []string{passcodeAttemptKey(scope), passcodeLockKey(scope)}
+	return passcodeReleaseScript.Run(c, r.redis, keys, flag).Err()
+}
+
+func (r *passcodeAttemptRepository) Lock(c context.Context, scope string, duration time.Duration) error {
+	return r.redis.Set(c, passcodeLockKey(scope), 1, duration).Err()
+}
+
+func (r *passcodeAttemptRepository) Reset(c context.Context, scope string) error {
+	return r.redis.Del(c, passcodeAttemptKey(scope), passcodeLockKey(scope)).Err()
+}
+
+func passcodeAttemptKey(scope string) string {
+	return viper.GetString("PASSCODE_ATTEMPT_KEY") + scope
+}
+
+func passcodeLockKey(scope string) string {
+	return viper.GetString("PASSCODE_ATTEMPT_KEY") + "lock_" + scope
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"online-shop/model/entity"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRepository interface {
+	Create(c context.Context, payment entity.Payment) (entity.Payment, error)
+	GetByIntentID(c context.Context, provider string, intentID string) (entity.Payment, error)
+	GetLatestByOrderID(c context.Context, orderID string) (entity.Payment, error)
+	UpdateStatus(c context.Context, payment entity.Payment, from string) (entity.Payment, error)
+	HasEvent(c context.Context, id string) (bool, error)
+	RecordEvent(c context.Context, event entity.PaymentEvent) error
+}
+
+// ErrPaymentStatusConflict dikembalikan ketika status pembayaran sudah diubah oleh proses lain
+var ErrPaymentStatusConflict = errors.New("payment status has been changed by another request")
+
+type paymentRepository struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+func NewPaymentRepository(db *gorm.DB, redis *redis.Client) PaymentRepository {
+	return &paymentRepository{db, redis}
+}
+
+func (r *paymentRepository) Create(c context.Context, payment entity.Payment) (entity.Payment, error) {
+	err := conn(c, r.db).Create(&payment).Error
+	if err != nil {
+		return payment, err
+	}
+
+	return payment, nil
+}
+
+func (r *paymentRepository) GetByIntentID(c context.Context, provider string, intentID string) (entity.Payment, error) {
+	var payment entity.Payment
+
+	err := conn(c, r.db).
+		Where("provider = ? AND intent_id = ?", provider, intentID).
+		Take(&payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return payment, errors.New("payment not found")
+	}
+	if err != nil {
+		return payment, err
+	}
+
+	payment.ApplyCurrency()
+	return payment, nil
+}
+
+func (r *paymentRepository) GetLatestByOrderID(c context.Context, orderID string) (entity.Payment, error) {
+	var payment entity.Payment
+
+	err := conn(c, r.db).
+		Where("order_id = ?", orderID).
+		Order("created_at DESC").
+		Take(&payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return payment, nil
+	}
+	if err != nil {
+		return payment, err
+	}
+
+	payment.ApplyCurrency()
+	return payment, nil
+}
+
+func (r *paymentRepository) UpdateStatus(c context.Context, payment entity.Payment, from string) (entity.Payment, error) {
+	result := conn(c, r.db).
+		Model(&payment).
+		Where("status = ?", from).
+		Updates(map[string]interface{}{"status": payment.Status, "updated_at": payment.UpdatedAt})
+	if result.Error != nil {
+		return payment, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return payment, ErrPaymentStatusConflict
+	}
+
+	return payment, nil
+}
+
+// HasEvent melaporkan apakah event callback sudah pernah selesai diproses
+func (r *paymentRepository) HasEvent(c context.Context, id string) (bool, error) {
+	var count int64
+
+	err := conn(c, r.db).Model(&entity.PaymentEvent{}).Where("id = ?", id).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// RecordEvent menandai event callback sudah diproses. Event yang sama bisa
+// dicatat oleh dua callback yang berjalan bersamaan sehingga duplikat diabaikan.
+func (r *paymentRepository) RecordEvent(c context.Context, event entity.PaymentEvent) error {
+	return conn(c, r.db).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&event).Error
+}
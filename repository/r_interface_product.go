@@ -2,21 +2,55 @@ package repository
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"online-shop/model/dto"
 	"online-shop/model/entity"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
-	GetAll(c context.Context) ([]entity.Product, error)
+	GetAll(c context.Context, query dto.ReqProductQuery) (dto.ResProducts, error)
+	Search(c context.Context, query dto.ReqProductSearch, tsQuery string) (dto.ResProductSearch, error)
 	GetByID(c context.Context, id string) (entity.Product, error)
+	GetByIDs(c context.Context, ids []string) ([]entity.Product, error)
 	Create(c context.Context, product entity.Product) (entity.Product, error)
 	Update(c context.Context, product entity.Product) (entity.Product, error)
 	Delete(c context.Context, product entity.Product) (entity.Product, error)
+	AdjustStock(c context.Context, movement entity.StockMovement) (entity.Product, error)
+	GetStockMovements(c context.Context, productID string) ([]entity.StockMovement, error)
+	GetForImport(c context.Context, ids []string, skus []string) ([]entity.Product, error)
+	Import(c context.Context, plan entity.ProductImport) error
+	Export(c context.Context, fn func(product entity.Product) error) error
+	GetPrices(c context.Context, productID string) ([]entity.ProductPrice, error)
+	GetPricesByCurrency(c context.Context, productIDs []string, currency string) ([]entity.ProductPrice, error)
+	SetPrices(c context.Context, productID string, prices []entity.ProductPrice) error
+}
+
+// OutOfStockError dikembalikan ketika stok satu atau lebih produk atau varian tidak mencukupi
+type OutOfStockError struct {
+	ProductIDs []string
+	VariantIDs []string
+}
+
+func (e *OutOfStockError) Error() string {
+	if len(e.VariantIDs) > 0 {
+		return "insufficient stock for variant(s): " + strings.Join(e.VariantIDs, ", ")
+	}
+	return "insufficient stock for product(s): " + strings.Join(e.ProductIDs, ", ")
 }
 
 type repository struct {
@@ -28,62 +62,201 @@ func NewRepository(db *gorm.DB, redis *redis.Client) Repository {
 	return &repository{db, redis}
 }
 
-func (r *repository) GetAll(c context.Context) ([]entity.Product, error) {
-	var products []entity.Product
-	productKey := viper.GetString("PRODUCTS_KEY")
+// productColumns adalah kolom produk yang ditampilkan, termasuk gambar
+// produk yang sudah terurut dan daftar harga produk dalam bentuk JSON
+const productColumns = "id, sku, name, price, stock, weight, tax_class, created_at, " +
+	"(SELECT COALESCE(json_agg(json_build_object('id', product_images.id, 'url', product_images.url, 'thumbnailUrl', product_images.thumbnail_url) " +
+	"ORDER BY product_images.position, product_images.created_at), '[]') " +
+	"FROM product_images WHERE product_images.product_id = products.id) AS images, " +
+	"(SELECT COALESCE(json_agg(json_build_object('amount', product_prices.price, 'currency', product_prices.currency) " +
+	"ORDER BY product_prices.currency), '[]') " +
+	"FROM product_prices WHERE product_prices.product_id = products.id AND product_prices.variant_id IS NULL) AS prices"
+
+// Kolom yang boleh dipakai untuk sorting, dipetakan dari parameter query
+var productSortColumns = map[string]string{
+	"price":      "price",
+	"name":       "name",
+	"created_at": "created_at",
+}
+
+func (r *repository) GetAll(c context.Context, query dto.ReqProductQuery) (dto.ResProducts, error) {
+	result := dto.ResProducts{Page: query.Page, Limit: query.Limit}
+
+	listKey, err := r.productListKey(c, query)
+	if err != nil {
+		return result, err
+	}
 
 	// Mencoba untuk mendapatkan data dari cache Redis
-	cachedData, err := r.redis.Get(c, productKey).Result()
+	cachedData, err := cacheGet(c, r.redis, listKey)
 	if err == nil {
-		// Jika data ditemukan di cache, kita bisa langsung mengembalikannya
-		err := json.Unmarshal([]byte(cachedData), &products)
+		err := json.Unmarshal([]byte(cachedData), &result)
 		if err != nil {
-			return nil, err
+			return result, err
 		}
-		return products, nil
+		return result, nil
 	}
 
 	// Jika data tidak ada di cache, kita harus mengambilnya dari database
-	rows, err := r.db.Model(&products).Select("id, name, price").Where("is_deleted = ?", false).Rows()
+	tx := conn(c, r.db).Model(&entity.Product{}).Where("is_deleted = ?", false)
+	if query.Name != "" {
+		tx = tx.Where("name ILIKE ?", "%"+escapeLike(query.Name)+"%")
+	}
+	if query.Category != "" {
+		// Produk di kategori tersebut dan seluruh sub-kategorinya
+		tx = tx.Where(`id IN (
+			SELECT product_id FROM product_categories WHERE category_id IN (
+				WITH RECURSIVE tree AS (
+					SELECT id FROM categories WHERE slug = ?
+					UNION
+					SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id
+				)
+				SELECT id FROM tree
+			)
+		)`, query.Category)
+	}
+	if query.MinPrice != nil {
+		tx = tx.Where("price >= ?", *query.MinPrice)
+	}
+	if query.MaxPrice != nil {
+		tx = tx.Where("price <= ?", *query.MaxPrice)
+	}
+	tx = tx.Session(&gorm.Session{})
+
+	err = tx.Count(&result.Total).Error
 	if err != nil {
-		return nil, err
+		return result, err
+	}
+
+	order := fmt.Sprintf("%s %s, id ASC", productSortColumns[query.SortBy], strings.ToUpper(query.Order))
+	rows, err := tx.Select(productColumns).
+		Order(order).
+		Limit(query.Limit).
+		Offset((query.Page - 1) * query.Limit).
+		Rows()
+	if err != nil {
+		return result, err
 	}
 	defer rows.Close()
 
+	result.Data = []entity.Product{}
 	for rows.Next() {
 		var product entity.Product
 		err := r.db.ScanRows(rows, &product)
 		if err != nil {
-			return nil, err
+			return result, err
 		}
-		products = append(products, product)
+		result.Data = append(result.Data, product)
 	}
 
 	err = rows.Err()
 	if err != nil {
-		return nil, err
+		return result, err
 	}
 
+	result.TotalPages = int((result.Total + int64(query.Limit) - 1) / int64(query.Limit))
+
 	// Menyimpan hasil query ke dalam cache Redis
-	jsonData, err := json.Marshal(products)
+	jsonData, err := json.Marshal(result)
 	if err != nil {
-		return nil, err
+		return result, err
 	}
 
-	// Simpan data JSON ke Redis
-	err = r.redis.Set(c, productKey, jsonData, 24*time.Hour).Err()
+	err = cacheSet(c, r.redis, listKey, jsonData, 24*time.Hour)
 	if err != nil {
-		return nil, err
+		return result, err
 	}
 
-	return products, nil
+	return result, nil
+}
+
+// Search mencari produk dengan full-text search (tsQuery berisi prefix query
+// yang sudah disusun usecase) dan kemiripan trigram untuk salah ketik
+func (r *repository) Search(c context.Context, query dto.ReqProductSearch, tsQuery string) (dto.ResProductSearch, error) {
+	result := dto.ResProductSearch{Page: query.Page, Limit: query.Limit}
+
+	searchKey, err := r.productSearchKey(c, query)
+	if err != nil {
+		return result, err
+	}
+
+	cachedData, err := cacheGet(c, r.redis, searchKey)
+	if err == nil {
+		err := json.Unmarshal([]byte(cachedData), &result)
+		if err != nil {
+			return result, err
+		}
+		return result, nil
+	}
+
+	err = conn(c, r.db).Transaction(func(tx *gorm.DB) error {
+		// Ambang kemiripan operator <% hanya berlaku di transaksi ini
+		err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)",
+			viper.GetString("PRODUCT_SEARCH_SIMILARITY")).Error
+		if err != nil {
+			return err
+		}
+
+		search := tx.Model(&entity.Product{}).
+			Where("is_deleted = ?", false).
+			Where("(search_vector @@ to_tsquery('simple', ?) OR ? <% name)", tsQuery, query.Query).
+			Session(&gorm.Session{})
+
+		err = search.Count(&result.Total).Error
+		if err != nil {
+			return err
+		}
+
+		result.Data = []entity.ProductSearchResult{}
+		rows, err := search.
+			Select(productColumns+", "+
+				"ts_rank(search_vector, to_tsquery('simple', ?)) + word_similarity(?, name) AS rank, "+
+				"ts_headline('simple', name, to_tsquery('simple', ?), 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS highlight",
+				tsQuery, query.Query, tsQuery).
+			Order("rank DESC, id ASC").
+			Limit(query.Limit).
+			Offset((query.Page - 1) * query.Limit).
+			Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var product entity.ProductSearchResult
+			err := tx.ScanRows(rows, &product)
+			if err != nil {
+				return err
+			}
+			result.Data = append(result.Data, product)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.TotalPages = int((result.Total + int64(query.Limit) - 1) / int64(query.Limit))
+
+	jsonData, err := json.Marshal(result)
+	if err != nil {
+		return result, err
+	}
+
+	err = cacheSet(c, r.redis, searchKey, jsonData, time.Hour)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
 }
 
 func (r *repository) GetByID(c context.Context, id string) (entity.Product, error) {
 	var product entity.Product
 	productIdKey := viper.GetString("PRODUCT_ID_KEY") + id
 
-	cachedData, err := r.redis.Get(c, productIdKey).Result()
+	cachedData, err := cacheGet(c, r.redis, productIdKey)
 	if err == nil {
 		err := json.Unmarshal([]byte(cachedData), &product)
 		if err != nil {
@@ -92,7 +265,7 @@ func (r *repository) GetByID(c context.Context, id string) (entity.Product, erro
 		return product, nil
 	}
 
-	rows, err := r.db.Model(&product).Select("id, name, price").Where("is_deleted = ? AND id = ?", false, id).Rows()
+	rows, err := conn(c, r.db).Model(&product).Select(productColumns).Where("is_deleted = ? AND id = ?", false, id).Rows()
 	if err != nil {
 		return product, err
 	}
@@ -115,7 +288,7 @@ func (r *repository) GetByID(c context.Context, id string) (entity.Product, erro
 		return product, err
 	}
 
-	err = r.redis.Set(c, productIdKey, jsonData, 24*time.Hour).Err()
+	err = cacheSet(c, r.redis, productIdKey, jsonData, 24*time.Hour)
 	if err != nil {
 		return product, err
 	}
@@ -123,160 +296,646 @@ func (r *repository) GetByID(c context.Context, id string) (entity.Product, erro
 	return product, nil
 }
 
-func (r *repository) Create(c context.Context, product entity.Product) (entity.Product, error) {
-	productKey := viper.GetString("PRODUCTS_KEY")
-	productIdKey := viper.GetString("PRODUCT_ID_KEY") + product.ID
+func (r *repository) GetByIDs(c context.Context, ids []string) ([]entity.Product, error) {
+	var products []entity.Product
 
-	// Mendapatkan data cache saat ini
-	cachedData, err := r.redis.Get(c, productKey).Result()
-	if err != nil && err != redis.Nil {
-		return product, err
+	if len(ids) == 0 {
+		return products, nil
 	}
 
-	// Inisialisasi objek untuk menyimpan produk
-	var products []entity.Product
+	rows, err := conn(c, r.db).Model(&entity.Product{}).Select("id, sku, name, price, stock, weight, tax_class, created_at").Where("is_deleted = ? AND id IN ?", false, ids).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	// Jika data ada di cache, kita mengurai JSON
-	if cachedData != "" {
-		err := json.Unmarshal([]byte(cachedData), &products)
+	for rows.Next() {
+		var product entity.Product
+		err := r.db.ScanRows(rows, &product)
 		if err != nil {
-			return product, err
+			return nil, err
 		}
+		products = append(products, product)
 	}
 
-	// Menambahkan produk baru ke data cache
-	products = append(products, product)
-
-	// Mengupdate cache dengan daftar produk yang baru
-	jsonData, err := json.Marshal(products)
+	err = rows.Err()
 	if err != nil {
-		return product, err
+		return nil, err
 	}
 
-	// Mengupdate cache dengan daftar produk yang baru
-	err = r.redis.Set(c, productKey, jsonData, 24*time.Hour).Err()
+	return products, nil
+}
+
+func (r *repository) Create(c context.Context, product entity.Product) (entity.Product, error) {
+	// Membuat produk di database
+	err := conn(c, r.db).Create(&product).Error
 	if err != nil {
 		return product, err
 	}
 
-	// Menyimpan produk sebagai objek tunggal di Redis untuk akses cepat berdasarkan id
-	jsonProduct, err := json.Marshal(product)
+	// Semua cache daftar produk menjadi tidak valid, cache produk ini akan
+	// terisi saat pertama kali dibaca
+	refreshProductCache(c, r.redis, product.ID)
+
+	return product, nil
+}
+
+func (r *repository) Update(c context.Context, product entity.Product) (entity.Product, error) {
+	// Mengupdate produk di database, stok hanya boleh berubah lewat AdjustStock
+	err := conn(c, r.db).Model(&product).Select("sku", "name", "price", "weight", "tax_class").Updates(&product).Error
 	if err != nil {
 		return product, err
 	}
 
-	// Menyimpan produk sebagai objek tunggal di Redis untuk akses cepat berdasarkan id
-	err = r.redis.Set(c, productIdKey, jsonProduct, 24*time.Hour).Err()
+	// Cache produk dihapus agar dibaca ulang dari database beserta stok terbaru
+	refreshProductCache(c, r.redis, product.ID)
+
+	return product, nil
+}
+
+func (r *repository) Delete(c context.Context, product entity.Product) (entity.Product, error) {
+	// Menghapus produk di database
+	err := conn(c, r.db).Model(&product).Select("is_deleted").Updates(&product).Error
 	if err != nil {
 		return product, err
 	}
 
-	// Membuat produk di database
-	err = r.db.Create(&product).Error
+	// Menghapus produk dari cache produk
+	refreshProductCache(c, r.redis, product.ID)
+
+	return product, nil
+}
+
+func (r *repository) AdjustStock(c context.Context, movement entity.StockMovement) (entity.Product, error) {
+	var product entity.Product
+
+	err := conn(c, r.db).Transaction(func(tx *gorm.DB) error {
+		// Mengunci baris produk agar tidak bentrok dengan checkout yang berjalan
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id, name, price, stock, created_at").
+			Where("is_deleted = ? AND id = ?", false, movement.ProductID).
+			Take(&product).Error
+		if err != nil {
+			return err
+		}
+
+		if product.Stock+movement.Change < 0 {
+			return &OutOfStockError{ProductIDs: []string{product.ID}}
+		}
+
+		err = tx.Model(&entity.Product{}).
+			Where("id = ?", product.ID).
+			Update("stock", gorm.Expr("stock + ?", movement.Change)).Error
+		if err != nil {
+			return err
+		}
+
+		product.Stock += movement.Change
+		return tx.Create(&movement).Error
+	})
 	if err != nil {
 		return product, err
 	}
 
-	return product, nil
+	refreshProductCache(c, r.redis, product.ID)
+
+	// Dibaca ulang agar data yang dikembalikan lengkap beserta gambarnya
+	return r.GetByID(c, product.ID)
 }
 
-func (r *repository) Update(c context.Context, product entity.Product) (entity.Product, error) {
-	productKey := viper.GetString("PRODUCTS_KEY")
-	productIdKey := viper.GetString("PRODUCT_ID_KEY") + product.ID
+func (r *repository) GetStockMovements(c context.Context, productID string) ([]entity.StockMovement, error) {
+	movements := []entity.StockMovement{}
 
-	// Mengupdate cache produk
-	jsonData, err := json.Marshal(product)
+	err := conn(c, r.db).
+		Where("product_id = ?", productID).
+		Order("created_at DESC").
+		Find(&movements).Error
 	if err != nil {
-		return product, err
+		return nil, err
 	}
 
-	err = r.redis.Set(c, productIdKey, jsonData, 24*time.Hour).Err()
+	return movements, nil
+}
+
+// GetForImport mengambil produk berdasarkan id (termasuk yang sudah dihapus)
+// atau sku (hanya produk aktif) untuk mencocokkan baris import
+func (r *repository) GetForImport(c context.Context, ids []string, skus []string) ([]entity.Product, error) {
+	products := []entity.Product{}
+
+	if len(ids) == 0 && len(skus) == 0 {
+		return products, nil
+	}
+
+	tx := conn(c, r.db).Select("id, sku, name, price, stock, is_deleted")
+	switch {
+	case len(ids) > 0 && len(skus) > 0:
+		tx = tx.Where("id IN ? OR (sku IN ? AND is_deleted = ?)", ids, skus, false)
+	case len(ids) > 0:
+		tx = tx.Where("id IN ?", ids)
+	default:
+		tx = tx.Where("sku IN ? AND is_deleted = ?", skus, false)
+	}
+
+	err := tx.Find(&products).Error
 	if err != nil {
-		return product, err
+		return nil, err
 	}
 
-	// Mendapatkan data cache saat ini
-	var products []entity.Product
-	cachedData, err := r.redis.Get(c, productKey).Result()
-	if err == nil {
-		err := json.Unmarshal([]byte(cachedData), &products)
+	return products, nil
+}
+
+// Import menerapkan seluruh baris import dalam satu transaksi. Perubahan stok
+// dicatat sebagai stock movement terhadap stok yang sudah dikunci, dan cache
+// produk hanya di-invalidate sekali setelah transaksi selesai.
+func (r *repository) Import(c context.Context, plan entity.ProductImport) error {
+	err := conn(c, r.db).Transaction(func(tx *gorm.DB) error {
+		if len(plan.Creates) > 0 {
+			err := tx.CreateInBatches(&plan.Creates, 100).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(plan.Updates) == 0 {
+			return nil
+		}
+
+		ids := make([]string, 0, len(plan.Updates))
+		for _, product := range plan.Updates {
+			ids = append(ids, product.ID)
+		}
+
+		var locked []entity.Product
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id, stock").
+			Where("is_deleted = ? AND id IN ?", false, ids).
+			Order("id").
+			Find(&locked).Error
 		if err != nil {
-			return product, err
+			return err
+		}
+
+		stocks := make(map[string]int64, len(locked))
+		for _, product := range locked {
+			stocks[product.ID] = product.Stock
 		}
 
-		// Mengupdate produk tertentu dalam daftar cache
-		for i, p := range products {
-			if p.ID == product.ID {
-				products[i] = product
-				break
+		for _, product := range plan.Updates {
+			stock, ok := stocks[product.ID]
+			if !ok {
+				return fmt.Errorf("product %s not found", product.ID)
+			}
+
+			err := tx.Model(&entity.Product{}).
+				Where("id = ?", product.ID).
+				Updates(map[string]any{"sku": product.SKU, "name": product.Name, "price": product.Price}).Error
+			if err != nil {
+				return err
+			}
+
+			target, ok := plan.Stocks[product.ID]
+			if !ok || target == stock {
+				continue
+			}
+
+			err = tx.Model(&entity.Product{}).
+				Where("id = ?", product.ID).
+				Update("stock", target).Error
+			if err != nil {
+				return err
+			}
+
+			note := "product import"
+			err = tx.Create(&entity.StockMovement{
+				ID:        uuid.NewString(),
+				ProductID: product.ID,
+				Change:    target - stock,
+				Reason:    "correction",
+				Reference: &plan.Reference,
+				Note:      &note,
+				CreatedAt: time.Now(),
+			}).Error
+			if err != nil {
+				return err
 			}
 		}
 
-		// Mengupdate cache dengan daftar produk yang telah dimodifikasi
-		jsonData, err := json.Marshal(products)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(plan.Creates)+len(plan.Updates))
+	for _, product := range plan.Creates {
+		ids = append(ids, product.ID)
+	}
+	for _, product := range plan.Updates {
+		ids = append(ids, product.ID)
+	}
+
+	refreshProductCache(c, r.redis, ids...)
+
+	return nil
+}
+
+// Export membaca seluruh produk aktif baris per baris dan memanggil fn untuk
+// setiap produk, sehingga daftar produk tidak perlu dimuat sekaligus ke memori
+func (r *repository) Export(c context.Context, fn func(product entity.Product) error) error {
+	rows, err := conn(c, r.db).
+		Model(&entity.Product{}).
+		Select("id, sku, name, price, stock, created_at").
+		Where("is_deleted = ?", false).
+		Order("created_at, id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var product entity.Product
+		err := r.db.ScanRows(rows, &product)
 		if err != nil {
-			return product, err
+			return err
 		}
 
-		err = r.redis.Set(c, productKey, jsonData, 24*time.Hour).Err()
+		err = fn(product)
 		if err != nil {
-			return product, err
+			return err
 		}
 	}
 
-	// Mengupdate produk di database
-	err = r.db.Updates(&product).Error
+	return rows.Err()
+}
+
+func (r *repository) GetPrices(c context.Context, productID string) ([]entity.ProductPrice, error) {
+	prices := []entity.ProductPrice{}
+
+	err := conn(c, r.db).
+		Where("product_id = ?", productID).
+		Order("variant_id NULLS FIRST, currency").
+		Find(&prices).Error
 	if err != nil {
-		return product, err
+		return nil, err
 	}
 
-	return product, nil
+	for i := range prices {
+		prices[i].ApplyCurrency()
+	}
+
+	return prices, nil
 }
 
-func (r *repository) Delete(c context.Context, product entity.Product) (entity.Product, error) {
-	productKey := viper.GetString("PRODUCTS_KEY")
-	productIdKey := viper.GetString("PRODUCT_ID_KEY") + product.ID
+// GetPricesByCurrency mengambil harga produk dan varian dalam satu mata uang
+// untuk checkout
+func (r *repository) GetPricesByCurrency(c context.Context, productIDs []string, currency string) ([]entity.ProductPrice, error) {
+	prices := []entity.ProductPrice{}
 
-	// Menghapus produk dari cache produk
-	err := r.redis.Del(c, productIdKey).Err()
+	if len(productIDs) == 0 {
+		return prices, nil
+	}
+
+	err := conn(c, r.db).
+		Where("product_id IN ? AND currency = ?", productIDs, currency).
+		Find(&prices).Error
 	if err != nil {
-		return product, err
+		return nil, err
+	}
+
+	for i := range prices {
+		prices[i].ApplyCurrency()
 	}
 
-	// Mendapatkan data cache saat ini
+	return prices, nil
+}
+
+// SetPrices mengganti seluruh daftar harga produk dalam satu transaksi
+func (r *repository) SetPrices(c context.Context, productID string, prices []entity.ProductPrice) error {
+	err := conn(c, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("product_id = ?", productID).Delete(&entity.ProductPrice{}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(prices) == 0 {
+			return nil
+		}
+
+		return tx.Create(&prices).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	refreshProductCache(c, r.redis, productID)
+
+	return nil
+}
+
+// reserveStock mengurangi stok produk di dalam transaksi tx. Baris produk
+// dikunci dengan SELECT ... FOR UPDATE (urut berdasarkan id untuk menghindari
+// deadlock) sehingga checkout yang berjalan bersamaan tidak bisa oversell.
+func reserveStock(tx *gorm.DB, quantities map[string]int64, reference string) error {
+	if len(quantities) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(quantities))
+	for id := range quantities {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
 	var products []entity.Product
-	cachedData, err := r.redis.Get(c, productKey).Result()
-	if err == nil {
-		err := json.Unmarshal([]byte(cachedData), &products)
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, stock").
+		Where("is_deleted = ? AND id IN ?", false, ids).
+		Order("id").
+		Find(&products).Error
+	if err != nil {
+		return err
+	}
+
+	stocks := make(map[string]int64, len(products))
+	for _, product := range products {
+		stocks[product.ID] = product.Stock
+	}
+
+	var outOfStock []string
+	for _, id := range ids {
+		stock, exists := stocks[id]
+		if !exists || stock < quantities[id] {
+			outOfStock = append(outOfStock, id)
+		}
+	}
+	if len(outOfStock) > 0 {
+		return &OutOfStockError{ProductIDs: outOfStock}
+	}
+
+	for _, id := range ids {
+		err := tx.Model(&entity.Product{}).
+			Where("id = ?", id).
+			Update("stock", gorm.Expr("stock - ?", quantities[id])).Error
 		if err != nil {
-			return product, err
+			return err
 		}
 
-		// Menghapus produk tertentu dari daftar cache
-		for i, p := range products {
-			if p.ID == product.ID {
-				products = append(products[:i], products[i+1:]...)
-				break
-			}
+		movement := entity.StockMovement{
+			ID:        uuid.NewString(),
+			ProductID: id,
+			Change:    -quantities[id],
+			Reason:    "order",
+			Reference: &reference,
+			CreatedAt: time.Now(),
+		}
+		err = tx.Create(&movement).Error
+		if err != nil {
+			return err
 		}
+	}
 
-		// Mengupdate cache dengan daftar produk yang telah dimodifikasi
-		jsonData, err := json.Marshal(products)
+	return nil
+}
+
+// releaseStock mengembalikan stok produk di dalam transaksi tx, misalnya
+// ketika pesanan dibatalkan atau kedaluwarsa
+func releaseStock(tx *gorm.DB, quantities map[string]int64, reason string, reference string) error {
+	ids := make([]string, 0, len(quantities))
+	for id := range quantities {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		err := tx.Model(&entity.Product{}).
+			Where("id = ?", id).
+			Update("stock", gorm.Expr("stock + ?", quantities[id])).Error
 		if err != nil {
-			return product, err
+			return err
 		}
 
-		err = r.redis.Set(c, productKey, jsonData, 24*time.Hour).Err()
+		movement := entity.StockMovement{
+			ID:        uuid.NewString(),
+			ProductID: id,
+			Change:    quantities[id],
+			Reason:    reason,
+			Reference: &reference,
+			CreatedAt: time.Now(),
+		}
+		err = tx.Create(&movement).Error
 		if err != nil {
-			return product, err
+			return err
 		}
 	}
 
-	// Menghapus produk di database
-	err = r.db.Updates(&product).Error
+	return nil
+}
+
+// detailQuantities menjumlahkan kuantitas baris pesanan per produk untuk
+// baris tanpa varian dan per varian untuk baris dengan varian
+func detailQuantities(details []entity.OrderDetail) (map[string]int64, map[string]int64) {
+	products := make(map[string]int64)
+	variants := make(map[string]int64)
+	for _, detail := range details {
+		if detail.VariantID != nil {
+			variants[*detail.VariantID] += int64(detail.Quantity)
+			continue
+		}
+		products[detail.ProductID] += int64(detail.Quantity)
+	}
+	return products, variants
+}
+
+// reserveVariantStock sama seperti reserveStock, untuk stok varian produk
+func reserveVariantStock(tx *gorm.DB, quantities map[string]int64, reference string) error {
+	if len(quantities) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(quantities))
+	for id := range quantities {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var variants []entity.ProductVariant
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, product_id, stock").
+		Where("is_deleted = ? AND id IN ?", false, ids).
+		Order("id").
+		Find(&variants).Error
 	if err != nil {
-		return product, err
+		return err
 	}
 
-	return product, nil
+	variantMap := make(map[string]entity.ProductVariant, len(variants))
+	for _, variant := range variants {
+		variantMap[variant.ID] = variant
+	}
+
+	var outOfStock []string
+	for _, id := range ids {
+		variant, exists := variantMap[id]
+		if !exists || variant.Stock < quantities[id] {
+			outOfStock = append(outOfStock, id)
+		}
+	}
+	if len(outOfStock) > 0 {
+		return &OutOfStockError{VariantIDs: outOfStock}
+	}
+
+	for _, id := range ids {
+		err := tx.Model(&entity.ProductVariant{}).
+			Where("id = ?", id).
+			Update("stock", gorm.Expr("stock - ?", quantities[id])).Error
+		if err != nil {
+			return err
+		}
+
+		variantID := id
+		movement := entity.StockMovement{
+			ID:        uuid.NewString(),
+			ProductID: variantMap[id].ProductID,
+			VariantID: &variantID,
+			Change:    -quantities[id],
+			Reason:    "order",
+			Reference: &reference,
+			CreatedAt: time.Now(),
+		}
+		err = tx.Create(&movement).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseVariantStock sama seperti releaseStock, untuk stok varian produk
+func releaseVariantStock(tx *gorm.DB, quantities map[string]int64, reason string, reference string) error {
+	if len(quantities) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(quantities))
+	for id := range quantities {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var variants []entity.ProductVariant
+	err := tx.Select("id, product_id").Where("id IN ?", ids).Find(&variants).Error
+	if err != nil {
+		return err
+	}
+
+	for _, variant := range variants {
+		err := tx.Model(&entity.ProductVariant{}).
+			Where("id = ?", variant.ID).
+			Update("stock", gorm.Expr("stock + ?", quantities[variant.ID])).Error
+		if err != nil {
+			return err
+		}
+
+		variantID := variant.ID
+		movement := entity.StockMovement{
+			ID:        uuid.NewString(),
+			ProductID: variant.ProductID,
+			VariantID: &variantID,
+			Change:    quantities[variant.ID],
+			Reason:    reason,
+			Reference: &reference,
+			CreatedAt: time.Now(),
+		}
+		err = tx.Create(&movement).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// invalidateProductCache menghapus cache produk berdasarkan id dan seluruh
+// cache daftar produk. Di dalam transaksi penghapusan ditunda sampai commit.
+func invalidateProductCache(c context.Context, rdb *redis.Client, ids ...string) error {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, viper.GetString("PRODUCT_ID_KEY")+id)
+	}
+
+	return AfterCommit(c, func(c context.Context) error {
+		if len(keys) > 0 {
+			err := rdb.Del(c, keys...).Err()
+			if err != nil {
+				return err
+			}
+		}
+
+		return rdb.Incr(c, viper.GetString("PRODUCTS_KEY")+":version").Err()
+	})
+}
+
+// refreshProductCache dipanggil setelah perubahan produk di-commit. Perubahan
+// sudah tersimpan, jadi kegagalan Redis hanya dicatat dan cache lama akan
+// hilang sendiri saat TTL habis.
+func refreshProductCache(c context.Context, rdb *redis.Client, ids ...string) {
+	err := invalidateProductCache(c, rdb, ids...)
+	if err != nil {
+		log.Println("error invalidate product cache:", err)
+	}
+}
+
+// Setiap bentuk query daftar produk disimpan di key tersendiri. Key tersebut
+// memuat versi cache, sehingga menaikkan versi akan membuat semua entri lama
+// tidak terpakai lagi dan hilang dengan sendirinya saat TTL habis.
+func (r *repository) productListKey(c context.Context, query dto.ReqProductQuery) (string, error) {
+	productKey := viper.GetString("PRODUCTS_KEY")
+
+	version, err := r.redis.Get(c, productKey+":version").Int64()
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(query.Page))
+	params.Set("limit", strconv.Itoa(query.Limit))
+	params.Set("name", strings.ToLower(query.Name))
+	params.Set("category", query.Category)
+	params.Set("sort", query.SortBy)
+	params.Set("order", query.Order)
+	if query.MinPrice != nil {
+		params.Set("min_price", strconv.FormatInt(*query.MinPrice, 10))
+	}
+	if query.MaxPrice != nil {
+		params.Set("max_price", strconv.FormatInt(*query.MaxPrice, 10))
+	}
+
+	hash := sha1.Sum([]byte(params.Encode()))
+	return fmt.Sprintf("%s:v%d:%s", productKey, version, hex.EncodeToString(hash[:])), nil
+}
+
+// productSearchKey memakai versi cache yang sama dengan daftar produk, sehingga
+// hasil pencarian ikut tidak berlaku saat produk berubah
+func (r *repository) productSearchKey(c context.Context, query dto.ReqProductSearch) (string, error) {
+	productKey := viper.GetString("PRODUCTS_KEY")
+
+	version, err := r.redis.Get(c, productKey+":version").Int64()
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("q", strings.ToLower(query.Query))
+	params.Set("page", strconv.Itoa(query.Page))
+	params.Set("limit", strconv.Itoa(query.Limit))
+
+	hash := sha1.Sum([]byte(params.Encode()))
+	return fmt.Sprintf("%s:v%d:search:%s", productKey, version, hex.EncodeToString(hash[:])), nil
+}
+
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
 }
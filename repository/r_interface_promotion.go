@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"online-shop/model/entity"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PromotionRepository interface {
+	List(c context.Context) ([]entity.Promotion, error)
+	GetByID(c context.Context, id string) (entity.Promotion, error)
+	GetByCode(c context.Context, code string) (entity.Promotion, error)
+	Create(c context.Context, promotion entity.Promotion) (entity.Promotion, error)
+	Update(c context.Context, promotion entity.Promotion) (entity.Promotion, error)
+	Delete(c context.Context, id string) error
+	CountRedemptions(c context.Context, promotionID string, email string) (int64, int64, error)
+	GetEligibleProductIDs(c context.Context, promotion entity.Promotion, productIDs []string) ([]string, error)
+}
+
+// ErrPromotionUsageLimit dikembalikan ketika batas pemakaian kode promo sudah tercapai
+var ErrPromotionUsageLimit = errors.New("promo code has reached its usage limit")
+
+type promotionRepository struct {
+	db *gorm.DB
+}
+
+func NewPromotionRepository(db *gorm.DB) PromotionRepository {
+	return &promotionRepository{db}
+}
+
+// promotionColumns termasuk jumlah pemakaian kode promo
+const promotionColumns = "promotions.*, " +
+	"(SELECT COUNT(*) FROM promotion_redemptions WHERE promotion_redemptions.promotion_id = promotions.id) AS used_count"
+
+func (r *promotionRepository) List(c context.Context) ([]entity.Promotion, error) {
+	promotions := []entity.Promotion{}
+
+	err := conn(c, r.db).
+		Select(promotionColumns).
+		Where("is_deleted = ?", false).
+		Order("created_at DESC").
+		Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range promotions {
+		promotions[i].ApplyCurrency()
+	}
+
+	return promotions, nil
+}
+
+func (r *promotionRepository) GetByID(c context.Context, id string) (entity.Promotion, error) {
+	var promotion entity.Promotion
+
+	err := conn(c, r.db).
+		Select(promotionColumns).
+		Where("is_deleted = ? AND id = ?", false, id).
+		Limit(1).
+		Find(&promotion).Error
+	if err != nil {
+		return promotion, err
+	}
+
+	promotion.ApplyCurrency()
+	return promotion, nil
+}
+
+func (r *promotionRepository) GetByCode(c context.Context, code string) (entity.Promotion, error) {
+	var promotion entity.Promotion
+
+	err := conn(c, r.db).
+		Select(promotionColumns).
+		Where("is_deleted = ? AND code = ?", false, strings.ToUpper(code)).
+		Limit(1).
+		Find(&promotion).Error
+	if err != nil {
+		return promotion, err
+	}
+
+	promotion.ApplyCurrency()
+	return promotion, nil
+}
+
+func (r *promotionRepository) Create(c context.Context, promotion entity.Promotion) (entity.Promotion, error) {
+	err := conn(c, r.db).Omit("used_count").Create(&promotion).Error
+	if err != nil {
+		return promotion, err
+	}
+
+	return promotion, nil
+}
+
+func (r *promotionRepository) Update(c context.Context, promotion entity.Promotion) (entity.Promotion, error) {
+	err := conn(c, r.db).
+		Model(&promotion).
+		Select("code", "description", "type", "value", "max_discount", "buy_quantity", "get_quantity", "min_subtotal",
+			"starts_at", "ends_at", "usage_limit", "usage_limit_per_email", "product_ids", "category_ids", "is_active", "updated_at").
+		Updates(&promotion).Error
+	if err != nil {
+		return promotion, err
+	}
+
+	return promotion, nil
+}
+
+func (r *promotionRepository) Delete(c context.Context, id string) error {
+	return conn(c, r.db).
+		Model(&entity.Promotion{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"is_deleted": true, "updated_at": time.Now()}).Error
+}
+
+// CountRedemptions mengembalikan jumlah pemakaian kode promo secara total
+// dan oleh email tertentu
+func (r *promotionRepository) CountRedemptions(c context.Context, promotionID string, email string) (int64, int64, error) {
+	var counts struct {
+		Total   int64
+		ByEmail int64
+	}
+
+	err := conn(c, r.db).
+		Model(&entity.PromotionRedemption{}).
+		Select("COUNT(*) AS total, COUNT(*) FILTER (WHERE email = ?) AS by_email", strings.ToLower(email)).
+		Where("promotion_id = ?", promotionID).
+		Scan(&counts).Error
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return counts.Total, counts.ByEmail, nil
+}
+
+// GetEligibleProductIDs mengembalikan produk dari productIDs yang termasuk
+// produk atau kategori (beserta sub-kategorinya) yang ditentukan promo
+func (r *promotionRepository) GetEligibleProductIDs(c context.Context, promotion entity.Promotion, productIDs []string) ([]string, error) {
+	if len(promotion.ProductIDs) == 0 && len(promotion.CategoryIDs) == 0 {
+		return productIDs, nil
+	}
+
+	eligible := map[string]bool{}
+	for _, id := range promotion.ProductIDs {
+		eligible[id] = true
+	}
+
+	if len(promotion.CategoryIDs) > 0 && len(productIDs) > 0 {
+		var inCategory []string
+		err := conn(c, r.db).Raw(`
+			SELECT DISTINCT product_id FROM product_categories
+			WHERE product_id IN ? AND category_id IN (
+				WITH RECURSIVE tree AS (
+					SELECT id FROM categories WHERE id IN ?
+					UNION
+					SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id
+				)
+				SELECT id FROM tree
+			)`, productIDs, promotion.CategoryIDs).Scan(&inCategory).Error
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range inCategory {
+			eligible[id] = true
+		}
+	}
+
+	result := []string{}
+	for _, id := range productIDs {
+		if eligible[id] {
+			result = append(result, id)
+		}
+	}
+
+	return result, nil
+}
+
+// redeemPromotion mencatat pemakaian kode promo di dalam transaksi tx. Baris
+// promo dikunci agar batas pemakaian tidak terlampaui oleh checkout yang
+// berjalan bersamaan.
+func redeemPromotion(tx *gorm.DB, promotionID string, order entity.Order) error {
+	var promotion entity.Promotion
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, usage_limit, usage_limit_per_email").
+		Where("id = ?", promotionID).
+		Take(&promotion).Error
+	if err != nil {
+		return err
+	}
+
+	email := strings.ToLower(order.Email)
+
+	if promotion.UsageLimit != nil {
+		var used int64
+		err := tx.Model(&entity.PromotionRedemption{}).Where("promotion_id = ?", promotionID).Count(&used).Error
+		if err != nil {
+			return err
+		}
+		if used >= *promotion.UsageLimit {
+			return ErrPromotionUsageLimit
+		}
+	}
+
+	if promotion.UsageLimitPerEmail != nil {
+		var used int64
+		err := tx.Model(&entity.PromotionRedemption{}).Where("promotion_id = ? AND email = ?", promotionID, email).Count(&used).Error
+		if err != nil {
+			return err
+		}
+		if used >= *promotion.UsageLimitPerEmail {
+			return ErrPromotionUsageLimit
+		}
+	}
+
+	return tx.Create(&entity.PromotionRedemption{
+		ID:          uuid.NewString(),
+		PromotionID: promotionID,
+		OrderID:     order.ID,
+		Email:       email,
+		CreatedAt:   time.Now(),
+	}).Error
+}
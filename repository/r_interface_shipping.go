@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"online-shop/model/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ShippingRepository interface {
+	List(c context.Context, activeOnly bool) ([]entity.ShippingMethod, error)
+	GetByID(c context.Context, id string) (entity.ShippingMethod, error)
+	Create(c context.Context, method entity.ShippingMethod) (entity.ShippingMethod, error)
+	Update(c context.Context, method entity.ShippingMethod) (entity.ShippingMethod, error)
+	Delete(c context.Context, id string) error
+}
+
+type shippingRepository struct {
+	db *gorm.DB
+}
+
+func NewShippingRepository(db *gorm.DB) ShippingRepository {
+	return &shippingRepository{db}
+}
+
+func (r *shippingRepository) List(c context.Context, activeOnly bool) ([]entity.ShippingMethod, error) {
+	methods := []entity.ShippingMethod{}
+
+	tx := conn(c, r.db).Where("is_deleted = ?", false)
+	if activeOnly {
+		tx = tx.Where("is_active = ?", true)
+	}
+
+	err := tx.Order("sort_order ASC, name ASC").Find(&methods).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return methods, nil
+}
+
+func (r *shippingRepository) GetByID(c context.Context, id string) (entity.ShippingMethod, error) {
+	var method entity.ShippingMethod
+
+	err := conn(c, r.db).Where("is_deleted = ? AND id = ?", false, id).Limit(1).Find(&method).Error
+	if err != nil {
+		return method, err
+	}
+
+	return method, nil
+}
+
+func (r *shippingRepository) Create(c context.Context, method entity.ShippingMethod) (entity.ShippingMethod, error) {
+	err := conn(c, r.db).Create(&method).Error
+	if err != nil {
+		return method, err
+	}
+
+	return method, nil
+}
+
+func (r *shippingRepository) Update(c context.Context, method entity.ShippingMethod) (entity.ShippingMethod, error) {
+	err := conn(c, r.db).
+		Model(&method).
+		Select("name", "description", "rates", "sort_order", "is_active", "updated_at").
+		Updates(&method).Error
+	if err != nil {
+		return method, err
+	}
+
+	return method, nil
+}
+
+func (r *shippingRepository) Delete(c context.Context, id string) error {
+	return conn(c, r.db).
+		Model(&entity.ShippingMethod{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"is_deleted": true, "updated_at": time.Now()}).Error
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"online-shop/model/entity"
+
+	"gorm.io/gorm"
+)
+
+type TaxRepository interface {
+	ListClasses(c context.Context) ([]entity.TaxClass, error)
+	GetClass(c context.Context, code string) (entity.TaxClass, error)
+	CreateClass(c context.Context, class entity.TaxClass) (entity.TaxClass, error)
+	DeleteClass(c context.Context, code string) error
+	CountClassUsage(c context.Context, code string) (int64, error)
+	ListRates(c context.Context) ([]entity.TaxRate, error)
+	GetRate(c context.Context, id string) (entity.TaxRate, error)
+	CreateRate(c context.Context, rate entity.TaxRate) (entity.TaxRate, error)
+	UpdateRate(c context.Context, rate entity.TaxRate) (entity.TaxRate, error)
+	DeleteRate(c context.Context, id string) error
+	GetRatesForProvince(c context.Context, province string) ([]entity.TaxRate, error)
+}
+
+type taxRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxRepository(db *gorm.DB) TaxRepository {
+	return &taxRepository{db}
+}
+
+func (r *taxRepository) ListClasses(c context.Context) ([]entity.TaxClass, error) {
+	classes := []entity.TaxClass{}
+
+	err := conn(c, r.db).Order("code ASC").Find(&classes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return classes, nil
+}
+
+func (r *taxRepository) GetClass(c context.Context, code string) (entity.TaxClass, error) {
+	var class entity.TaxClass
+
+	err := conn(c, r.db).Where("code = ?", code).Limit(1).Find(&class).Error
+	if err != nil {
+		return class, err
+	}
+
+	return class, nil
+}
+
+func (r *taxRepository) CreateClass(c context.Context, class entity.TaxClass) (entity.TaxClass, error) {
+	err := conn(c, r.db).Create(&class).Error
+	if err != nil {
+		return class, err
+	}
+
+	return class, nil
+}
+
+func (r *taxRepository) DeleteClass(c context.Context, code string) error {
+	return conn(c, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("tax_class = ?", code).Delete(&entity.TaxRate{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("code = ?", code).Delete(&entity.TaxClass{}).Error
+	})
+}
+
+// CountClassUsage menghitung produk yang masih memakai kelas pajak tersebut
+func (r *taxRepository) CountClassUsage(c context.Context, code string) (int64, error) {
+	var count int64
+
+	err := conn(c, r.db).Model(&entity.Product{}).Where("tax_class = ?", code).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *taxRepository) ListRates(c context.Context) ([]entity.TaxRate, error) {
+	rates := []entity.TaxRate{}
+
+	err := conn(c, r.db).Order("tax_class ASC, province ASC").Find(&rates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (r *taxRepository) GetRate(c context.Context, id string) (entity.TaxRate, error) {
+	var rate entity.TaxRate
+
+	err := conn(c, r.db).Where("id = ?", id).Limit(1).Find(&rate).Error
+	if err != nil {
+		return rate, err
+	}
+
+	return rate, nil
+}
+
+func (r *taxRepository) CreateRate(c context.Context, rate entity.TaxRate) (entity.TaxRate, error) {
+	err := conn(c, r.db).Create(&rate).Error
+	if err != nil {
+		return rate, err
+	}
+
+	return rate, nil
+}
+
+func (r *taxRepository) UpdateRate(c context.Context, rate entity.TaxRate) (entity.TaxRate, error) {
+	err := conn(c, r.db).
+		Model(&rate).
+		Select("tax_class", "province", "name", "rate", "updated_at").
+		Updates(&rate).Error
+	if err != nil {
+		return rate, err
+	}
+
+	return rate, nil
+}
+
+func (r *taxRepository) DeleteRate(c context.Context, id string) error {
+	return conn(c, r.db).Where("id = ?", id).Delete(&entity.TaxRate{}).Error
+}
+
+// GetRatesForProvince mengembalikan tarif khusus provinsi tersebut dan tarif
+// umum (province kosong) untuk setiap kelas pajak
+func (r *taxRepository) GetRatesForProvince(c context.Context, province string) ([]entity.TaxRate, error) {
+	rates := []entity.TaxRate{}
+
+	err := conn(c, r.db).
+		Where("province = '' OR LOWER(province) = LOWER(?)", province).
+		Find(&rates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
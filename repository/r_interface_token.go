@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+// TokenRepository menyimpan daftar token yang sudah dicabut di Redis
+type TokenRepository interface {
+	Revoke(c context.Context, tokenID string, expiresAt time.Time) error
+	IsRevoked(c context.Context, tokenID string) (bool, error)
+	Consume(c context.Context, tokenID string, expiresAt time.Time) (bool, error)
+}
+
+type tokenRepository struct {
+	redis *redis.Client
+}
+
+func NewTokenRepository(redis *redis.Client) TokenRepository {
+	return &tokenRepository{redis}
+}
+
+func (r *tokenRepository) Revoke(c context.Context, tokenID string, expiresAt time.Time) error {
+	// Token cukup disimpan sampai masa berlakunya habis
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	return r.redis.Set(c, viper.GetString("REVOKED_TOKEN_KEY")+tokenID, 1, ttl).Err()
+}
+
+func (r *tokenRepository) IsRevoked(c context.Context, tokenID string) (bool, error) {
+	count, err := r.redis.Exists(c, viper.GetString("REVOKED_TOKEN_KEY")+tokenID).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Consume mencabut token sekali pakai secara atomik dan mengembalikan false
+// jika token sudah pernah dicabut atau dipakai sebelumnya
+func (r *tokenRepository) Consume(c context.Context, tokenID string, expiresAt time.Time) (bool, error) {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return false, nil
+	}
+
+	return r.redis.SetNX(c, viper.GetString("REVOKED_TOKEN_KEY")+tokenID, 1, ttl).Result()
+}
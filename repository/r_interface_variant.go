@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"online-shop/model/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VariantRepository interface {
+	GetOptions(c context.Context, productID string) ([]entity.ProductOption, error)
+	SetOptions(c context.Context, productID string, options []entity.ProductOption) error
+	GetByProductID(c context.Context, productID string) ([]entity.ProductVariant, error)
+	GetByProductIDs(c context.Context, productIDs []string) ([]entity.ProductVariant, error)
+	GetByID(c context.Context, id string) (entity.ProductVariant, error)
+	GetBySKU(c context.Context, sku string) (entity.ProductVariant, error)
+	Create(c context.Context, variant entity.ProductVariant) (entity.ProductVariant, error)
+	Update(c context.Context, variant entity.ProductVariant) (entity.ProductVariant, error)
+	Delete(c context.Context, variant entity.ProductVariant) error
+	AdjustStock(c context.Context, movement entity.StockMovement) (entity.ProductVariant, error)
+}
+
+type variantRepository struct {
+	db *gorm.DB
+}
+
+func NewVariantRepository(db *gorm.DB) VariantRepository {
+	return &variantRepository{db}
+}
+
+func (r *variantRepository) GetOptions(c context.Context, productID string) ([]entity.ProductOption, error) {
+	options := []entity.ProductOption{}
+
+	err := conn(c, r.db).Where("product_id = ?", productID).Order("position ASC").Find(&options).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// SetOptions mengganti seluruh jenis opsi sebuah produk
+func (r *variantRepository) SetOptions(c context.Context, productID string, options []entity.ProductOption) error {
+	return conn(c, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("product_id = ?", productID).Delete(&entity.ProductOption{}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(options) == 0 {
+			return nil
+		}
+
+		return tx.Create(&options).Error
+	})
+}
+
+func (r *variantRepository) GetByProductID(c context.Context, productID string) ([]entity.ProductVariant, error) {
+	return r.GetByProductIDs(c, []string{productID})
+}
+
+func (r *variantRepository) GetByProductIDs(c context.Context, productIDs []string) ([]entity.ProductVariant, error) {
+	variants := []entity.ProductVariant{}
+	if len(productIDs) == 0 {
+		return variants, nil
+	}
+
+	err := conn(c, r.db).
+		Where("is_deleted = ? AND product_id IN ?", false, productIDs).
+		Order("created_at ASC, id ASC").
+		Find(&variants).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return variants, nil
+}
+
+func (r *variantRepository) GetByID(c context.Context, id string) (entity.ProductVariant, error) {
+	var variant entity.ProductVariant
+
+	err := conn(c, r.db).Where("is_deleted = ? AND id = ?", false, id).Limit(1).Find(&variant).Error
+	if err != nil {
+		return variant, err
+	}
+
+	return variant, nil
+}
+
+func (r *variantRepository) GetBySKU(c context.Context, sku string) (entity.ProductVariant, error) {
+	var variant entity.ProductVariant
+
+	err := conn(c, r.db).Where("is_deleted = ? AND sku = ?", false, sku).Limit(1).Find(&variant).Error
+	if err != nil {
+		return variant, err
+	}
+
+	return variant, nil
+}
+
+func (r *variantRepository) Create(c context.Context, variant entity.ProductVariant) (entity.ProductVariant, error) {
+	err := conn(c, r.db).Create(&variant).Error
+	if err != nil {
+		return variant, err
+	}
+
+	return variant, nil
+}
+
+func (r *variantRepository) Update(c context.Context, variant entity.ProductVariant) (entity.ProductVariant, error) {
+	// Stok hanya boleh berubah lewat AdjustStock
+	err := conn(c, r.db).
+		Model(&variant).
+		Select("sku", "options", "price", "updated_at").
+		Updates(&variant).Error
+	if err != nil {
+		return variant, err
+	}
+
+	return variant, nil
+}
+
+func (r *variantRepository) Delete(c context.Context, variant entity.ProductVariant) error {
+	return conn(c, r.db).
+		Model(&variant).
+		Updates(map[string]interface{}{"is_deleted": true, "updated_at": variant.UpdatedAt}).Error
+}
+
+func (r *variantRepository) AdjustStock(c context.Context, movement entity.StockMovement) (entity.ProductVariant, error) {
+	var variant entity.ProductVariant
+
+	err := conn(c, r.db).Transaction(func(tx *gorm.DB) error {
+		// Mengunci baris varian agar tidak bentrok dengan checkout yang berjalan
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("is_deleted = ? AND id = ? AND product_id = ?", false, *movement.VariantID, movement.ProductID).
+			Take(&variant).Error
+		if err != nil {
+			return err
+		}
+
+		if variant.Stock+movement.Change < 0 {
+			return &OutOfStockError{VariantIDs: []string{variant.ID}}
+		}
+
+		err = tx.Model(&entity.ProductVariant{}).
+			Where("id = ?", variant.ID).
+			Update("stock", gorm.Expr("stock + ?", movement.Change)).Error
+		if err != nil {
+			return err
+		}
+
+		variant.Stock += movement.Change
+		return tx.Create(&movement).Error
+	})
+	if err != nil {
+		return variant, err
+	}
+
+	return variant, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type transactionKey struct{}
+
+// transaction adalah transaksi database yang dibawa lewat context beserta
+// pekerjaan yang baru boleh dijalankan setelah transaksi selesai
+type transaction struct {
+	db            *gorm.DB
+	afterCommit   []func(c context.Context) error
+	afterRollback []func(c context.Context)
+}
+
+// runInTransaction menjalankan fn dalam satu transaksi database. Repository
+// yang dipanggil dengan context dari fn memakai transaksi yang sama, jadi
+// seluruh perubahannya tersimpan atau batal bersama. Jika c sudah membawa
+// transaksi, fn ikut transaksi tersebut.
+func runInTransaction(c context.Context, db *gorm.DB, fn func(c context.Context) error) error {
+	if _, ok := c.Value(transactionKey{}).(*transaction); ok {
+		return fn(c)
+	}
+
+	t := &transaction{}
+	err := db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		t.db = tx
+		return fn(context.WithValue(c, transactionKey{}, t))
+	})
+	if err != nil {
+		for _, fn := range t.afterRollback {
+			fn(c)
+		}
+		return err
+	}
+
+	// Perubahan sudah tersimpan, kegagalan di sini hanya dicatat
+	for _, fn := range t.afterCommit {
+		errAfter := fn(c)
+		if errAfter != nil {
+			log.Println("error after commit:", errAfter)
+		}
+	}
+
+	return nil
+}
+
+// conn mengembalikan transaksi yang dibawa c, atau db jika c tidak membawa
+// transaksi. Semua query repository harus lewat conn agar ikut transaksi.
+func conn(c context.Context, db *gorm.DB) *gorm.DB {
+	if t, ok := c.Value(transactionKey{}).(*transaction); ok {
+		return t.db.WithContext(c)
+	}
+	return db.WithContext(c)
+}
+
+func inTransaction(c context.Context) bool {
+	_, ok := c.Value(transactionKey{}).(*transaction)
+	return ok
+}
+
+// AfterCommit menjalankan fn setelah transaksi yang dibawa c di-commit, atau
+// langsung jika c tidak membawa transaksi. Dipakai untuk cache dan file yang
+// tidak boleh berubah sebelum datanya benar-benar tersimpan.
+func AfterCommit(c context.Context, fn func(c context.Context) error) error {
+	t, ok := c.Value(transactionKey{}).(*transaction)
+	if !ok {
+		return fn(c)
+	}
+
+	t.afterCommit = append(t.afterCommit, fn)
+	return nil
+}
+
+// AfterRollback menjalankan fn jika transaksi yang dibawa c dibatalkan. Tanpa
+// transaksi fn tidak pernah dijalankan.
+func AfterRollback(c context.Context, fn func(c context.Context)) {
+	t, ok := c.Value(transactionKey{}).(*transaction)
+	if ok {
+		t.afterRollback = append(t.afterRollback, fn)
+	}
+}
+
+// cacheGet membaca cache Redis. Di dalam transaksi cache dilewati karena
+// data yang sedang diubah transaksi hanya terlihat dari database.
+func cacheGet(c context.Context, rdb *redis.Client, key string) (string, error) {
+	if inTransaction(c) {
+		return "", redis.Nil
+	}
+	return rdb.Get(c, key).Result()
+}
+
+// cacheSet menyimpan cache Redis, kecuali di dalam transaksi karena datanya
+// belum tentu di-commit
+func cacheSet(c context.Context, rdb *redis.Client, key string, value any, ttl time.Duration) error {
+	if inTransaction(c) {
+		return nil
+	}
+	return rdb.Set(c, key, value, ttl).Err()
+}
+
+// cacheDel menghapus cache Redis setelah perubahan datanya di-commit
+func cacheDel(c context.Context, rdb *redis.Client, keys ...string) error {
+	return AfterCommit(c, func(c context.Context) error {
+		return rdb.Del(c, keys...).Err()
+	})
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type LocalOptions struct {
+	Dir     string
+	BaseURL string
+}
+
+type localStorage struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStorage menyimpan file di direktori lokal. File disajikan oleh
+// server HTTP di bawah BaseURL.
+func NewLocalStorage(options LocalOptions) (Storage, error) {
+	err := os.MkdirAll(options.Dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &localStorage{
+		dir:     options.Dir,
+		baseURL: strings.TrimSuffix(options.BaseURL, "/"),
+	}, nil
+}
+
+func (s *localStorage) Name() string {
+	return "local"
+}
+
+func (s *localStorage) Put(c context.Context, key string, body io.Reader, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	// Ditulis ke file sementara lalu di-rename agar tidak ada file setengah jadi
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, body)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *localStorage) Delete(c context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *localStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// path memastikan key tidak keluar dari direktori storage
+func (s *localStorage) path(key string) (string, error) {
+	if key == "" || !filepath.IsLocal(key) {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
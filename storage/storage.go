@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage adalah abstraksi penyimpanan file media. Implementasi lain
+// (misalnya S3-compatible) cukup memenuhi interface ini.
+type Storage interface {
+	Name() string
+	Put(c context.Context, key string, body io.Reader, contentType string) error
+	Delete(c context.Context, key string) error
+	URL(key string) string
+}
+
+// NewStorage membuat storage berdasarkan driver yang dikonfigurasi
+func NewStorage(driver string, options LocalOptions) (Storage, error) {
+	switch driver {
+	case "local":
+		return NewLocalStorage(options)
+	default:
+		return nil, fmt.Errorf("storage driver %s not supported", driver)
+	}
+}
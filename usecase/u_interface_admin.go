@@ -0,0 +1,289 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AdminUsecase interface {
+	Bootstrap(c context.Context) error
+	Login(c context.Context, input dto.ReqAdminLogin) (dto.ResAdminToken, error)
+	Authenticate(c context.Context, token string) (entity.AdminUser, entity.AdminToken, error)
+	Logout(c context.Context, tokenID string) error
+	RotateToken(c context.Context, admin entity.AdminUser, tokenID string) (dto.ResAdminToken, error)
+	CreateUser(c context.Context, input dto.ReqAdminUser) (entity.AdminUser, error)
+	ListUsers(c context.Context) ([]entity.AdminUser, error)
+	GetUser(c context.Context, id string) (entity.AdminUser, error)
+	UpdateUser(c context.Context, id string, input dto.ReqUpdateAdminUser) (entity.AdminUser, error)
+	RevokeUserTokens(c context.Context, id string) error
+}
+
+var ErrInvalidAdminToken = errors.New("invalid or expired admin token")
+
+// Password contoh yang pernah ada di konfigurasi, tidak boleh dipakai untuk
+// superadmin pertama
+const defaultBootstrapPassword = "change-me-please"
+
+// adminPasswordMinLength sama dengan batas minimal password di dto.ReqAdminUser
+const adminPasswordMinLength = 12
+
+type adminUsecase struct {
+	repo repository.AdminRepository
+}
+
+func NewAdminUsecase(repo repository.AdminRepository) AdminUsecase {
+	return &adminUsecase{repo}
+}
+
+// Bootstrap membuat superadmin pertama dari konfigurasi jika belum ada admin
+// sama sekali. Password-nya tidak disimpan di file konfigurasi dan harus
+// diberikan lewat environment ADMIN_BOOTSTRAP_PASSWORD.
+func (u *adminUsecase) Bootstrap(c context.Context) error {
+	username := viper.GetString("ADMIN_BOOTSTRAP_USERNAME")
+	if username == "" {
+		return nil
+	}
+
+	count, err := u.repo.CountUsers(c)
+	if err != nil || count > 0 {
+		return err
+	}
+
+	password := viper.GetString("ADMIN_BOOTSTRAP_PASSWORD")
+	if password == "" {
+		return errors.New("ADMIN_BOOTSTRAP_PASSWORD must be set in the environment to create the first admin")
+	}
+	if password == defaultBootstrapPassword || len(password) < adminPasswordMinLength {
+		return fmt.Errorf("ADMIN_BOOTSTRAP_PASSWORD must not be the example password and must be at least %d characters", adminPasswordMinLength)
+	}
+
+	_, err = u.CreateUser(c, dto.ReqAdminUser{
+		Username: username,
+		Password: password,
+		Role:     entity.RoleSuperadmin,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Println("bootstrap superadmin created:", username)
+	return nil
+}
+
+func (u *adminUsecase) Login(c context.Context, input dto.ReqAdminLogin) (dto.ResAdminToken, error) {
+	admin, err := u.repo.GetUserByUsername(c, input.Username)
+	if err != nil {
+		return dto.ResAdminToken{}, err
+	}
+
+	passwordHash := admin.PasswordHash
+	if admin.ID == "" {
+		passwordHash = dummyPasswordHash
+	}
+
+	errPass := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(input.Password))
+	if errPass != nil || admin.ID == "" || !admin.IsActive {
+		return dto.ResAdminToken{}, errors.New("invalid username or password")
+	}
+
+	return u.issueToken(c, admin)
+}
+
+// Authenticate mencari token berdasarkan hash-nya. Token mentah tidak pernah
+// disimpan sehingga kebocoran database tidak membocorkan token yang aktif.
+func (u *adminUsecase) Authenticate(c context.Context, token string) (entity.AdminUser, entity.AdminToken, error) {
+	adminToken, err := u.repo.GetTokenByHash(c, hashToken(token))
+	if err != nil {
+		return entity.AdminUser{}, adminToken, err
+	}
+
+	currentTime := time.Now()
+	if adminToken.ID == "" || adminToken.RevokedAt != nil || currentTime.After(adminToken.ExpiresAt) {
+		return entity.AdminUser{}, adminToken, ErrInvalidAdminToken
+	}
+
+	admin, err := u.repo.GetUserByID(c, adminToken.AdminID)
+	if err != nil {
+		return admin, adminToken, err
+	}
+
+	if admin.ID == "" || !admin.IsActive {
+		return admin, adminToken, ErrInvalidAdminToken
+	}
+
+	// Waktu pemakaian cukup dicatat paling sering sekali per menit agar setiap
+	// request admin tidak menulis ke database. Kegagalan pencatatan tidak
+	// membatalkan request karena token tetap valid.
+	if adminToken.LastUsedAt == nil || currentTime.Sub(*adminToken.LastUsedAt) > time.Minute {
+		err = u.repo.TouchToken(c, adminToken.ID, currentTime)
+		if err != nil {
+			log.Println("error touch admin token:", adminToken.ID, err)
+		} else {
+			adminToken.LastUsedAt = &currentTime
+		}
+	}
+
+	return admin, adminToken, nil
+}
+
+func (u *adminUsecase) Logout(c context.Context, tokenID string) error {
+	return u.repo.RevokeToken(c, tokenID)
+}
+
+// RotateToken menerbitkan token baru dan mencabut token yang sedang dipakai
+func (u *adminUsecase) RotateToken(c context.Context, admin entity.AdminUser, tokenID string) (dto.ResAdminToken, error) {
+	result, err := u.issueToken(c, admin)
+	if err != nil {
+		return result, err
+	}
+
+	err = u.repo.RevokeToken(c, tokenID)
+	if err != nil {
+		return dto.ResAdminToken{}, err
+	}
+
+	return result, nil
+}
+
+func (u *adminUsecase) CreateUser(c context.Context, input dto.ReqAdminUser) (entity.AdminUser, error) {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return entity.AdminUser{}, err
+	}
+
+	currentTime := time.Now()
+	admin := entity.AdminUser{
+		ID:           uuid.NewString(),
+		Username:     input.Username,
+		PasswordHash: string(passwordHash),
+		Role:         input.Role,
+		IsActive:     true,
+		CreatedAt:    currentTime,
+		UpdatedAt:    currentTime,
+	}
+
+	result, err := u.repo.CreateUser(c, admin)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (u *adminUsecase) ListUsers(c context.Context) ([]entity.AdminUser, error) {
+	result, err := u.repo.ListUsers(c)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (u *adminUsecase) GetUser(c context.Context, id string) (entity.AdminUser, error) {
+	admin, err := u.repo.GetUserByID(c, id)
+	if err != nil {
+		return admin, err
+	}
+
+	if admin.ID != id {
+		return admin, errors.New("admin not found")
+	}
+
+	return admin, nil
+}
+
+func (u *adminUsecase) UpdateUser(c context.Context, id string, input dto.ReqUpdateAdminUser) (entity.AdminUser, error) {
+	admin, err := u.repo.GetUserByID(c, id)
+	if err != nil {
+		return admin, err
+	}
+
+	if admin.ID != id {
+		return admin, errors.New("admin not found")
+	}
+
+	if input.Role != "" {
+		admin.Role = input.Role
+	}
+
+	if input.IsActive != nil {
+		admin.IsActive = *input.IsActive
+	}
+
+	if input.Password != "" {
+		passwordHash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return admin, err
+		}
+		admin.PasswordHash = string(passwordHash)
+	}
+
+	admin.UpdatedAt = time.Now()
+
+	result, err := u.repo.UpdateUser(c, admin)
+	if err != nil {
+		return result, err
+	}
+
+	// Token lama tidak boleh tetap berlaku setelah password diganti atau akun dinonaktifkan
+	if input.Password != "" || !admin.IsActive {
+		err = u.repo.RevokeUserTokens(c, admin.ID)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+func (u *adminUsecase) RevokeUserTokens(c context.Context, id string) error {
+	admin, err := u.repo.GetUserByID(c, id)
+	if err != nil {
+		return err
+	}
+
+	if admin.ID != id {
+		return errors.New("admin not found")
+	}
+
+	return u.repo.RevokeUserTokens(c, id)
+}
+
+func (u *adminUsecase) issueToken(c context.Context, admin entity.AdminUser) (dto.ResAdminToken, error) {
+	token, err := randomToken()
+	if err != nil {
+		return dto.ResAdminToken{}, err
+	}
+
+	currentTime := time.Now()
+	adminToken := entity.AdminToken{
+		ID:        uuid.NewString(),
+		AdminID:   admin.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: currentTime.Add(viper.GetDuration("ADMIN_TOKEN_TTL")),
+		CreatedAt: currentTime,
+	}
+
+	adminToken, err = u.repo.CreateToken(c, adminToken)
+	if err != nil {
+		return dto.ResAdminToken{}, err
+	}
+
+	result := dto.ResAdminToken{
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresAt: adminToken.ExpiresAt,
+	}
+
+	return result, nil
+}
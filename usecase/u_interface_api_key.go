@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyUsecase interface {
+	Create(c context.Context, input dto.ReqAPIKey, actor string) (entity.APIKeyWithSecret, error)
+	List(c context.Context) ([]entity.APIKey, error)
+	Revoke(c context.Context, id string) error
+	Authenticate(c context.Context, rawKey string, clientIP string) (entity.APIKey, error)
+}
+
+var (
+	ErrInvalidAPIKey   = errors.New("invalid, expired or revoked API key")
+	ErrAPIKeyIPBlocked = errors.New("API key is not allowed from this IP address")
+)
+
+type apiKeyUsecase struct {
+	repo repository.APIKeyRepository
+}
+
+func NewAPIKeyUsecase(repo repository.APIKeyRepository) APIKeyUsecase {
+	return &apiKeyUsecase{repo}
+}
+
+func (u *apiKeyUsecase) Create(c context.Context, input dto.ReqAPIKey, actor string) (entity.APIKeyWithSecret, error) {
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return entity.APIKeyWithSecret{}, errors.New("expiresAt must be in the future")
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return entity.APIKeyWithSecret{}, err
+	}
+
+	// Format key: sk_<prefix>_<secret>, prefix ditampilkan untuk identifikasi
+	prefix := secret[:8]
+	rawKey := "sk_" + prefix + "_" + secret[8:]
+
+	allowedIPs := input.AllowedIPs
+	if allowedIPs == nil {
+		allowedIPs = []string{}
+	}
+
+	key := entity.APIKey{
+		ID:         uuid.NewString(),
+		Name:       input.Name,
+		Prefix:     "sk_" + prefix,
+		KeyHash:    hashToken(rawKey),
+		Scopes:     input.Scopes,
+		AllowedIPs: allowedIPs,
+		ExpiresAt:  input.ExpiresAt,
+		CreatedBy:  actor,
+		CreatedAt:  time.Now(),
+	}
+
+	result, err := u.repo.Create(c, key)
+	if err != nil {
+		return entity.APIKeyWithSecret{}, err
+	}
+
+	return entity.APIKeyWithSecret{APIKey: result, Key: rawKey}, nil
+}
+
+func (u *apiKeyUsecase) List(c context.Context) ([]entity.APIKey, error) {
+	result, err := u.repo.List(c)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (u *apiKeyUsecase) Revoke(c context.Context, id string) error {
+	key, err := u.repo.GetByID(c, id)
+	if err != nil {
+		return err
+	}
+
+	if key.ID != id {
+		return errors.New("API key not found")
+	}
+
+	return u.repo.Revoke(c, id)
+}
+
+func (u *apiKeyUsecase) Authenticate(c context.Context, rawKey string, clientIP string) (entity.APIKey, error) {
+	if !strings.HasPrefix(rawKey, "sk_") {
+		return entity.APIKey{}, ErrInvalidAPIKey
+	}
+
+	key, err := u.repo.GetByHash(c, hashToken(rawKey))
+	if err != nil {
+		return key, err
+	}
+
+	currentTime := time.Now()
+	if key.ID == "" || key.RevokedAt != nil || (key.ExpiresAt != nil && currentTime.After(*key.ExpiresAt)) {
+		return entity.APIKey{}, ErrInvalidAPIKey
+	}
+
+	if !ipAllowed(key.AllowedIPs, clientIP) {
+		return entity.APIKey{}, ErrAPIKeyIPBlocked
+	}
+
+	// last_used_at cukup diperbarui paling sering sekali per menit
+	if key.LastUsedAt == nil || currentTime.Sub(*key.LastUsedAt) > time.Minute {
+		err = u.repo.Touch(c, key.ID, currentTime)
+		if err != nil {
+			return key, err
+		}
+		key.LastUsedAt = &currentTime
+	}
+
+	return key, nil
+}
+
+// ipAllowed memeriksa IP terhadap allow-list berisi IP tunggal atau CIDR.
+// Allow-list kosong berarti semua IP diizinkan.
+func ipAllowed(allowed []string, clientIP string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+
+	for _, entry := range allowed {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		if allowedIP := net.ParseIP(entry); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/repository"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditUsecase interface {
+	Record(c context.Context, log entity.AuditLog, before any, after any) error
+	List(c context.Context, query dto.ReqAuditQuery) (dto.ResAuditLogs, error)
+	Transaction(c context.Context, fn func(c context.Context) error) error
+}
+
+type auditUsecase struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditUsecase(repo repository.AuditRepository) AuditUsecase {
+	return &auditUsecase{repo}
+}
+
+// Record menyimpan satu entri audit. before dan after adalah snapshot entity
+// sebelum dan sesudah perubahan, nil jika entity belum atau sudah tidak ada.
+func (u *auditUsecase) Record(c context.Context, log entity.AuditLog, before any, after any) error {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return err
+	}
+
+	afterFields, err := toFields(after)
+	if err != nil {
+		return err
+	}
+
+	// Hanya field yang berubah yang disimpan
+	if beforeFields != nil && afterFields != nil {
+		for key, value := range beforeFields {
+			if afterValue, ok := afterFields[key]; ok && reflect.DeepEqual(value, afterValue) {
+				delete(beforeFields, key)
+				delete(afterFields, key)
+			}
+		}
+	}
+
+	log.ID = uuid.NewString()
+	log.Before = beforeFields
+	log.After = afterFields
+	log.CreatedAt = time.Now()
+
+	return u.repo.Create(c, log)
+}
+
+func (u *auditUsecase) List(c context.Context, query dto.ReqAuditQuery) (dto.ResAuditLogs, error) {
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	result, err := u.repo.List(c, query)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Transaction menjalankan perubahan dan pencatatan audit-nya dalam satu
+// transaksi database. Jika fn atau Record di dalamnya gagal, seluruh
+// perubahan dibatalkan sehingga tidak ada perubahan yang tidak tercatat.
+func (u *auditUsecase) Transaction(c context.Context, fn func(c context.Context) error) error {
+	return u.repo.Transaction(c, fn)
+}
+
+// toFields mengubah entity menjadi map field JSON-nya
+func toFields(value any) (map[string]any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	err = json.Unmarshal(raw, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
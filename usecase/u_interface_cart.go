@@ -0,0 +1,438 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+// CartUsecase mengelola keranjang tamu dan pelanggan. customerID kosong
+// berarti request dari tamu, keranjang milik pelanggan hanya bisa diakses
+// oleh pelanggan tersebut.
+type CartUsecase interface {
+	Get(c context.Context, id string, customerID string) (dto.ResCart, error)
+	GetByCustomerID(c context.Context, customerID string) (dto.ResCart, error)
+	AddItems(c context.Context, id string, customerID string, input dto.ReqCartItems) (dto.ResCart, error)
+	Update(c context.Context, id string, customerID string, input dto.ReqCartUpdate) (dto.ResCart, error)
+	Delete(c context.Context, id string, customerID string) error
+	PrepareCheckout(c context.Context, id string, customerID string, input dto.ReqCartCheckout) (entity.Checkout, error)
+}
+
+var errCartNotFound = errors.New("cart not found")
+
+type cartUsecase struct {
+	repo        repository.CartRepository
+	productRepo repository.Repository
+	variantRepo repository.VariantRepository
+}
+
+func NewCartUsecase(repo repository.CartRepository, productRepo repository.Repository, variantRepo repository.VariantRepository) CartUsecase {
+	return &cartUsecase{repo, productRepo, variantRepo}
+}
+
+func (u *cartUsecase) Get(c context.Context, id string, customerID string) (dto.ResCart, error) {
+	cart, err := u.getCart(c, id, customerID)
+	if err != nil {
+		return dto.ResCart{}, err
+	}
+
+	return u.view(c, cart)
+}
+
+func (u *cartUsecase) GetByCustomerID(c context.Context, customerID string) (dto.ResCart, error) {
+	cart, err := u.repo.GetByCustomerID(c, customerID)
+	if err != nil {
+		return dto.ResCart{}, err
+	}
+
+	if cart.ID == "" {
+		return dto.ResCart{}, errCartNotFound
+	}
+
+	return u.view(c, cart)
+}
+
+// AddItems menambahkan barang ke keranjang, keranjang dibuat jika belum ada
+func (u *cartUsecase) AddItems(c context.Context, id string, customerID string, input dto.ReqCartItems) (dto.ResCart, error) {
+	for i, item := range input.Items {
+		if item.Quantity < 1 {
+			return dto.ResCart{}, fmt.Errorf("items[%d]: quantity must be greater than zero", i)
+		}
+	}
+
+	err := u.checkItems(c, input.Items)
+	if err != nil {
+		return dto.ResCart{}, err
+	}
+
+	cart, err := u.update(c, id, customerID, true, func(cart *entity.Cart) error {
+		for _, item := range input.Items {
+			err := cart.AddItem(item.ProductID, item.VariantID, item.Quantity)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return dto.ResCart{}, err
+	}
+
+	return u.view(c, cart)
+}
+
+// Update mengganti jumlah barang, mata uang, atau kode promo keranjang
+func (u *cartUsecase) Update(c context.Context, id string, customerID string, input dto.ReqCartUpdate) (dto.ResCart, error) {
+	var added []dto.ReqCartItem
+	for _, item := range input.Items {
+		if item.Quantity > 0 {
+			added = append(added, item)
+		}
+	}
+
+	err := u.checkItems(c, added)
+	if err != nil {
+		return dto.ResCart{}, err
+	}
+
+	var currency string
+	if input.Currency != nil && *input.Currency != "" {
+		currency, err = checkoutCurrency(*input.Currency)
+		if err != nil {
+			return dto.ResCart{}, err
+		}
+	}
+
+	cart, err := u.update(c, id, customerID, false, func(cart *entity.Cart) error {
+		for _, item := range input.Items {
+			err := cart.SetItem(item.ProductID, item.VariantID, item.Quantity)
+			if err != nil {
+				return err
+			}
+		}
+
+		if input.Currency != nil {
+			cart.Currency = currency
+		}
+		if input.PromoCode != nil {
+			cart.PromoCode = strings.ToUpper(strings.TrimSpace(*input.PromoCode))
+		}
+		return nil
+	})
+	if err != nil {
+		return dto.ResCart{}, err
+	}
+
+	return u.view(c, cart)
+}
+
+func (u *cartUsecase) Delete(c context.Context, id string, customerID string) error {
+	cart, err := u.getCart(c, id, customerID)
+	if err != nil {
+		return err
+	}
+
+	return u.repo.Delete(c, cart)
+}
+
+// PrepareCheckout menyusun input checkout dari isi keranjang. Harga dan stok
+// divalidasi ulang oleh Checkout sehingga keranjang tidak perlu dikunci.
+func (u *cartUsecase) PrepareCheckout(c context.Context, id string, customerID string, input dto.ReqCartCheckout) (entity.Checkout, error) {
+	cart, err := u.getCart(c, id, customerID)
+	if err != nil {
+		return entity.Checkout{}, err
+	}
+
+	if len(cart.Items) == 0 {
+		return entity.Checkout{}, errors.New("cart is empty")
+	}
+
+	checkout := entity.Checkout{
+		CustomerID:       customerID,
+		Email:            input.Email,
+		Address:          input.Address,
+		ShippingMethodID: input.ShippingMethodID,
+		PromoCode:        cart.PromoCode,
+		Currency:         cart.Currency,
+	}
+	for _, item := range cart.Items {
+		checkout.Products = append(checkout.Products, entity.ProductQuantity{
+			ID:        item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	return checkout, nil
+}
+
+// getCart mengambil keranjang yang boleh diakses oleh customerID
+func (u *cartUsecase) getCart(c context.Context, id string, customerID string) (entity.Cart, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return entity.Cart{}, errors.New("cart id must be a UUID")
+	}
+
+	cart, err := u.repo.Get(c, id)
+	if err != nil {
+		return cart, err
+	}
+
+	if cart.ID == "" || !canAccessCart(cart, customerID) {
+		return entity.Cart{}, errCartNotFound
+	}
+
+	return cart, nil
+}
+
+// update mengubah keranjang secara atomik. Keranjang baru hanya dibuat jika
+// create bernilai true dan menjadi milik pelanggan yang sedang login.
+func (u *cartUsecase) update(c context.Context, id string, customerID string, create bool, fn func(cart *entity.Cart) error) (entity.Cart, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return entity.Cart{}, errors.New("cart id must be a UUID")
+	}
+
+	return u.repo.Update(c, id, func(cart *entity.Cart) error {
+		now := time.Now()
+		if cart.ID == "" {
+			if !create {
+				return errCartNotFound
+			}
+
+			*cart = entity.Cart{ID: id, Items: []entity.CartItem{}, CreatedAt: now}
+			if customerID != "" {
+				cart.CustomerID = &customerID
+			}
+		}
+
+		if !canAccessCart(*cart, customerID) {
+			return errCartNotFound
+		}
+
+		err := fn(cart)
+		if err != nil {
+			return err
+		}
+
+		if len(cart.Items) > cartMaxItems() {
+			return fmt.Errorf("cart must not contain more than %d items", cartMaxItems())
+		}
+
+		cart.UpdatedAt = now
+		return nil
+	})
+}
+
+// checkItems memastikan produk dan varian yang dimasukkan ke keranjang ada.
+// Stok tidak diperiksa di sini karena selalu dihitung ulang saat dibaca.
+func (u *cartUsecase) checkItems(c context.Context, items []dto.ReqCartItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	productIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	products, err := u.productRepo.GetByIDs(c, uniqueStrings(productIDs))
+	if err != nil {
+		return err
+	}
+
+	variants, err := u.variantRepo.GetByProductIDs(c, productIDs)
+	if err != nil {
+		return err
+	}
+
+	exists := make(map[string]bool, len(products))
+	for _, product := range products {
+		exists[product.ID] = true
+	}
+
+	variantProduct := make(map[string]string, len(variants))
+	hasVariants := make(map[string]bool)
+	for _, variant := range variants {
+		variantProduct[variant.ID] = variant.ProductID
+		hasVariants[variant.ProductID] = true
+	}
+
+	for _, item := range items {
+		if !exists[item.ProductID] {
+			return fmt.Errorf("product with ID %s not found", item.ProductID)
+		}
+		if item.VariantID != "" && variantProduct[item.VariantID] != item.ProductID {
+			return fmt.Errorf("variant with ID %s not found for product %s", item.VariantID, item.ProductID)
+		}
+		if item.VariantID == "" && hasVariants[item.ProductID] {
+			return fmt.Errorf("product with ID %s requires a variant", item.ProductID)
+		}
+	}
+
+	return nil
+}
+
+// view menghitung ulang harga dan ketersediaan setiap barang dari data
+// produk terbaru
+func (u *cartUsecase) view(c context.Context, cart entity.Cart) (dto.ResCart, error) {
+	currency, err := checkoutCurrency(cart.Currency)
+	if err != nil {
+		return dto.ResCart{}, err
+	}
+
+	result := dto.ResCart{
+		ID:          cart.ID,
+		CustomerID:  cart.CustomerID,
+		Currency:    currency,
+		PromoCode:   cart.PromoCode,
+		Items:       []dto.ResCartItem{},
+		Subtotal:    entity.NewMoney(0, currency),
+		CanCheckout: len(cart.Items) > 0,
+		CreatedAt:   cart.CreatedAt,
+		UpdatedAt:   cart.UpdatedAt,
+		ExpiresAt:   cart.ExpiresAt,
+	}
+
+	productIDs := make([]string, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	productIDs = uniqueStrings(productIDs)
+
+	products, err := u.productRepo.GetByIDs(c, productIDs)
+	if err != nil {
+		return result, err
+	}
+
+	productMap := make(map[string]entity.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+	}
+
+	variants, err := u.variantRepo.GetByProductIDs(c, productIDs)
+	if err != nil {
+		return result, err
+	}
+
+	variantMap := make(map[string]entity.ProductVariant, len(variants))
+	hasVariants := make(map[string]bool)
+	for _, variant := range variants {
+		variantMap[variant.ID] = variant
+		hasVariants[variant.ProductID] = true
+	}
+
+	priceList := make(map[string]entity.Money)
+	if currency != entity.BaseCurrency() {
+		prices, err := u.productRepo.GetPricesByCurrency(c, productIDs, currency)
+		if err != nil {
+			return result, err
+		}
+
+		for _, price := range prices {
+			if price.VariantID != nil {
+				priceList[*price.VariantID] = price.Price
+			} else {
+				priceList[price.ProductID] = price.Price
+			}
+		}
+	}
+
+	for _, item := range cart.Items {
+		line := cartLine(item, productMap, variantMap, hasVariants, currency, priceList)
+		result.ItemCount += int64(item.Quantity)
+
+		if !line.Available {
+			result.CanCheckout = false
+		} else {
+			result.Subtotal, err = result.Subtotal.Add(*line.Total)
+			if err != nil {
+				return result, err
+			}
+		}
+
+		result.Items = append(result.Items, line)
+	}
+
+	return result, nil
+}
+
+// cartLine mengisi data produk terbaru untuk satu barang di keranjang
+func cartLine(item entity.CartItem, productMap map[string]entity.Product, variantMap map[string]entity.ProductVariant, hasVariants map[string]bool, currency string, priceList map[string]entity.Money) dto.ResCartItem {
+	line := dto.ResCartItem{
+		ProductID: item.ProductID,
+		VariantID: item.VariantID,
+		Quantity:  item.Quantity,
+	}
+
+	product, exists := productMap[item.ProductID]
+	if !exists {
+		line.Issue = "product is no longer available"
+		return line
+	}
+	line.Name = product.Name
+	line.SKU = product.SKU
+	line.Stock = product.Stock
+
+	var variant *entity.ProductVariant
+	if item.VariantID != "" {
+		found, exists := variantMap[item.VariantID]
+		if !exists || found.ProductID != product.ID {
+			line.Issue = "variant is no longer available"
+			return line
+		}
+		variant = &found
+		line.SKU = &found.SKU
+		line.Options = found.Options
+		line.Stock = found.Stock
+	} else if hasVariants[product.ID] {
+		line.Issue = "product requires a variant"
+		return line
+	}
+
+	price, ok := linePrice(product, variant, currency, priceList)
+	if !ok {
+		line.Issue = "product is not available in " + currency
+		return line
+	}
+	line.Price = &price
+
+	total, err := price.Mul(int64(item.Quantity))
+	if err != nil {
+		line.Issue = err.Error()
+		return line
+	}
+	line.Total = &total
+
+	switch {
+	case line.Stock <= 0:
+		line.Issue = "out of stock"
+	case line.Stock < int64(item.Quantity):
+		line.Issue = fmt.Sprintf("only %d left in stock", line.Stock)
+	default:
+		line.Available = true
+	}
+
+	return line
+}
+
+// canAccessCart melaporkan apakah keranjang boleh diakses oleh customerID.
+// Keranjang tamu boleh diakses siapa saja yang mengetahui id-nya.
+func canAccessCart(cart entity.Cart, customerID string) bool {
+	return cart.CustomerID == nil || *cart.CustomerID == customerID
+}
+
+// cartMaxItems membaca batas jumlah baris keranjang, minimal 1
+func cartMaxItems() int {
+	limit := viper.GetInt("CART_MAX_ITEMS")
+	if limit < 1 {
+		return 100
+	}
+	return limit
+}
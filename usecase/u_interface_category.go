@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/repository"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+type CategoryUsecase interface {
+	GetTree(c context.Context) ([]entity.CategoryTree, error)
+	GetByID(c context.Context, id string) (entity.Category, error)
+	Create(c context.Context, input dto.ReqCategory) (entity.Category, error)
+	Update(c context.Context, id string, input dto.ReqCategory) (entity.Category, error)
+	Delete(c context.Context, id string) error
+	GetProductCategories(c context.Context, productID string) ([]entity.Category, error)
+	SetProductCategories(c context.Context, productID string, input dto.ReqProductCategories) ([]entity.Category, error)
+}
+
+type categoryUsecase struct {
+	repo        repository.CategoryRepository
+	productRepo repository.Repository
+}
+
+func NewCategoryUsecase(repo repository.CategoryRepository, productRepo repository.Repository) CategoryUsecase {
+	return &categoryUsecase{repo, productRepo}
+}
+
+func (u *categoryUsecase) GetTree(c context.Context) ([]entity.CategoryTree, error) {
+	categories, err := u.repo.GetAll(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Kategori sudah urut berdasarkan sort_order, urutan anak ikut terjaga
+	children := make(map[string][]entity.Category)
+	var roots []entity.Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	var build func(nodes []entity.Category) []entity.CategoryTree
+	build = func(nodes []entity.Category) []entity.CategoryTree {
+		tree := make([]entity.CategoryTree, 0, len(nodes))
+		for _, node := range nodes {
+			tree = append(tree, entity.CategoryTree{
+				Category: node,
+				Children: build(children[node.ID]),
+			})
+		}
+		return tree
+	}
+
+	return build(roots), nil
+}
+
+func (u *categoryUsecase) GetByID(c context.Context, id string) (entity.Category, error) {
+	category, err := u.repo.GetByID(c, id)
+	if err != nil {
+		return category, err
+	}
+
+	if category.ID != id {
+		return category, errors.New("category not found")
+	}
+
+	return category, nil
+}
+
+func (u *categoryUsecase) Create(c context.Context, input dto.ReqCategory) (entity.Category, error) {
+	category := entity.Category{
+		ID:        uuid.NewString(),
+		Name:      input.Name,
+		SortOrder: input.SortOrder,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	err := u.applyInput(c, &category, input)
+	if err != nil {
+		return category, err
+	}
+
+	result, err := u.repo.Create(c, category)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (u *categoryUsecase) Update(c context.Context, id string, input dto.ReqCategory) (entity.Category, error) {
+	category, err := u.GetByID(c, id)
+	if err != nil {
+		return category, err
+	}
+
+	category.Name = input.Name
+	category.SortOrder = input.SortOrder
+	category.UpdatedAt = time.Now()
+
+	err = u.applyInput(c, &category, input)
+	if err != nil {
+		return category, err
+	}
+
+	result, err := u.repo.Update(c, category)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (u *categoryUsecase) Delete(c context.Context, id string) error {
+	_, err := u.GetByID(c, id)
+	if err != nil {
+		return err
+	}
+
+	children, err := u.repo.CountChildren(c, id)
+	if err != nil {
+		return err
+	}
+
+	if children > 0 {
+		return errors.New("category still has subcategories")
+	}
+
+	return u.repo.Delete(c, id)
+}
+
+func (u *categoryUsecase) GetProductCategories(c context.Context, productID string) ([]entity.Category, error) {
+	product, err := u.productRepo.GetByID(c, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.ID != productID {
+		return nil, errors.New("product not found")
+	}
+
+	result, err := u.repo.GetByProductID(c, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (u *categoryUsecase) SetProductCategories(c context.Context, productID string, input dto.ReqProductCategories) ([]entity.Category, error) {
+	product, err := u.productRepo.GetByID(c, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.ID != productID {
+		return nil, errors.New("product not found")
+	}
+
+	// Menghapus id ganda
+	seen := make(map[string]bool)
+	categoryIDs := make([]string, 0, len(input.CategoryIDs))
+	for _, id := range input.CategoryIDs {
+		if !seen[id] {
+			seen[id] = true
+			categoryIDs = append(categoryIDs, id)
+		}
+	}
+
+	categories, err := u.repo.GetByIDs(c, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(categories) != len(categoryIDs) {
+		return nil, errors.New("one or more categories not found")
+	}
+
+	err = u.repo.SetProductCategories(c, productID, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// applyInput memvalidasi slug dan parent lalu menerapkannya ke kategori
+func (u *categoryUsecase) applyInput(c context.Context, category *entity.Category, input dto.ReqCategory) error {
+	slug := input.Slug
+	if slug == "" {
+		slug = input.Name
+	}
+	slug = slugify(slug)
+	if slug == "" {
+		return errors.New("slug must contain at least one letter or digit")
+	}
+
+	existing, err := u.repo.GetBySlug(c, slug)
+	if err != nil {
+		return err
+	}
+
+	if existing.ID != "" && existing.ID != category.ID {
+		return errors.New("slug is already used by another category")
+	}
+
+	category.Slug = slug
+	category.ParentID = nil
+
+	if input.ParentID == nil || *input.ParentID == "" {
+		return nil
+	}
+
+	parentID := *input.ParentID
+	if parentID == category.ID {
+		return errors.New("category cannot be its own parent")
+	}
+
+	parent, err := u.repo.GetByID(c, parentID)
+	if err != nil {
+		return err
+	}
+
+	if parent.ID != parentID {
+		return errors.New("parent category not found")
+	}
+
+	// Parent tidak boleh berasal dari sub-kategori sendiri agar tidak terjadi
+	// siklus. Repository memeriksa ulang di bawah lock saat menyimpan.
+	descendants, err := u.repo.GetDescendantIDs(c, category.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range descendants {
+		if id == parentID {
+			return repository.ErrCategoryCycle
+		}
+	}
+
+	category.ParentID = &parentID
+	return nil
+}
+
+// slugify mengubah teks menjadi slug huruf kecil yang dipisah tanda hubung
+func slugify(value string) string {
+	var builder strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(value) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			builder.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && builder.Len() > 0 {
+			builder.WriteRune('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(builder.String(), "-")
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Electronics", "electronics"},
+		{"Home & Living", "home-living"},
+		{"  Men's   Shoes  ", "men-s-shoes"},
+		{"Laptop 14 inch", "laptop-14-inch"},
+		{"--Sale--", "sale"},
+		{"Kopi Susu!", "kopi-susu"},
+		{"Café Crème", "caf-cr-me"},
+		{"日本", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		got := slugify(tt.value)
+		if got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"online-shop/auth"
+	"online-shop/mailer"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type CustomerUsecase interface {
+	Register(c context.Context, input dto.ReqRegister) (entity.Customer, error)
+	VerifyEmail(c context.Context, token string) (entity.Customer, error)
+	Login(c context.Context, input dto.ReqLogin) (dto.ResToken, error)
+	Refresh(c context.Context, refreshToken string) (dto.ResToken, error)
+	Logout(c context.Context, accessClaims auth.Claims, refreshToken string) error
+	GetProfile(c context.Context, id string) (entity.Customer, error)
+}
+
+var errInvalidCredentials = errors.New("invalid email or password")
+
+type customerUsecase struct {
+	repo      repository.CustomerRepository
+	tokenRepo repository.TokenRepository
+	cartRepo  repository.CartRepository
+	mailer    mailer.Mailer
+}
+
+func NewCustomerUsecase(repo repository.CustomerRepository, tokenRepo repository.TokenRepository, cartRepo repository.CartRepository, mailer mailer.Mailer) CustomerUsecase {
+	return &customerUsecase{repo, tokenRepo, cartRepo, mailer}
+}
+
+func (u *customerUsecase) Register(c context.Context, input dto.ReqRegister) (entity.Customer, error) {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return entity.Customer{}, err
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return entity.Customer{}, err
+	}
+
+	// Yang disimpan hanya hash dari token verifikasi
+	tokenHash := hashToken(token)
+	customer := entity.Customer{
+		ID:                uuid.NewString(),
+		Email:             input.Email,
+		PasswordHash:      string(passwordHash),
+		VerificationToken: &tokenHash,
+		CreatedAt:         time.Now(),
+	}
+
+	result, err := u.repo.Create(c, customer)
+	if err != nil {
+		return result, err
+	}
+
+	err = u.mailer.Send(c, result.Email, "Verify your email", viper.GetString("CUSTOMER_VERIFY_URL")+token)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (u *customerUsecase) VerifyEmail(c context.Context, token string) (entity.Customer, error) {
+	customer, err := u.repo.GetByVerificationToken(c, hashToken(token))
+	if err != nil {
+		return customer, err
+	}
+
+	if customer.ID == "" {
+		return customer, errors.New("invalid verification token")
+	}
+
+	currentTime := time.Now()
+	customer.EmailVerifiedAt = &currentTime
+
+	result, err := u.repo.MarkVerified(c, customer)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (u *customerUsecase) Login(c context.Context, input dto.ReqLogin) (dto.ResToken, error) {
+	customer, err := u.repo.GetByEmail(c, input.Email)
+	if err != nil {
+		return dto.ResToken{}, err
+	}
+
+	// bcrypt tetap dijalankan walaupun email tidak terdaftar agar waktu
+	// respons tidak membocorkan email mana yang terdaftar
+	passwordHash := customer.PasswordHash
+	if customer.ID == "" {
+		passwordHash = dummyPasswordHash
+	}
+
+	errPass := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(input.Password))
+	if errPass != nil || customer.ID == "" {
+		return dto.ResToken{}, errInvalidCredentials
+	}
+
+	if customer.EmailVerifiedAt == nil {
+		return dto.ResToken{}, errors.New("email has not been verified")
+	}
+
+	result, err := u.issueTokens(customer.ID)
+	if err != nil {
+		return result, err
+	}
+
+	// Gagal menggabungkan keranjang tidak menggagalkan login, keranjang tamu
+	// tetap bisa dipakai dengan id yang sama
+	cart, err := u.cartRepo.Merge(c, input.CartID, customer.ID)
+	if err != nil {
+		log.Println("error merge cart:", input.CartID, err)
+		cart.ID = input.CartID
+	}
+	result.CartID = cart.ID
+
+	return result, nil
+}
+
+func (u *customerUsecase) Refresh(c context.Context, refreshToken string) (dto.ResToken, error) {
+	claims, err := auth.ParseToken(viper.GetString("CUSTOMER_JWT_SECRET"), auth.AudienceCustomer, refreshToken, auth.TokenTypeRefresh)
+	if err != nil {
+		return dto.ResToken{}, err
+	}
+
+	// Refresh token hanya boleh dipakai sekali, request kedua dengan token
+	// yang sama dianggap pemakaian ulang walaupun berjalan bersamaan
+	consumed, err := u.tokenRepo.Consume(c, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return dto.ResToken{}, err
+	}
+
+	if !consumed {
+		return dto.ResToken{}, auth.ErrInvalidToken
+	}
+
+	return u.issueTokens(claims.Subject)
+}
+
+func (u *customerUsecase) Logout(c context.Context, accessClaims auth.Claims, refreshToken string) error {
+	err := u.tokenRepo.Revoke(c, accessClaims.ID, accessClaims.ExpiresAt.Time)
+	if err != nil {
+		return err
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	claims, err := auth.ParseToken(viper.GetString("CUSTOMER_JWT_SECRET"), auth.AudienceCustomer, refreshToken, auth.TokenTypeRefresh)
+	if err != nil {
+		return err
+	}
+
+	if claims.Subject != accessClaims.Subject {
+		return auth.ErrInvalidToken
+	}
+
+	return u.tokenRepo.Revoke(c, claims.ID, claims.ExpiresAt.Time)
+}
+
+func (u *customerUsecase) GetProfile(c context.Context, id string) (entity.Customer, error) {
+	customer, err := u.repo.GetByID(c, id)
+	if err != nil {
+		return customer, err
+	}
+
+	if customer.ID != id {
+		return customer, errors.New("customer not found")
+	}
+
+	return customer, nil
+}
+
+func (u *customerUsecase) issueTokens(customerID string) (dto.ResToken, error) {
+	secret := viper.GetString("CUSTOMER_JWT_SECRET")
+	accessTTL := viper.GetDuration("CUSTOMER_ACCESS_TOKEN_TTL")
+
+	accessToken, _, err := auth.GenerateToken(secret, auth.AudienceCustomer, customerID, auth.TokenTypeAccess, accessTTL)
+	if err != nil {
+		return dto.ResToken{}, err
+	}
+
+	refreshToken, _, err := auth.GenerateToken(secret, auth.AudienceCustomer, customerID, auth.TokenTypeRefresh, viper.GetDuration("CUSTOMER_REFRESH_TOKEN_TTL"))
+	if err != nil {
+		return dto.ResToken{}, err
+	}
+
+	result := dto.ResToken{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(accessTTL.Seconds()),
+	}
+
+	return result, nil
+}
+
+// dummyPasswordHash adalah hash bcrypt dari password acak, hanya untuk
+// menyamakan waktu login ketika email tidak terdaftar
+const dummyPasswordHash = "$2a$10$7JBmjBZMx4mhUYS00LL5x.Ky0WVcMxwSRbFR/NeKCMTq2z/1ub.WS"
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
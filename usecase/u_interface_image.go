@@ -0,0 +1,318 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"online-shop/imaging"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/repository"
+	"online-shop/storage"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type ImageUsecase interface {
+	GetByProductID(c context.Context, productID string) ([]entity.ProductImage, error)
+	Upload(c context.Context, productID string, files []*multipart.FileHeader) ([]entity.ProductImage, error)
+	Delete(c context.Context, productID string, id string) (entity.ProductImage, error)
+	Reorder(c context.Context, productID string, input dto.ReqImageOrder) ([]entity.ProductImage, error)
+}
+
+// Format gambar yang diterima beserta ekstensi filenya
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Batas dimensi untuk mencegah gambar kecil yang memakan memori besar saat di-decode
+const maxImageDimension = 8000
+
+type imageUsecase struct {
+	repo        repository.ImageRepository
+	productRepo repository.Repository
+	storage     storage.Storage
+}
+
+func NewImageUsecase(repo repository.ImageRepository, productRepo repository.Repository, storage storage.Storage) ImageUsecase {
+	return &imageUsecase{repo, productRepo, storage}
+}
+
+func (u *imageUsecase) GetByProductID(c context.Context, productID string) ([]entity.ProductImage, error) {
+	err := u.checkProduct(c, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := u.repo.GetByProductID(c, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (u *imageUsecase) Upload(c context.Context, productID string, files []*multipart.FileHeader) ([]entity.ProductImage, error) {
+	if len(files) == 0 {
+		return nil, errors.New("images must not be empty")
+	}
+
+	existing, err := u.GetByProductID(c, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxCount := viper.GetInt("PRODUCT_IMAGE_MAX_COUNT"); len(existing)+len(files) > maxCount {
+		return nil, fmt.Errorf("a product can have at most %d images", maxCount)
+	}
+
+	position := 0
+	for _, image := range existing {
+		position = max(position, image.Position+1)
+	}
+
+	// Semua file divalidasi lebih dulu agar upload tidak tersimpan sebagian.
+	// Validasi hanya membaca header gambar, decode penuh dilakukan satu per
+	// satu saat disimpan agar memori tidak bertambah sesuai jumlah file.
+	for _, file := range files {
+		_, err := readImage(file)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	images := make([]entity.ProductImage, 0, len(files))
+	var storedKeys []string
+	for i, file := range files {
+		image, err := u.store(c, productID, file, position+i)
+		if err != nil {
+			u.removeKeys(c, storedKeys)
+			return nil, err
+		}
+		storedKeys = append(storedKeys, image.StorageKey, image.ThumbnailKey)
+		images = append(images, image)
+	}
+
+	// Create hanya gagal jika data gambar tidak tersimpan, sehingga file
+	// yang sudah diunggah aman dihapus
+	result, err := u.repo.Create(c, images)
+	if err != nil {
+		u.removeKeys(c, storedKeys)
+		return nil, err
+	}
+
+	// Jika upload berjalan di dalam transaksi yang kemudian dibatalkan, file
+	// yang sudah diunggah tidak lagi dirujuk database
+	repository.AfterRollback(c, func(c context.Context) {
+		u.removeKeys(c, storedKeys)
+	})
+
+	return result, nil
+}
+
+func (u *imageUsecase) Delete(c context.Context, productID string, id string) (entity.ProductImage, error) {
+	images, err := u.GetByProductID(c, productID)
+	if err != nil {
+		return entity.ProductImage{}, err
+	}
+
+	for _, image := range images {
+		if image.ID != id {
+			continue
+		}
+
+		err := u.repo.Delete(c, image)
+		if err != nil {
+			return image, err
+		}
+
+		// File dihapus setelah data di database di-commit, file yang tertinggal
+		// hanya memakan tempat
+		repository.AfterCommit(c, func(c context.Context) error {
+			u.removeKeys(c, []string{image.StorageKey, image.ThumbnailKey})
+			return nil
+		})
+		return image, nil
+	}
+
+	return entity.ProductImage{}, errors.New("image not found")
+}
+
+func (u *imageUsecase) Reorder(c context.Context, productID string, input dto.ReqImageOrder) ([]entity.ProductImage, error) {
+	images, err := u.GetByProductID(c, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(input.ImageIDs) != len(images) {
+		return nil, errors.New("imageIds must contain every image of the product exactly once")
+	}
+
+	imageMap := make(map[string]bool, len(images))
+	for _, image := range images {
+		imageMap[image.ID] = true
+	}
+
+	for _, id := range input.ImageIDs {
+		if !imageMap[id] {
+			return nil, errors.New("imageIds must contain every image of the product exactly once")
+		}
+		delete(imageMap, id)
+	}
+
+	err = u.repo.Reorder(c, productID, input.ImageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := u.repo.GetByProductID(c, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (u *imageUsecase) checkProduct(c context.Context, productID string) error {
+	product, err := u.productRepo.GetByID(c, productID)
+	if err != nil {
+		return err
+	}
+
+	if product.ID != productID {
+		return errors.New("product not found")
+	}
+
+	return nil
+}
+
+// store membaca, membuat thumbnail, dan menyimpan satu file gambar beserta
+// thumbnail-nya ke storage
+func (u *imageUsecase) store(c context.Context, productID string, file *multipart.FileHeader, position int) (entity.ProductImage, error) {
+	upload, err := readImage(file)
+	if err != nil {
+		return entity.ProductImage{}, err
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(upload.data))
+	if err != nil {
+		return entity.ProductImage{}, fmt.Errorf("%s is not a valid image", file.Filename)
+	}
+
+	id := uuid.NewString()
+	extension := imageExtensions[upload.contentType]
+
+	image := entity.ProductImage{
+		ID:          id,
+		ProductID:   productID,
+		StorageKey:  "products/" + productID + "/" + id + extension,
+		ContentType: upload.contentType,
+		Size:        int64(len(upload.data)),
+		Width:       decoded.Bounds().Dx(),
+		Height:      decoded.Bounds().Dy(),
+		Position:    position,
+		CreatedAt:   time.Now(),
+	}
+
+	// Thumbnail PNG mempertahankan transparansi, selain itu memakai JPEG
+	var thumbnail bytes.Buffer
+	thumbnailType := "image/jpeg"
+	resized := imaging.Thumbnail(decoded, viper.GetInt("PRODUCT_IMAGE_THUMBNAIL_SIZE"))
+	if upload.contentType == "image/jpeg" {
+		err := jpeg.Encode(&thumbnail, resized, &jpeg.Options{Quality: 85})
+		if err != nil {
+			return image, err
+		}
+	} else {
+		thumbnailType = "image/png"
+		err := png.Encode(&thumbnail, resized)
+		if err != nil {
+			return image, err
+		}
+	}
+	image.ThumbnailKey = "products/" + productID + "/" + id + "_thumb" + imageExtensions[thumbnailType]
+
+	err = u.storage.Put(c, image.StorageKey, bytes.NewReader(upload.data), upload.contentType)
+	if err != nil {
+		return image, err
+	}
+
+	err = u.storage.Put(c, image.ThumbnailKey, &thumbnail, thumbnailType)
+	if err != nil {
+		u.removeKeys(c, []string{image.StorageKey})
+		return image, err
+	}
+
+	image.URL = u.storage.URL(image.StorageKey)
+	image.ThumbnailURL = u.storage.URL(image.ThumbnailKey)
+
+	return image, nil
+}
+
+func (u *imageUsecase) removeKeys(c context.Context, keys []string) {
+	for _, key := range keys {
+		err := u.storage.Delete(c, key)
+		if err != nil {
+			log.Println("error delete file from storage:", key, err)
+		}
+	}
+}
+
+type imageUpload struct {
+	data        []byte
+	contentType string
+}
+
+// readImage membaca file upload dan memvalidasi ukuran, jenis, dan dimensinya
+// tanpa men-decode seluruh gambar
+func readImage(file *multipart.FileHeader) (imageUpload, error) {
+	maxSize := viper.GetInt64("PRODUCT_IMAGE_MAX_SIZE")
+	if file.Size > maxSize {
+		return imageUpload{}, fmt.Errorf("%s exceeds the maximum size of %d bytes", file.Filename, maxSize)
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return imageUpload{}, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return imageUpload{}, err
+	}
+
+	if int64(len(data)) > maxSize {
+		return imageUpload{}, fmt.Errorf("%s exceeds the maximum size of %d bytes", file.Filename, maxSize)
+	}
+
+	// Jenis file ditentukan dari isinya, bukan dari nama atau header client
+	contentType := http.DetectContentType(data)
+	if _, ok := imageExtensions[contentType]; !ok {
+		return imageUpload{}, fmt.Errorf("%s is not a JPEG, PNG or GIF image", file.Filename)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return imageUpload{}, fmt.Errorf("%s is not a valid image", file.Filename)
+	}
+
+	if config.Width > maxImageDimension || config.Height > maxImageDimension {
+		return imageUpload{}, fmt.Errorf("%s exceeds the maximum dimension of %dx%d", file.Filename, maxImageDimension, maxImageDimension)
+	}
+
+	return imageUpload{data: data, contentType: contentType}, nil
+}
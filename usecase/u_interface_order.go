@@ -3,27 +3,196 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"math"
+	"online-shop/model/dto"
 	"online-shop/model/entity"
+	"online-shop/payment"
 	"online-shop/repository"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type OrderUsecase interface {
-	Confirm(c context.Context, id string, input entity.Confirm) (entity.Order, error)
-	GetDetailOrder(c context.Context, id string, passcode string) (entity.OrderWithDetail, error)
+	Pay(c context.Context, id string, passcode string, clientIP string) (entity.Payment, error)
+	Confirm(c context.Context, id string, input entity.Confirm, clientIP string) (entity.Order, error)
+	HandlePaymentCallback(c context.Context, payload []byte, signature string) error
+	GetDetailOrder(c context.Context, id string, passcode string, customerID string, clientIP string) (entity.OrderWithDetail, error)
+	GetByID(c context.Context, id string) (entity.Order, error)
+	UpdateStatus(c context.Context, id string, input dto.ReqOrderStatus, actor string) (entity.Order, error)
+	GetStatusHistory(c context.Context, id string) ([]entity.OrderStatusHistory, error)
+	ExpireOrders(c context.Context, limit int) (int, error)
+	Cancel(c context.Context, id string, passcode string, clientIP string) (entity.Order, error)
+	Refund(c context.Context, id string, input dto.ReqRefund, audit entity.AuditLog) (entity.Refund, error)
+	GetRefunds(c context.Context, id string) ([]entity.Refund, error)
+	List(c context.Context, query dto.ReqOrderQuery) (dto.ResOrders, error)
 }
 
+// InvalidTransitionError dikembalikan ketika perpindahan status pesanan tidak diizinkan
+type InvalidTransitionError struct {
+	From string
+	To   string
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("order status cannot change from %s to %s", e.From, e.To)
+}
+
+// PasscodeLockedError dikembalikan ketika percobaan passcode sedang dikunci
+// karena terlalu banyak kegagalan
+type PasscodeLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *PasscodeLockedError) Error() string {
+	return fmt.Sprintf("too many failed passcode attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+var errInvalidPasscode = errors.New("invalid passcode")
+
 type orderUsecase struct {
-	repo repository.OrderRepository
+	repo         repository.OrderRepository
+	paymentRepo  repository.PaymentRepository
+	provider     payment.Provider
+	attemptRepo  repository.PasscodeAttemptRepository
+	auditUsecase AuditUsecase
 }
 
-func NewOrderUsecase(repo repository.OrderRepository) OrderUsecase {
-	return &orderUsecase{repo}
+func NewOrderUsecase(repo repository.OrderRepository, paymentRepo repository.PaymentRepository, provider payment.Provider, attemptRepo repository.PasscodeAttemptRepository, auditUsecase AuditUsecase) OrderUsecase {
+	return &orderUsecase{repo, paymentRepo, provider, attemptRepo, auditUsecase}
 }
 
-func (u *orderUsecase) Confirm(c context.Context, id string, input entity.Confirm) (entity.Order, error) {
+func (u *orderUsecase) Pay(c context.Context, id string, passcode string, clientIP string) (entity.Payment, error) {
+	order, err := u.getPendingOrder(c, id, passcode, clientIP)
+	if err != nil {
+		return entity.Payment{}, err
+	}
+
+	// Intent yang masih menunggu pembayaran dipakai ulang
+	latest, err := u.paymentRepo.GetLatestByOrderID(c, order.ID)
+	if err != nil {
+		return latest, err
+	}
+
+	if latest.ID != "" && latest.Status == payment.StatusPending && latest.Amount == order.GrandTotal {
+		return latest, nil
+	}
+
+	intent, err := u.provider.CreateIntent(c, order.ID, order.GrandTotal.Amount, order.GrandTotal.Currency)
+	if err != nil {
+		return entity.Payment{}, err
+	}
+
+	currentTime := time.Now()
+	record := entity.Payment{
+		ID:        uuid.NewString(),
+		OrderID:   order.ID,
+		Provider:  u.provider.Name(),
+		IntentID:  intent.ID,
+		Currency:  order.Currency,
+		Amount:    entity.NewMoney(intent.Amount, order.Currency),
+		Status:    intent.Status,
+		CreatedAt: currentTime,
+		UpdatedAt: currentTime,
+	}
+	if intent.PaymentURL != "" {
+		record.PaymentURL = &intent.PaymentURL
+	}
+
+	result, err := u.paymentRepo.Create(c, record)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (u *orderUsecase) Confirm(c context.Context, id string, input entity.Confirm, clientIP string) (entity.Order, error) {
+	order, err := u.getPendingOrder(c, id, input.Passcode, clientIP)
+	if err != nil {
+		return order, err
+	}
+
+	// Status pembayaran ditanyakan langsung ke provider, bukan dari input pelanggan
+	record, err := u.paymentRepo.GetLatestByOrderID(c, order.ID)
+	if err != nil {
+		return order, err
+	}
+
+	if record.ID == "" {
+		return order, errors.New("payment has not been created for this order")
+	}
+
+	intent, err := u.provider.GetStatus(c, record.IntentID)
+	if err != nil {
+		return order, err
+	}
+
+	result, err := u.applyPaymentStatus(c, order, record, intent.Status)
+	if err != nil {
+		return result, err
+	}
+
+	switch intent.Status {
+	case payment.StatusPending:
+		return result, errors.New("payment is still pending")
+	case payment.StatusFailed:
+		return result, errors.New("payment failed")
+	}
+
+	return result, nil
+}
+
+func (u *orderUsecase) HandlePaymentCallback(c context.Context, payload []byte, signature string) error {
+	event, err := u.provider.ParseCallback(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	record, err := u.paymentRepo.GetByIntentID(c, u.provider.Name(), event.IntentID)
+	if err != nil {
+		return err
+	}
+
+	// Callback yang sama bisa dikirim berkali-kali oleh provider
+	processed, err := u.paymentRepo.HasEvent(c, event.ID)
+	if err != nil || processed {
+		return err
+	}
+
+	order, err := u.repo.GetByID(c, record.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if order.ID != record.OrderID {
+		return errors.New("order not found")
+	}
+
+	order.Passcode = nil
+
+	// Event baru dicatat setelah statusnya berhasil diterapkan, jika gagal
+	// provider akan mengirim ulang dan applyPaymentStatus aman diulang
+	_, err = u.applyPaymentStatus(c, order, record, event.Status)
+	if err != nil {
+		return err
+	}
+
+	return u.paymentRepo.RecordEvent(c, entity.PaymentEvent{
+		ID:        event.ID,
+		PaymentID: record.ID,
+		Status:    event.Status,
+		CreatedAt: time.Now(),
+	})
+}
+
+// getPendingOrder mengambil pesanan yang masih menunggu pembayaran setelah
+// passcode diverifikasi
+func (u *orderUsecase) getPendingOrder(c context.Context, id string, passcode string, clientIP string) (entity.Order, error) {
 	order, err := u.repo.GetByID(c, id)
 	if err != nil {
 		return order, err
@@ -33,32 +202,510 @@ func (u *orderUsecase) Confirm(c context.Context, id string, input entity.Confir
 		return order, errors.New("order not found")
 	}
 
-	errPass := bcrypt.CompareHashAndPassword([]byte(*order.Passcode), []byte(input.Passcode))
+	errPass := u.verifyPasscode(c, order, passcode, clientIP)
 	if errPass != nil {
-		return order, errors.New("invalid passcode")
+		return order, errPass
 	}
 
-	if order.GrandTotal != input.Amount {
-		return order, errors.New("total amount mismatch: access to orders is not allowed")
+	order.Passcode = nil
+
+	if order.Status != entity.OrderStatusPending {
+		return order, &InvalidTransitionError{From: order.Status, To: entity.OrderStatusPaid}
+	}
+
+	// Pesanan yang melewati batas waktu pembayaran langsung dibuat kedaluwarsa
+	if order.ExpiresAt != nil && time.Now().After(*order.ExpiresAt) {
+		_, errExpire := u.transition(c, order, entity.OrderStatusExpired, "system", "payment window elapsed")
+		if errExpire != nil {
+			return order, errExpire
+		}
+		return order, errors.New("order has expired")
+	}
+
+	return order, nil
+}
+
+// applyPaymentStatus menyimpan status pembayaran terbaru dan memindahkan
+// pesanan ke status paid jika pembayaran berhasil
+func (u *orderUsecase) applyPaymentStatus(c context.Context, order entity.Order, record entity.Payment, status string) (entity.Order, error) {
+	if record.Status != status {
+		// Hanya pembayaran yang masih pending yang boleh berubah status
+		if record.Status != payment.StatusPending {
+			return order, nil
+		}
+
+		from := record.Status
+		record.Status = status
+		record.UpdatedAt = time.Now()
+
+		_, err := u.paymentRepo.UpdateStatus(c, record, from)
+		if errors.Is(err, repository.ErrPaymentStatusConflict) {
+			// Sudah diproses oleh request lain
+			return order, nil
+		}
+		if err != nil {
+			return order, err
+		}
+	}
+
+	if status != payment.StatusSucceeded || order.Status == entity.OrderStatusPaid {
+		return order, nil
+	}
+
+	if order.Status != entity.OrderStatusPending || record.Amount != order.GrandTotal {
+		// Pembayaran masuk untuk pesanan yang tidak bisa dibayar lagi, dana dikembalikan
+		_, err := u.provider.Refund(c, record.IntentID, record.Amount.Amount)
+		if err != nil {
+			return order, err
+		}
+
+		record.Status = payment.StatusRefunded
+		record.UpdatedAt = time.Now()
+		_, err = u.paymentRepo.UpdateStatus(c, record, payment.StatusSucceeded)
+		if err != nil {
+			return order, err
+		}
+
+		return order, fmt.Errorf("payment cannot be applied to %s order and has been refunded", order.Status)
 	}
 
 	currentTime := time.Now()
+	order.PaidAt = &currentTime
+	order.PaidBank = &record.Provider
+	order.PaidAccount = &record.IntentID
+
+	return u.transition(c, order, entity.OrderStatusPaid, "payment:"+record.Provider, "")
+}
+
+// GetByID mengambil pesanan tanpa verifikasi passcode, hanya untuk admin
+func (u *orderUsecase) GetByID(c context.Context, id string) (entity.Order, error) {
+	order, err := u.repo.GetByID(c, id)
+	if err != nil {
+		return order, err
+	}
+
+	if order.ID != id {
+		return order, errors.New("order not found")
+	}
 
 	order.Passcode = nil
-	order.PaidAt = &currentTime
-	order.PaidAccount = &input.AccountNumber
-	order.PaidBank = &input.Bank
-	order.GrandTotal = input.Amount
 
-	update, errUpdate := u.repo.Update(c, order)
-	if errUpdate != nil {
-		return update, errUpdate
+	return order, nil
+}
+
+func (u *orderUsecase) UpdateStatus(c context.Context, id string, input dto.ReqOrderStatus, actor string) (entity.Order, error) {
+	order, err := u.repo.GetByID(c, id)
+	if err != nil {
+		return order, err
+	}
+
+	if order.ID != id {
+		return order, errors.New("order not found")
+	}
+
+	order.Passcode = nil
+
+	result, err := u.transition(c, order, input.Status, actor, input.Note)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (u *orderUsecase) GetStatusHistory(c context.Context, id string) ([]entity.OrderStatusHistory, error) {
+	order, err := u.repo.GetByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.ID != id {
+		return nil, errors.New("order not found")
+	}
+
+	result, err := u.repo.GetStatusHistory(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (u *orderUsecase) Cancel(c context.Context, id string, passcode string, clientIP string) (entity.Order, error) {
+	order, err := u.repo.GetByID(c, id)
+	if err != nil {
+		return order, err
+	}
+
+	if order.ID != id {
+		return order, errors.New("order not found")
 	}
 
-	return update, nil
+	errPass := u.verifyPasscode(c, order, passcode, clientIP)
+	if errPass != nil {
+		return order, errPass
+	}
+
+	order.Passcode = nil
+
+	// Pelanggan hanya bisa membatalkan pesanan yang belum dibayar
+	result, err := u.transition(c, order, entity.OrderStatusCancelled, "customer", "cancelled by customer")
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
 }
 
-func (u *orderUsecase) GetDetailOrder(c context.Context, id string, passcode string) (entity.OrderWithDetail, error) {
+// Refund mengembalikan dana lewat payment provider. audit adalah entri audit
+// admin yang melakukan refund, dicatat dalam transaksi yang sama dengan status
+// akhir refund.
+func (u *orderUsecase) Refund(c context.Context, id string, input dto.ReqRefund, audit entity.AuditLog) (entity.Refund, error) {
+	order, err := u.repo.GetByID(c, id)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+
+	if order.ID != id {
+		return entity.Refund{}, errors.New("order not found")
+	}
+
+	order.Passcode = nil
+
+	if !entity.CanTransition(order.Status, entity.OrderStatusRefunded) {
+		return entity.Refund{}, fmt.Errorf("%s order cannot be refunded", order.Status)
+	}
+
+	record, err := u.paymentRepo.GetLatestByOrderID(c, order.ID)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+
+	if record.ID == "" || record.Status != payment.StatusSucceeded {
+		return entity.Refund{}, errors.New("order has no settled payment to refund")
+	}
+
+	details, err := u.repo.GetDetailOrders(c, order.ID)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+
+	refunds, err := u.repo.GetRefunds(c, order.ID)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+
+	// Jumlah dan nominal yang sudah direfund per baris pesanan
+	refundedTotal := order.GrandTotal.Zero()
+	refundedShipping := order.GrandTotal.Zero()
+	refundedQty := make(map[string]int32)
+	refundedAmount := make(map[string]entity.Money)
+	for _, detail := range details {
+		refundedAmount[detail.ID] = order.GrandTotal.Zero()
+	}
+	for _, refund := range refunds {
+		if refund.Status == entity.RefundStatusFailed {
+			continue
+		}
+		refundedTotal, err = refundedTotal.Add(refund.Amount)
+		if err != nil {
+			return entity.Refund{}, err
+		}
+		refundedShipping, err = refundedShipping.Add(refund.ShippingAmount)
+		if err != nil {
+			return entity.Refund{}, err
+		}
+		for _, line := range refund.Lines {
+			refundedQty[line.OrderDetailID] += line.Quantity
+			refundedAmount[line.OrderDetailID], err = refundedAmount[line.OrderDetailID].Add(line.Amount)
+			if err != nil {
+				return entity.Refund{}, err
+			}
+		}
+	}
+
+	// Ongkos kirim setelah diskon adalah selisih GrandTotal dengan total detail
+	shippingRemaining, err := order.GrandTotal.Sub(refundedShipping)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+	detailMap := make(map[string]entity.OrderDetail, len(details))
+	for _, detail := range details {
+		detailMap[detail.ID] = detail
+		shippingRemaining, err = shippingRemaining.Sub(detail.Total)
+		if err != nil {
+			return entity.Refund{}, err
+		}
+	}
+
+	// Tanpa baris, seluruh sisa pesanan direfund
+	lines := input.Lines
+	if len(lines) == 0 {
+		for _, detail := range details {
+			remaining := detail.Quantity - refundedQty[detail.ID]
+			if remaining > 0 {
+				lines = append(lines, dto.ReqRefundLine{OrderDetailID: detail.ID, Quantity: remaining})
+			}
+		}
+	}
+
+	if len(lines) == 0 && !shippingRemaining.IsPositive() {
+		return entity.Refund{}, errors.New("order has been fully refunded")
+	}
+
+	refund := entity.Refund{
+		ID:        uuid.NewString(),
+		OrderID:   order.ID,
+		PaymentID: record.ID,
+		Currency:  order.Currency,
+		Amount:    order.GrandTotal.Zero(),
+		Reason:    input.Reason,
+		Status:    entity.RefundStatusPending,
+		Actor:     audit.Actor,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	var restock []entity.OrderDetail
+	for _, line := range lines {
+		detail, exists := detailMap[line.OrderDetailID]
+		if !exists {
+			return entity.Refund{}, fmt.Errorf("order detail with ID %s not found", line.OrderDetailID)
+		}
+
+		if line.Quantity > detail.Quantity-refundedQty[detail.ID] {
+			return entity.Refund{}, fmt.Errorf("refund quantity for order detail %s exceeds the remaining quantity", detail.ID)
+		}
+
+		remainingAmount, err := detail.Total.Sub(refundedAmount[detail.ID])
+		if err != nil {
+			return entity.Refund{}, err
+		}
+
+		// Nominal default mengikuti total baris setelah diskon promo
+		amount, err := detail.Total.MulDiv(int64(line.Quantity), int64(detail.Quantity))
+		if err != nil {
+			return entity.Refund{}, err
+		}
+		if line.Quantity == detail.Quantity-refundedQty[detail.ID] {
+			amount = remainingAmount
+		}
+		if line.Amount != nil {
+			amount = entity.NewMoney(*line.Amount, order.Currency)
+		}
+
+		if amount.Amount > remainingAmount.Amount {
+			return entity.Refund{}, fmt.Errorf("refund amount for order detail %s exceeds the remaining amount", detail.ID)
+		}
+
+		refundedQty[detail.ID] += line.Quantity
+		refundedAmount[detail.ID], err = refundedAmount[detail.ID].Add(amount)
+		if err != nil {
+			return entity.Refund{}, err
+		}
+		refund.Amount, err = refund.Amount.Add(amount)
+		if err != nil {
+			return entity.Refund{}, err
+		}
+
+		refund.Lines = append(refund.Lines, entity.RefundLine{
+			ID:            uuid.NewString(),
+			RefundID:      refund.ID,
+			OrderDetailID: detail.ID,
+			Quantity:      line.Quantity,
+			Currency:      order.Currency,
+			Amount:        amount,
+		})
+
+		if input.Restock {
+			returned := detail
+			returned.Quantity = line.Quantity
+			restock = append(restock, returned)
+		}
+	}
+
+	// Refund penuh ikut mengembalikan sisa ongkos kirim
+	refund.ShippingAmount = order.GrandTotal.Zero()
+	if len(input.Lines) == 0 && shippingRemaining.IsPositive() {
+		refund.ShippingAmount = shippingRemaining
+		refund.Amount, err = refund.Amount.Add(shippingRemaining)
+		if err != nil {
+			return entity.Refund{}, err
+		}
+	}
+
+	totalRefunded, err := refundedTotal.Add(refund.Amount)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+	if totalRefunded.Amount > record.Amount.Amount {
+		return entity.Refund{}, errors.New("refund amount exceeds the paid amount")
+	}
+
+	refund, err = u.repo.CreateRefund(c, refund, refundedTotal)
+	if err != nil {
+		return refund, err
+	}
+
+	providerRefund, errRefund := u.provider.Refund(c, record.IntentID, refund.Amount.Amount)
+
+	refund.UpdatedAt = time.Now()
+	if errRefund != nil {
+		refund.Status = entity.RefundStatusFailed
+		_, err = u.repo.UpdateRefund(c, refund, nil)
+		if err != nil {
+			return refund, err
+		}
+		return refund, errRefund
+	}
+
+	refund.Status = entity.RefundStatusSucceeded
+	refund.ProviderRefundID = &providerRefund.ID
+	err = u.auditUsecase.Transaction(c, func(c context.Context) error {
+		refund, err = u.repo.UpdateRefund(c, refund, restock)
+		if err != nil {
+			return err
+		}
+
+		// Pesanan yang sudah direfund penuh berpindah ke status refunded
+		if totalRefunded.Amount == record.Amount.Amount {
+			record.Status = payment.StatusRefunded
+			record.UpdatedAt = time.Now()
+			_, err = u.paymentRepo.UpdateStatus(c, record, payment.StatusSucceeded)
+			if err != nil {
+				return err
+			}
+
+			_, err = u.transition(c, order, entity.OrderStatusRefunded, audit.Actor, input.Reason)
+			if err != nil {
+				return err
+			}
+		}
+
+		return u.auditUsecase.Record(c, audit, nil, refund)
+	})
+	if err != nil {
+		return refund, err
+	}
+
+	return refund, nil
+}
+
+func (u *orderUsecase) GetRefunds(c context.Context, id string) ([]entity.Refund, error) {
+	order, err := u.repo.GetByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.ID != id {
+		return nil, errors.New("order not found")
+	}
+
+	result, err := u.repo.GetRefunds(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (u *orderUsecase) List(c context.Context, query dto.ReqOrderQuery) (dto.ResOrders, error) {
+	// Nilai default untuk pagination dan sorting
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+	if query.SortBy == "" {
+		query.SortBy = "created_at"
+	}
+	if query.Order == "" {
+		query.Order = "desc"
+	}
+
+	if query.MinTotal != nil && query.MaxTotal != nil && *query.MinTotal > *query.MaxTotal {
+		return dto.ResOrders{}, errors.New("min_total must not be greater than max_total")
+	}
+
+	if query.DateFrom != nil && query.DateTo != nil && query.DateFrom.After(*query.DateTo) {
+		return dto.ResOrders{}, errors.New("date_from must not be after date_to")
+	}
+
+	query.Currency = entity.NormalizeCurrency(query.Currency)
+	if query.Currency != "" && !entity.IsCurrency(query.Currency) {
+		return dto.ResOrders{}, errors.New("currency is not supported")
+	}
+
+	result, err := u.repo.List(c, query)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// ExpireOrders membuat kedaluwarsa pesanan pending yang sudah melewati batas
+// waktu pembayaran dan mengembalikan jumlah pesanan yang diproses
+func (u *orderUsecase) ExpireOrders(c context.Context, limit int) (int, error) {
+	orders, err := u.repo.GetExpiredOrders(c, time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, order := range orders {
+		_, err := u.transition(c, order, entity.OrderStatusExpired, "system", "payment window elapsed")
+		if errors.Is(err, repository.ErrOrderStatusConflict) {
+			// Sudah diproses oleh replika lain atau pelanggan baru saja membayar
+			continue
+		}
+		if err != nil && c.Err() != nil {
+			return expired, err
+		}
+		if err != nil {
+			// Satu pesanan yang gagal tidak boleh menghentikan pesanan lain,
+			// pesanan ini dicoba lagi pada interval berikutnya
+			log.Println("error expire order:", order.ID, err)
+			continue
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+// transition memindahkan pesanan ke status to dan mencatat riwayatnya.
+// Perubahan lain pada order (mis. data pembayaran) ikut disimpan.
+func (u *orderUsecase) transition(c context.Context, order entity.Order, to string, actor string, note string) (entity.Order, error) {
+	from := order.Status
+	if !entity.CanTransition(from, to) {
+		return order, &InvalidTransitionError{From: from, To: to}
+	}
+
+	history := entity.OrderStatusHistory{
+		ID:         uuid.NewString(),
+		OrderID:    order.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		Actor:      actor,
+		CreatedAt:  time.Now(),
+	}
+	if note != "" {
+		history.Note = &note
+	}
+
+	order.Status = to
+
+	// Pesanan yang batal atau kedaluwarsa mengembalikan stoknya
+	if to == entity.OrderStatusCancelled || to == entity.OrderStatusExpired {
+		return u.repo.UpdateStatusAndRestock(c, order, from, history)
+	}
+
+	return u.repo.UpdateStatus(c, order, from, history)
+}
+
+func (u *orderUsecase) GetDetailOrder(c context.Context, id string, passcode string, customerID string, clientIP string) (entity.OrderWithDetail, error) {
 	order, err := u.repo.GetByID(c, id)
 	if err != nil {
 		return entity.OrderWithDetail{}, err
@@ -68,9 +715,13 @@ func (u *orderUsecase) GetDetailOrder(c context.Context, id string, passcode str
 		return entity.OrderWithDetail{}, errors.New("order not found")
 	}
 
-	errPass := bcrypt.CompareHashAndPassword([]byte(*order.Passcode), []byte(passcode))
-	if errPass != nil {
-		return entity.OrderWithDetail{}, errors.New("invalid passcode")
+	// Pemilik akun tidak perlu passcode untuk melihat pesanannya sendiri
+	isOwner := customerID != "" && order.CustomerID != nil && *order.CustomerID == customerID
+	if !isOwner {
+		errPass := u.verifyPasscode(c, order, passcode, clientIP)
+		if errPass != nil {
+			return entity.OrderWithDetail{}, errPass
+		}
 	}
 
 	order.Passcode = nil
@@ -80,10 +731,126 @@ func (u *orderUsecase) GetDetailOrder(c context.Context, id string, passcode str
 		return entity.OrderWithDetail{}, errDetails
 	}
 
+	discounts, errDiscounts := u.repo.GetDiscounts(c, id)
+	if errDiscounts != nil {
+		return entity.OrderWithDetail{}, errDiscounts
+	}
+
 	orderWithDetail := entity.OrderWithDetail{
-		Order:   order,
-		Details: orderDetails,
+		Order:     order,
+		Details:   orderDetails,
+		Discounts: discounts,
 	}
 
 	return orderWithDetail, nil
 }
+
+func checkPasscode(order entity.Order, passcode string) error {
+	if order.Passcode == nil {
+		return errInvalidPasscode
+	}
+
+	errPass := bcrypt.CompareHashAndPassword([]byte(*order.Passcode), []byte(passcode))
+	if errPass != nil {
+		return errInvalidPasscode
+	}
+
+	return nil
+}
+
+type passcodeScope struct {
+	key   string
+	limit int64
+}
+
+// verifyPasscode memeriksa passcode dengan batas percobaan per pesanan dan per IP.
+// Percobaan dicatat sebelum passcode dibandingkan sehingga request paralel
+// tidak bisa melewati batas. Setelah batas terlampaui, setiap kegagalan
+// berikutnya menggandakan lama kunci.
+func (u *orderUsecase) verifyPasscode(c context.Context, order entity.Order, passcode string, clientIP string) error {
+	scopes := []passcodeScope{
+		{"order:" + order.ID, viper.GetInt64("PASSCODE_MAX_ATTEMPTS_ORDER")},
+	}
+	if clientIP != "" {
+		scopes = append(scopes, passcodeScope{"ip:" + clientIP, viper.GetInt64("PASSCODE_MAX_ATTEMPTS_IP")})
+	}
+
+	var locked *PasscodeLockedError
+	attempts := make([]repository.PasscodeAttempt, 0, len(scopes))
+	for _, scope := range scopes {
+		attempt, err := u.attemptRepo.Attempt(c, scope.key, scope.limit, viper.GetDuration("PASSCODE_ATTEMPT_TTL"), lockoutDuration(0))
+		if err == nil && attempt.RetryAfter > 0 {
+			err = &PasscodeLockedError{RetryAfter: attempt.RetryAfter}
+		}
+		if err != nil {
+			// Passcode tidak diperiksa, percobaan pada scope sebelumnya dibatalkan
+			u.releaseAttempts(c, scopes[:len(attempts)], attempts)
+			return err
+		}
+		attempts = append(attempts, attempt)
+
+		if !attempt.Locked {
+			continue
+		}
+
+		// Kunci sementara dari script diganti dengan lama kunci yang sebenarnya
+		duration := lockoutDuration(attempt.Count - scope.limit)
+		err = u.attemptRepo.Lock(c, scope.key, duration)
+		if err != nil {
+			return err
+		}
+		if locked == nil || duration > locked.RetryAfter {
+			locked = &PasscodeLockedError{RetryAfter: duration}
+		}
+	}
+
+	errPass := checkPasscode(order, passcode)
+	if errPass == nil {
+		// Counter pesanan direset, sedangkan percobaan IP hanya dibatalkan
+		// agar satu passcode yang benar tidak menghapus kegagalan IP pada
+		// pesanan lain
+		err := u.attemptRepo.Reset(c, scopes[0].key)
+		if err != nil {
+			return err
+		}
+		u.releaseAttempts(c, scopes[1:], attempts[1:])
+		return nil
+	}
+
+	if locked != nil {
+		return locked
+	}
+
+	return errPass
+}
+
+// releaseAttempts membatalkan percobaan yang tidak perlu dihitung. Kegagalan
+// hanya di-log karena paling buruk percobaan tersebut tetap terhitung.
+func (u *orderUsecase) releaseAttempts(c context.Context, scopes []passcodeScope, attempts []repository.PasscodeAttempt) {
+	for i, attempt := range attempts {
+		err := u.attemptRepo.Release(c, scopes[i].key, attempt.Locked)
+		if err != nil {
+			log.Println("error release passcode attempt:", scopes[i].key, err)
+		}
+	}
+}
+
+// lockoutDuration menghitung lama kunci: PASSCODE_LOCKOUT_BASE * 2^n,
+// dibatasi PASSCODE_LOCKOUT_MAX jika diisi
+func lockoutDuration(n int64) time.Duration {
+	base := viper.GetDuration("PASSCODE_LOCKOUT_BASE")
+	maxDuration := viper.GetDuration("PASSCODE_LOCKOUT_MAX")
+
+	duration := base
+	for i := int64(0); i < n && (maxDuration <= 0 || duration < maxDuration); i++ {
+		if duration > math.MaxInt64/2 {
+			return math.MaxInt64
+		}
+		duration *= 2
+	}
+
+	if maxDuration > 0 && duration > maxDuration {
+		return maxDuration
+	}
+	return duration
+}
@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math"
+	"online-shop/model/entity"
+	"online-shop/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	viper.Set("PASSCODE_LOCKOUT_BASE", "1m")
+	viper.Set("PASSCODE_LOCKOUT_MAX", "1h")
+	t.Cleanup(viper.Reset)
+
+	tests := []struct {
+		n    int64
+		want time.Duration
+	}{
+		{-1, time.Minute},
+		{0, time.Minute},
+		{1, 2 * time.Minute},
+		{2, 4 * time.Minute},
+		{5, 32 * time.Minute},
+		{6, time.Hour},
+		{1000, time.Hour},
+	}
+
+	for _, tt := range tests {
+		got := lockoutDuration(tt.n)
+		if got != tt.want {
+			t.Errorf("lockoutDuration(%d) = %s, want %s", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestLockoutDurationWithoutMax(t *testing.T) {
+	viper.Set("PASSCODE_LOCKOUT_BASE", "1s")
+	viper.Set("PASSCODE_LOCKOUT_MAX", "0")
+	t.Cleanup(viper.Reset)
+
+	got := lockoutDuration(3)
+	if got != 8*time.Second {
+		t.Errorf("lockoutDuration(3) = %s, want %s", got, 8*time.Second)
+	}
+
+	got = lockoutDuration(1000)
+	if got != math.MaxInt64 {
+		t.Errorf("lockoutDuration(1000) = %s, want %s", got, time.Duration(math.MaxInt64))
+	}
+}
+
+// memoryAttemptRepository meniru script Redis dengan mutex
+type memoryAttemptRepository struct {
+	mu     sync.Mutex
+	counts map[string]int64
+	locks  map[string]time.Time
+}
+
+func newMemoryAttemptRepository() *memoryAttemptRepository {
+	return &memoryAttemptRepository{counts: map[string]int64{}, locks: map[string]time.Time{}}
+}
+
+func (r *memoryAttemptRepository) Attempt(c context.Context, scope string, limit int64, ttl time.Duration, lock time.Duration) (repository.PasscodeAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if until, ok := r.locks[scope]; ok && time.Now().Before(until) {
+		return repository.PasscodeAttempt{RetryAfter: time.Until(until)}, nil
+	}
+
+	r.counts[scope]++
+	count := r.counts[scope]
+	if limit > 0 && count >= limit {
+		r.locks[scope] = time.Now().Add(lock)
+		return repository.PasscodeAttempt{Count: count, Locked: true}, nil
+	}
+	return repository.PasscodeAttempt{Count: count}, nil
+}
+
+func (r *memoryAttemptRepository) Release(c context.Context, scope string, unlock bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.counts[scope] > 0 {
+		r.counts[scope]--
+	}
+	if unlock {
+		delete(r.locks, scope)
+	}
+	return nil
+}
+
+func (r *memoryAttemptRepository) Lock(c context.Context, scope string, duration time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.locks[scope] = time.Now().Add(duration)
+	return nil
+}
+
+func (r *memoryAttemptRepository) Reset(c context.Context, scope string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.counts, scope)
+	delete(r.locks, scope)
+	return nil
+}
+
+func TestVerifyPasscodeLimitsParallelGuesses(t *testing.T) {
+	viper.Set("PASSCODE_MAX_ATTEMPTS_ORDER", 5)
+	viper.Set("PASSCODE_MAX_ATTEMPTS_IP", 0)
+	viper.Set("PASSCODE_ATTEMPT_TTL", "1h")
+	viper.Set("PASSCODE_LOCKOUT_BASE", "1m")
+	viper.Set("PASSCODE_LOCKOUT_MAX", "1h")
+	t.Cleanup(viper.Reset)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	passcode := string(hash)
+	order := entity.Order{ID: "order-1", Passcode: &passcode}
+
+	u := &orderUsecase{attemptRepo: newMemoryAttemptRepository()}
+
+	const guesses = 50
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	compared := 0
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := u.verifyPasscode(context.Background(), order, "wrong", "")
+
+			var locked *PasscodeLockedError
+			if !errors.As(err, &locked) || locked.RetryAfter == time.Minute {
+				// Percobaan ini sempat membandingkan passcode
+				mu.Lock()
+				compared++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if compared != 5 {
+		t.Errorf("passcode compared %d times, want 5", compared)
+	}
+
+	err = u.verifyPasscode(context.Background(), order, "correct", "")
+	var locked *PasscodeLockedError
+	if !errors.As(err, &locked) {
+		t.Errorf("verifyPasscode after burst = %v, want PasscodeLockedError", err)
+	}
+}
+
+func TestVerifyPasscodeResetsOrderCounterOnSuccess(t *testing.T) {
+	viper.Set("PASSCODE_MAX_ATTEMPTS_ORDER", 3)
+	viper.Set("PASSCODE_MAX_ATTEMPTS_IP", 10)
+	viper.Set("PASSCODE_ATTEMPT_TTL", "1h")
+	viper.Set("PASSCODE_LOCKOUT_BASE", "1m")
+	viper.Set("PASSCODE_LOCKOUT_MAX", "1h")
+	t.Cleanup(viper.Reset)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	passcode := string(hash)
+	order := entity.Order{ID: "order-1", Passcode: &passcode}
+
+	repo := newMemoryAttemptRepository()
+	u := &orderUsecase{attemptRepo: repo}
+
+	for i := 0; i < 2; i++ {
+		err := u.verifyPasscode(context.Background(), order, "wrong", "10.0.0.1")
+		if !errors.Is(err, errInvalidPasscode) {
+			t.Fatalf("attempt %d: err = %v, want %v", i+1, err, errInvalidPasscode)
+		}
+	}
+
+	err = u.verifyPasscode(context.Background(), order, "correct", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("correct passcode: err = %v", err)
+	}
+
+	if repo.counts["order:order-1"] != 0 {
+		t.Errorf("order counter = %d, want 0", repo.counts["order:order-1"])
+	}
+	// Kegagalan IP tetap tercatat, hanya percobaan yang berhasil dibatalkan
+	if repo.counts["ip:10.0.0.1"] != 2 {
+		t.Errorf("ip counter = %d, want 2", repo.counts["ip:10.0.0.1"])
+	}
+}
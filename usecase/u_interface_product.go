@@ -1,39 +1,104 @@
 package usecase
 
 import (
+	"bufio"
 	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"math/rand"
+	"io"
+	"math/big"
 	"online-shop/model/dto"
 	"online-shop/model/entity"
 	"online-shop/repository"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
+	"github.com/spf13/viper"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type Usecase interface {
 	Checkout(c context.Context, input entity.Checkout) (entity.OrderWithDetail, error)
-	GetAll(c context.Context) ([]entity.Product, error)
+	GetAll(c context.Context, query dto.ReqProductQuery) (dto.ResProducts, error)
+	Search(c context.Context, query dto.ReqProductSearch) (dto.ResProductSearch, error)
 	GetByID(c context.Context, id string) (entity.Product, error)
 	Create(c context.Context, input dto.ReqProduct) (entity.Product, error)
 	Update(c context.Context, id string, input dto.ReqProduct) (entity.Product, error)
 	Delete(c context.Context, id string) error
+	AdjustStock(c context.Context, id string, input dto.ReqStockAdjustment) (entity.Product, error)
+	GetStockMovements(c context.Context, id string) ([]entity.StockMovement, error)
+	Import(c context.Context, query dto.ReqProductImport, body io.Reader) (dto.ResProductImport, error)
+	Export(c context.Context, format string, w io.Writer) error
+	GetPrices(c context.Context, id string) ([]entity.ProductPrice, error)
+	SetPrices(c context.Context, id string, input dto.ReqProductPrices) ([]entity.ProductPrice, error)
 }
 
+// ErrInvalidPriceRange dikembalikan GetAll jika min_price lebih besar dari
+// max_price
+var ErrInvalidPriceRange = errors.New("min_price must not be greater than max_price")
+
 type usecase struct {
-	repo      repository.Repository
-	orderRepo repository.OrderRepository
+	repo          repository.Repository
+	orderRepo     repository.OrderRepository
+	variantRepo   repository.VariantRepository
+	promotionRepo repository.PromotionRepository
+	shippingRepo  repository.ShippingRepository
+	taxRepo       repository.TaxRepository
+}
+
+func NewUsecase(repo repository.Repository, orderRepo repository.OrderRepository, variantRepo repository.VariantRepository, promotionRepo repository.PromotionRepository, shippingRepo repository.ShippingRepository, taxRepo repository.TaxRepository) Usecase {
+	return &usecase{repo, orderRepo, variantRepo, promotionRepo, shippingRepo, taxRepo}
 }
 
-func NewUsecase(repo repository.Repository, orderRepo repository.OrderRepository) Usecase {
-	return &usecase{repo, orderRepo}
+func (u *usecase) GetAll(c context.Context, query dto.ReqProductQuery) (dto.ResProducts, error) {
+	// Nilai default untuk pagination dan sorting
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+	if query.SortBy == "" {
+		query.SortBy = "created_at"
+	}
+	if query.Order == "" {
+		query.Order = "desc"
+	}
+
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return dto.ResProducts{}, ErrInvalidPriceRange
+	}
+
+	result, err := u.repo.GetAll(c, query)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
 }
 
-func (u *usecase) GetAll(c context.Context) ([]entity.Product, error) {
-	result, err := u.repo.GetAll(c)
+func (u *usecase) Search(c context.Context, query dto.ReqProductSearch) (dto.ResProductSearch, error) {
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	query.Query = strings.TrimSpace(query.Query)
+	tsQuery := prefixQuery(query.Query)
+	if tsQuery == "" {
+		return dto.ResProductSearch{}, errors.New("q must contain at least one letter or digit")
+	}
+
+	result, err := u.repo.Search(c, query, tsQuery)
 	if err != nil {
 		return result, err
 	}
@@ -55,10 +120,19 @@ func (u *usecase) GetByID(c context.Context, id string) (entity.Product, error)
 }
 
 func (u *usecase) Create(c context.Context, input dto.ReqProduct) (entity.Product, error) {
+	taxClass, err := u.taxClass(c, input.TaxClass)
+	if err != nil {
+		return entity.Product{}, err
+	}
+
 	product := entity.Product{
 		ID:        uuid.New().String(),
+		SKU:       optionalSKU(input.SKU),
 		Name:      input.Name,
-		Price:     input.Price,
+		Price:     entity.NewMoney(input.Price, entity.BaseCurrency()),
+		Stock:     input.Stock,
+		Weight:    input.Weight,
+		TaxClass:  taxClass,
 		IsDeleted: &[]bool{false}[0],
 	}
 
@@ -80,12 +154,22 @@ func (u *usecase) Update(c context.Context, id string, input dto.ReqProduct) (en
 		return product, errors.New("product not found")
 	}
 
-	if product.Name == input.Name && product.Price == input.Price {
+	taxClass, err := u.taxClass(c, input.TaxClass)
+	if err != nil {
+		return product, err
+	}
+
+	sku := optionalSKU(input.SKU)
+	if product.Name == input.Name && product.Price.Amount == input.Price && product.Weight == input.Weight &&
+		product.TaxClass == taxClass && sameSKU(product.SKU, sku) {
 		return product, errors.New("no changes detected")
 	}
 
+	product.SKU = sku
 	product.Name = input.Name
-	product.Price = input.Price
+	product.Price = entity.NewMoney(input.Price, entity.BaseCurrency())
+	product.Weight = input.Weight
+	product.TaxClass = taxClass
 
 	result, err := u.repo.Update(c, product)
 	if err != nil {
@@ -95,6 +179,23 @@ func (u *usecase) Update(c context.Context, id string, input dto.ReqProduct) (en
 	return result, nil
 }
 
+// taxClass memastikan kelas pajak ada, kelas kosong memakai kelas default
+func (u *usecase) taxClass(c context.Context, code string) (string, error) {
+	if code == "" {
+		return entity.DefaultTaxClass, nil
+	}
+
+	class, err := u.taxRepo.GetClass(c, code)
+	if err != nil {
+		return "", err
+	}
+	if class.Code == "" {
+		return "", errors.New("tax class not found")
+	}
+
+	return class.Code, nil
+}
+
 func (u *usecase) Delete(c context.Context, id string) error {
 	product, err := u.repo.GetByID(c, id)
 	if err != nil {
@@ -115,9 +216,532 @@ func (u *usecase) Delete(c context.Context, id string) error {
 	return nil
 }
 
+func (u *usecase) AdjustStock(c context.Context, id string, input dto.ReqStockAdjustment) (entity.Product, error) {
+	movement := entity.StockMovement{
+		ID:        uuid.NewString(),
+		ProductID: id,
+		Change:    input.Change,
+		Reason:    input.Reason,
+		CreatedAt: time.Now(),
+	}
+
+	if input.Note != "" {
+		movement.Note = &input.Note
+	}
+
+	result, err := u.repo.AdjustStock(c, movement)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return result, errors.New("product not found")
+	}
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (u *usecase) GetStockMovements(c context.Context, id string) ([]entity.StockMovement, error) {
+	product, err := u.repo.GetByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.ID != id {
+		return nil, errors.New("product not found")
+	}
+
+	result, err := u.repo.GetStockMovements(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (u *usecase) GetPrices(c context.Context, id string) ([]entity.ProductPrice, error) {
+	_, err := u.GetByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.repo.GetPrices(c, id)
+}
+
+// SetPrices mengganti daftar harga produk. Harga dalam mata uang dasar tetap
+// diatur lewat Price produk dan varian.
+func (u *usecase) SetPrices(c context.Context, id string, input dto.ReqProductPrices) ([]entity.ProductPrice, error) {
+	_, err := u.GetByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := u.variantRepo.GetByProductID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	variantIDs := make(map[string]bool, len(variants))
+	for _, variant := range variants {
+		variantIDs[variant.ID] = true
+	}
+
+	now := time.Now()
+	prices := make([]entity.ProductPrice, 0, len(input.Prices))
+	seen := make(map[string]bool, len(input.Prices))
+	for i, item := range input.Prices {
+		currency := entity.NormalizeCurrency(item.Currency)
+		if !entity.IsCurrency(currency) {
+			return nil, fmt.Errorf("prices[%d]: currency is not supported", i)
+		}
+		if currency == entity.BaseCurrency() {
+			return nil, fmt.Errorf("prices[%d]: price in %s is set on the product or variant", i, currency)
+		}
+
+		price := entity.ProductPrice{
+			ID:        uuid.NewString(),
+			ProductID: id,
+			Currency:  currency,
+			Price:     entity.NewMoney(item.Price, currency),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if item.VariantID != "" {
+			if !variantIDs[item.VariantID] {
+				return nil, fmt.Errorf("prices[%d]: variant with ID %s not found for product %s", i, item.VariantID, id)
+			}
+			price.VariantID = &item.VariantID
+		}
+
+		key := item.VariantID + "/" + currency
+		if seen[key] {
+			return nil, fmt.Errorf("prices[%d]: duplicate price for %s", i, currency)
+		}
+		seen[key] = true
+
+		prices = append(prices, price)
+	}
+
+	err = u.repo.SetPrices(c, id, prices)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.repo.GetPrices(c, id)
+}
+
+// Import memvalidasi seluruh baris terlebih dahulu. Perubahan hanya diterapkan
+// jika tidak ada baris yang gagal dan bukan dry run.
+func (u *usecase) Import(c context.Context, query dto.ReqProductImport, body io.Reader) (dto.ResProductImport, error) {
+	result := dto.ResProductImport{DryRun: query.DryRun, Errors: []dto.ProductImportError{}}
+
+	if query.Format == "" {
+		query.Format = "csv"
+	}
+
+	rows, err := parseImportRows(query.Format, body, &result)
+	if err != nil {
+		return result, err
+	}
+	result.Total = len(rows)
+
+	if len(rows) == 0 && len(result.Errors) == 0 {
+		return result, errors.New("import file has no rows")
+	}
+
+	plan, err := u.planImport(c, rows, &result)
+	if err != nil {
+		return result, err
+	}
+
+	if len(result.Errors) > 0 || query.DryRun {
+		return result, nil
+	}
+
+	err = u.repo.Import(c, plan)
+	if err != nil {
+		return result, err
+	}
+	result.Applied = true
+	result.Reference = plan.Reference
+
+	return result, nil
+}
+
+// importRow adalah baris import beserta nomor barisnya di file
+type importRow struct {
+	line int
+	dto.ProductImportRow
+}
+
+// planImport mencocokkan setiap baris dengan produk yang sudah ada berdasarkan
+// id atau sku lalu menyusun daftar produk yang dibuat dan diubah
+func (u *usecase) planImport(c context.Context, rows []importRow, result *dto.ResProductImport) (entity.ProductImport, error) {
+	plan := entity.ProductImport{Reference: uuid.NewString(), Stocks: map[string]int64{}}
+
+	ids := []string{}
+	skus := []string{}
+	for _, row := range rows {
+		if row.ID != "" {
+			ids = append(ids, row.ID)
+		}
+		if row.SKU != "" {
+			skus = append(skus, row.SKU)
+		}
+	}
+
+	existing, err := u.repo.GetForImport(c, ids, skus)
+	if err != nil {
+		return plan, err
+	}
+
+	byID := map[string]entity.Product{}
+	bySKU := map[string]entity.Product{}
+	for _, product := range existing {
+		byID[product.ID] = product
+		if product.SKU != nil && (product.IsDeleted == nil || !*product.IsDeleted) {
+			bySKU[*product.SKU] = product
+		}
+	}
+
+	// Produk yang sudah dicocokkan dengan baris sebelumnya
+	matched := map[string]int{}
+
+	for _, row := range rows {
+		sku := optionalSKU(row.SKU)
+
+		product, found := byID[row.ID]
+		if row.ID == "" {
+			product, found = bySKU[row.SKU]
+		}
+
+		if first, ok := matched[product.ID]; found && ok {
+			result.Errors = append(result.Errors, dto.ProductImportError{Row: row.line, Message: fmt.Sprintf("product %s is already changed in row %d", product.ID, first)})
+			continue
+		}
+		if found {
+			matched[product.ID] = row.line
+		}
+
+		if found && product.IsDeleted != nil && *product.IsDeleted {
+			result.Errors = append(result.Errors, dto.ProductImportError{Row: row.line, Message: "product " + product.ID + " has been deleted"})
+			continue
+		}
+
+		if owner, ok := bySKU[row.SKU]; ok && row.SKU != "" && owner.ID != product.ID {
+			result.Errors = append(result.Errors, dto.ProductImportError{Row: row.line, Message: "sku " + row.SKU + " is already used by product " + owner.ID})
+			continue
+		}
+
+		if !found {
+			id := row.ID
+			if id == "" {
+				id = uuid.NewString()
+			}
+
+			var stock int64
+			if row.Stock != nil {
+				stock = *row.Stock
+			}
+
+			plan.Creates = append(plan.Creates, entity.Product{
+				ID:        id,
+				SKU:       sku,
+				Name:      row.Name,
+				Price:     entity.NewMoney(row.Price, entity.BaseCurrency()),
+				Stock:     stock,
+				TaxClass:  entity.DefaultTaxClass,
+				IsDeleted: &[]bool{false}[0],
+			})
+			result.Created++
+			continue
+		}
+
+		stockChanged := row.Stock != nil && *row.Stock != product.Stock
+		if product.Name == row.Name && product.Price.Amount == row.Price && sameSKU(product.SKU, sku) && !stockChanged {
+			result.Unchanged++
+			continue
+		}
+
+		product.SKU = sku
+		product.Name = row.Name
+		product.Price = entity.NewMoney(row.Price, entity.BaseCurrency())
+		plan.Updates = append(plan.Updates, product)
+		if row.Stock != nil {
+			plan.Stocks[product.ID] = *row.Stock
+		}
+		result.Updated++
+	}
+
+	return plan, nil
+}
+
+// parseImportRows membaca dan memvalidasi setiap baris file import. Baris yang
+// tidak valid dicatat di result.Errors, sedangkan error yang dikembalikan
+// berarti file tidak bisa dibaca sama sekali.
+func parseImportRows(format string, body io.Reader, result *dto.ResProductImport) ([]importRow, error) {
+	rows := []importRow{}
+	seenIDs := map[string]int{}
+	seenSKUs := map[string]int{}
+	maxRows := viper.GetInt("PRODUCT_IMPORT_MAX_ROWS")
+
+	add := func(line int, row dto.ProductImportRow) error {
+		if len(rows)+len(result.Errors) >= maxRows {
+			return fmt.Errorf("import file exceeds the maximum of %d rows", maxRows)
+		}
+
+		row.ID = strings.TrimSpace(row.ID)
+		row.SKU = strings.TrimSpace(row.SKU)
+		row.Name = strings.TrimSpace(row.Name)
+
+		message := validateImportRow(row)
+		if message == "" && row.ID != "" {
+			if first, ok := seenIDs[row.ID]; ok {
+				message = fmt.Sprintf("duplicate id, already used in row %d", first)
+			}
+		}
+		if message == "" && row.SKU != "" {
+			if first, ok := seenSKUs[row.SKU]; ok {
+				message = fmt.Sprintf("duplicate sku, already used in row %d", first)
+			}
+		}
+		if message != "" {
+			result.Errors = append(result.Errors, dto.ProductImportError{Row: line, Message: message})
+			return nil
+		}
+
+		if row.ID != "" {
+			seenIDs[row.ID] = line
+		}
+		if row.SKU != "" {
+			seenSKUs[row.SKU] = line
+		}
+		rows = append(rows, importRow{line, row})
+		return nil
+	}
+
+	switch format {
+	case "jsonl":
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+		line := 0
+		for scanner.Scan() {
+			line++
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+
+			var row dto.ProductImportRow
+			decoder := json.NewDecoder(strings.NewReader(scanner.Text()))
+			decoder.DisallowUnknownFields()
+			err := decoder.Decode(&row)
+			if err != nil {
+				result.Errors = append(result.Errors, dto.ProductImportError{Row: line, Message: err.Error()})
+				continue
+			}
+
+			err = add(line, row)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err := scanner.Err()
+		if err != nil {
+			return nil, err
+		}
+	default:
+		reader := csv.NewReader(body)
+		reader.TrimLeadingSpace = true
+
+		header, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		columns := map[string]int{}
+		for i, name := range header {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		for _, name := range []string{"name", "price"} {
+			if _, ok := columns[name]; !ok {
+				return nil, fmt.Errorf("csv header must contain a %q column", name)
+			}
+		}
+
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) && errors.Is(err, csv.ErrFieldCount) {
+				result.Errors = append(result.Errors, dto.ProductImportError{Row: parseErr.StartLine, Message: "wrong number of columns"})
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			line, _ := reader.FieldPos(0)
+
+			row, message := csvImportRow(columns, record)
+			if message != "" {
+				result.Errors = append(result.Errors, dto.ProductImportError{Row: line, Message: message})
+				continue
+			}
+
+			err = add(line, row)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+func csvImportRow(columns map[string]int, record []string) (dto.ProductImportRow, string) {
+	var row dto.ProductImportRow
+
+	field := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	row.ID = field("id")
+	row.SKU = field("sku")
+	row.Name = field("name")
+
+	price, err := strconv.ParseInt(field("price"), 10, 64)
+	if err != nil {
+		return row, "price must be a whole number"
+	}
+	row.Price = price
+
+	if value := field("stock"); value != "" {
+		stock, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return row, "stock must be a whole number"
+		}
+		row.Stock = &stock
+	}
+
+	return row, ""
+}
+
+// validateImportRow menerapkan aturan yang sama dengan dto.ReqProduct
+func validateImportRow(row dto.ProductImportRow) string {
+	if row.ID != "" {
+		if _, err := uuid.Parse(row.ID); err != nil {
+			return "id must be a valid uuid"
+		}
+	}
+	if len(row.SKU) > 64 {
+		return "sku must be at most 64 characters"
+	}
+	if row.Name == "" {
+		return "name is required"
+	}
+	if len(row.Name) > 255 {
+		return "name must be at most 255 characters"
+	}
+	if row.Price <= 0 {
+		return "price must be greater than 0"
+	}
+	if row.Stock != nil && *row.Stock < 0 {
+		return "stock must not be negative"
+	}
+	return ""
+}
+
+// Export menulis seluruh produk aktif ke w dalam format yang sama dengan import
+func (u *usecase) Export(c context.Context, format string, w io.Writer) error {
+	if format == "jsonl" {
+		encoder := json.NewEncoder(w)
+		return u.repo.Export(c, func(product entity.Product) error {
+			return encoder.Encode(exportRow(product))
+		})
+	}
+
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"id", "sku", "name", "price", "stock"})
+	if err != nil {
+		return err
+	}
+
+	err = u.repo.Export(c, func(product entity.Product) error {
+		row := exportRow(product)
+		return writer.Write([]string{
+			row.ID,
+			row.SKU,
+			row.Name,
+			strconv.FormatInt(row.Price, 10),
+			strconv.FormatInt(*row.Stock, 10),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func exportRow(product entity.Product) dto.ProductImportRow {
+	row := dto.ProductImportRow{
+		ID:    product.ID,
+		Name:  product.Name,
+		Price: product.Price.Amount,
+		Stock: &product.Stock,
+	}
+	if product.SKU != nil {
+		row.SKU = *product.SKU
+	}
+	return row
+}
+
+// optionalSKU mengubah sku kosong menjadi nil agar tidak bentrok dengan
+// unique index sku
+func optionalSKU(sku string) *string {
+	sku = strings.TrimSpace(sku)
+	if sku == "" {
+		return nil
+	}
+	return &sku
+}
+
+func sameSKU(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 func (u *usecase) Checkout(c context.Context, input entity.Checkout) (entity.OrderWithDetail, error) {
+	if len(input.Products) == 0 {
+		return entity.OrderWithDetail{}, errors.New("products must not be empty")
+	}
+
+	for _, productQty := range input.Products {
+		if productQty.Quantity <= 0 {
+			return entity.OrderWithDetail{}, fmt.Errorf("quantity for product with ID %s must be greater than zero", productQty.ID)
+		}
+	}
+
 	// 1. Ambil Produk dari Repository
-	products, err := u.repo.GetAll(c)
+	productIDs := make([]string, 0, len(input.Products))
+	for _, productQty := range input.Products {
+		productIDs = append(productIDs, productQty.ID)
+	}
+
+	products, err := u.repo.GetByIDs(c, productIDs)
 	if err != nil {
 		return entity.OrderWithDetail{}, err
 	}
@@ -128,18 +752,129 @@ func (u *usecase) Checkout(c context.Context, input entity.Checkout) (entity.Ord
 		productMap[product.ID] = product
 	}
 
+	variants, err := u.variantRepo.GetByProductIDs(c, productIDs)
+	if err != nil {
+		return entity.OrderWithDetail{}, err
+	}
+
+	variantMap := make(map[string]entity.ProductVariant)
+	hasVariants := make(map[string]bool)
+	for _, variant := range variants {
+		variantMap[variant.ID] = variant
+		hasVariants[variant.ProductID] = true
+	}
+
+	currency, err := checkoutCurrency(input.Currency)
+	if err != nil {
+		return entity.OrderWithDetail{}, err
+	}
+
+	// Harga dalam mata uang selain mata uang dasar diambil dari daftar harga
+	priceList := make(map[string]entity.Money)
+	if currency != entity.BaseCurrency() {
+		prices, err := u.repo.GetPricesByCurrency(c, productIDs, currency)
+		if err != nil {
+			return entity.OrderWithDetail{}, err
+		}
+
+		for _, price := range prices {
+			if price.VariantID != nil {
+				priceList[*price.VariantID] = price.Price
+			} else {
+				priceList[price.ProductID] = price.Price
+			}
+		}
+	}
+
 	// 2. Hitung Total Keseluruhan
-	var grandTotal int64
-	for _, productQty := range input.Products {
+	subtotal := entity.NewMoney(0, currency)
+	linePrices := make([]entity.Money, len(input.Products))
+	var weight int64
+	for i, productQty := range input.Products {
 		product, exists := productMap[productQty.ID]
 		if !exists {
 			return entity.OrderWithDetail{}, fmt.Errorf("product with ID %s not found", productQty.ID)
 		}
-		grandTotal += product.Price * int64(productQty.Quantity)
+
+		var variant *entity.ProductVariant
+		if productQty.VariantID != "" {
+			found, exists := variantMap[productQty.VariantID]
+			if !exists || found.ProductID != product.ID {
+				return entity.OrderWithDetail{}, fmt.Errorf("variant with ID %s not found for product %s", productQty.VariantID, product.ID)
+			}
+			variant = &found
+		} else if hasVariants[product.ID] {
+			// Produk yang memiliki varian harus dipesan per varian
+			return entity.OrderWithDetail{}, fmt.Errorf("product with ID %s requires a variant", product.ID)
+		}
+
+		price, ok := linePrice(product, variant, currency, priceList)
+		if !ok {
+			return entity.OrderWithDetail{}, fmt.Errorf("product with ID %s has no price in %s", product.ID, currency)
+		}
+
+		lineTotal, err := price.Mul(int64(productQty.Quantity))
+		if err != nil {
+			return entity.OrderWithDetail{}, err
+		}
+
+		subtotal, err = subtotal.Add(lineTotal)
+		if err != nil {
+			return entity.OrderWithDetail{}, err
+		}
+
+		linePrices[i] = price
+		weight, err = addWeight(weight, product.Weight, productQty.Quantity)
+		if err != nil {
+			return entity.OrderWithDetail{}, err
+		}
+	}
+
+	// Ongkos kirim dihitung dari metode yang dipilih, provinsi tujuan, dan berat keranjang
+	if input.ShippingMethodID == "" {
+		return entity.OrderWithDetail{}, errors.New("shippingMethodId is required")
+	}
+
+	method, err := u.shippingRepo.GetByID(c, input.ShippingMethodID)
+	if err != nil {
+		return entity.OrderWithDetail{}, err
+	}
+	if method.ID == "" {
+		return entity.OrderWithDetail{}, errors.New("shipping method not found")
+	}
+
+	shipping, ok := quoteShipping(method, input.Address, weight, currency)
+	if !ok {
+		return entity.OrderWithDetail{}, errors.New("shipping method is not available for this address")
+	}
+
+	// Kode promo divalidasi terhadap subtotal sebelum diskon
+	var promotion entity.Promotion
+	if input.PromoCode != "" {
+		promotion, err = u.promotionRepo.GetByCode(c, strings.TrimSpace(input.PromoCode))
+		if err != nil {
+			return entity.OrderWithDetail{}, err
+		}
+		if promotion.ID == "" {
+			return entity.OrderWithDetail{}, errors.New("promo code not found")
+		}
+
+		err = checkPromotion(c, u.promotionRepo, promotion, input.Email, subtotal, time.Now())
+		if err != nil {
+			return entity.OrderWithDetail{}, err
+		}
+	}
+
+	grandTotal, err := subtotal.Add(shipping.Cost)
+	if err != nil {
+		return entity.OrderWithDetail{}, err
 	}
 
 	// 3. Generate Kode Akses
-	passcode := generatePasscode(5) // Mengasumsikan Anda memiliki fungsi untuk menghasilkan kode akses
+	passcode, errPasscode := generatePasscode(passcodeLength())
+	if errPasscode != nil {
+		return entity.OrderWithDetail{}, errPasscode
+	}
 
 	hashPasscode, errHash := bcrypt.GenerateFromPassword([]byte(passcode), bcrypt.MinCost)
 	if errHash != nil {
@@ -150,38 +885,153 @@ func (u *usecase) Checkout(c context.Context, input entity.Checkout) (entity.Ord
 
 	// 4. Buat Pesanan
 	order := entity.Order{
-		ID:         uuid.NewString(),
-		Email:      input.Email,
-		Address:    input.Address,
-		GrandTotal: grandTotal,
-		Passcode:   &passHash,
+		ID:            uuid.NewString(),
+		Email:         input.Email,
+		Address:       input.Address.String(),
+		Currency:      currency,
+		Subtotal:      subtotal,
+		DiscountTotal: subtotal.Zero(),
+		TaxTotal:      subtotal.Zero(),
+		GrandTotal:    grandTotal,
+		Status:        entity.OrderStatusPending,
+		Passcode:      &passHash,
+
+		ShippingAddress:  &input.Address,
+		ShippingMethodID: &method.ID,
+		ShippingMethod:   &method.Name,
+		ShippingWeight:   weight,
+		ShippingCost:     shipping.Cost,
+	}
+
+	// Pesanan tamu tidak terhubung ke akun pelanggan
+	if input.CustomerID != "" {
+		order.CustomerID = &input.CustomerID
+	}
+
+	// Batas waktu pembayaran, tidak ada batas jika tidak dikonfigurasi
+	if window := viper.GetDuration("ORDER_PAYMENT_WINDOW"); window > 0 {
+		expiresAt := time.Now().Add(window)
+		order.ExpiresAt = &expiresAt
 	}
 
 	// 5. Buat Detail Pesanan
 	var orderDetails []entity.OrderDetail
-	for _, productQty := range input.Products {
+	for i, productQty := range input.Products {
 		product := productMap[productQty.ID]
+		total, err := linePrices[i].Mul(int64(productQty.Quantity))
+		if err != nil {
+			return entity.OrderWithDetail{}, err
+		}
+
 		orderDetail := entity.OrderDetail{
 			ID:        uuid.NewString(),
 			OrderID:   order.ID,
 			ProductID: product.ID,
 			Quantity:  productQty.Quantity,
-			Price:     product.Price,
-			Total:     product.Price * int64(productQty.Quantity),
+			Currency:  currency,
+			Price:     linePrices[i],
+			Discount:  subtotal.Zero(),
+			Tax:       subtotal.Zero(),
+			Total:     total,
+		}
+
+		// Opsi varian disalin agar detail pesanan tidak berubah jika varian diubah
+		if productQty.VariantID != "" {
+			variant := variantMap[productQty.VariantID]
+			orderDetail.VariantID = &variant.ID
+			orderDetail.SKU = &variant.SKU
+			orderDetail.Options = variant.Options
 		}
+
 		orderDetails = append(orderDetails, orderDetail)
 	}
 
+	// Diskon produk dibagi ke setiap detail sehingga jumlah Total detail
+	// ditambah ongkos kirim setelah diskon sama dengan GrandTotal
+	var discounts []entity.OrderDiscount
+	if promotion.ID != "" {
+		eligibleIDs, err := u.promotionRepo.GetEligibleProductIDs(c, promotion, productIDs)
+		if err != nil {
+			return entity.OrderWithDetail{}, err
+		}
+
+		eligible := make(map[string]bool, len(eligibleIDs))
+		for _, id := range eligibleIDs {
+			eligible[id] = true
+		}
+
+		discounts, err = applyPromotion(promotion, order.ID, orderDetails, eligible, order.ShippingCost)
+		if err != nil {
+			return entity.OrderWithDetail{}, err
+		}
+		if len(discounts) == 0 {
+			return entity.OrderWithDetail{}, errors.New("promo code does not apply to any product in the basket")
+		}
+
+		for _, discount := range discounts {
+			order.DiscountTotal, err = order.DiscountTotal.Add(discount.Amount)
+			if err != nil {
+				return entity.OrderWithDetail{}, err
+			}
+		}
+
+		order.GrandTotal, err = order.GrandTotal.Sub(order.DiscountTotal)
+		if err != nil {
+			return entity.OrderWithDetail{}, err
+		}
+		order.PromoCode = &promotion.Code
+	}
+
+	// Pajak dihitung per detail dari total setelah diskon dengan tarif provinsi tujuan
+	taxRates, err := u.taxRepo.GetRatesForProvince(c, input.Address.Province)
+	if err != nil {
+		return entity.OrderWithDetail{}, err
+	}
+
+	rates := taxRatesByClass(taxRates)
+	order.PricesIncludeTax = pricesIncludeTax()
+	for i := range orderDetails {
+		product := productMap[orderDetails[i].ProductID]
+		rate := rates[product.TaxClass]
+		tax, err := calculateTax(orderDetails[i].Total, rate, order.PricesIncludeTax)
+		if err != nil {
+			return entity.OrderWithDetail{}, err
+		}
+
+		orderDetails[i].TaxClass = &product.TaxClass
+		orderDetails[i].TaxRate = rate
+		orderDetails[i].Tax = tax
+		if !order.PricesIncludeTax {
+			orderDetails[i].Total, err = orderDetails[i].Total.Add(tax)
+			if err != nil {
+				return entity.OrderWithDetail{}, err
+			}
+		}
+
+		order.TaxTotal, err = order.TaxTotal.Add(tax)
+		if err != nil {
+			return entity.OrderWithDetail{}, err
+		}
+	}
+
+	if !order.PricesIncludeTax {
+		order.GrandTotal, err = order.GrandTotal.Add(order.TaxTotal)
+		if err != nil {
+			return entity.OrderWithDetail{}, err
+		}
+	}
+
 	// 6. Simpan Pesanan dan Detailnya
-	err = u.orderRepo.CreateOrder(order, orderDetails)
+	err = u.orderRepo.CreateOrder(c, order, orderDetails, discounts)
 	if err != nil {
 		return entity.OrderWithDetail{}, err
 	}
 
 	// 7. Mengembalikan Respon
 	orderWithDetail := entity.OrderWithDetail{
-		Order:   order,
-		Details: orderDetails,
+		Order:     order,
+		Details:   orderDetails,
+		Discounts: discounts,
 	}
 
 	orderWithDetail.Order.Passcode = &passcode
@@ -189,22 +1039,79 @@ func (u *usecase) Checkout(c context.Context, input entity.Checkout) (entity.Ord
 	return orderWithDetail, nil
 }
 
-// Fungsi untuk menghasilkan kode akses
-func generatePasscode(length int) string {
+// checkoutCurrency memvalidasi mata uang pesanan, kosong berarti mata uang dasar
+func checkoutCurrency(code string) (string, error) {
+	currency := entity.NormalizeCurrency(code)
+	if currency == "" {
+		return entity.BaseCurrency(), nil
+	}
+	if !entity.IsCurrency(currency) {
+		return "", errors.New("currency is not supported")
+	}
+	return currency, nil
+}
+
+// linePrice menentukan harga satuan dalam mata uang pesanan. Di luar mata uang
+// dasar, harga varian di daftar harga didahulukan, lalu harga produk jika
+// varian tidak memiliki harga sendiri.
+func linePrice(product entity.Product, variant *entity.ProductVariant, currency string, priceList map[string]entity.Money) (entity.Money, bool) {
+	if currency == entity.BaseCurrency() {
+		if variant != nil {
+			return variant.EffectivePrice(product.Price), true
+		}
+		return product.Price, true
+	}
+
+	if variant != nil {
+		if price, ok := priceList[variant.ID]; ok {
+			return price, true
+		}
+		if variant.Price != nil {
+			return entity.Money{}, false
+		}
+	}
+
+	price, ok := priceList[product.ID]
+	return price, ok
+}
+
+// generatePasscode menghasilkan passcode acak dengan crypto/rand
+func generatePasscode(length int) (string, error) {
 	// Charset berisi karakter yang dapat digunakan dalam passcode
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
-	// Membuat slice byte dengan panjang sesuai input 'length'
 	passcode := make([]byte, length)
+	for i := range passcode {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", err
+		}
+		passcode[i] = charset[n.Int64()]
+	}
+
+	return string(passcode), nil
+}
 
-	// Seed generator angka acak dengan nilai unik berdasarkan waktu sekarang
-	rand.Seed(time.Now().UnixNano())
+// passcodeLength membaca panjang passcode dari konfigurasi, minimal 5 karakter
+func passcodeLength() int {
+	length := viper.GetInt("ORDER_PASSCODE_LENGTH")
+	if length < 5 {
+		return 5
+	}
+	return length
+}
 
-	// Mengisi setiap indeks slice 'passcode' dengan karakter acak dari 'charset'
-	for i := range passcode {
-		passcode[i] = charset[rand.Intn(len(charset))]
+// prefixQuery menyusun tsquery yang mencocokkan setiap kata sebagai awalan,
+// misalnya "kaos pol" menjadi "kaos:* & pol:*". Karakter selain huruf dan
+// angka dibuang agar tidak bisa menyisipkan operator tsquery.
+func prefixQuery(query string) string {
+	terms := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, term := range terms {
+		terms[i] = term + ":*"
 	}
 
-	// Mengembalikan passcode sebagai string
-	return string(passcode)
+	return strings.Join(terms, " & ")
 }
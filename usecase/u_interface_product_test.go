@@ -0,0 +1,28 @@
+package usecase
+
+import "testing"
+
+func TestPrefixQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"kaos", "kaos:*"},
+		{"Kaos Pol", "kaos:* & pol:*"},
+		{"  kaos   polos  ", "kaos:* & polos:*"},
+		{"iphone 15", "iphone:* & 15:*"},
+		{"kaos & !polo | (x)", "kaos:* & polo:* & x:*"},
+		{"t-shirt", "t:* & shirt:*"},
+		{"kopi:* <-> susu", "kopi:* & susu:*"},
+		{"café", "café:*"},
+		{"!!! & |", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		got := prefixQuery(tt.query)
+		if got != tt.want {
+			t.Errorf("prefixQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
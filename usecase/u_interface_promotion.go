@@ -0,0 +1,397 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PromotionUsecase interface {
+	List(c context.Context) ([]entity.Promotion, error)
+	GetByID(c context.Context, id string) (entity.Promotion, error)
+	Create(c context.Context, input dto.ReqPromotion) (entity.Promotion, error)
+	Update(c context.Context, id string, input dto.ReqPromotion) (entity.Promotion, error)
+	Delete(c context.Context, id string) error
+}
+
+type promotionUsecase struct {
+	repo         repository.PromotionRepository
+	productRepo  repository.Repository
+	categoryRepo repository.CategoryRepository
+}
+
+func NewPromotionUsecase(repo repository.PromotionRepository, productRepo repository.Repository, categoryRepo repository.CategoryRepository) PromotionUsecase {
+	return &promotionUsecase{repo, productRepo, categoryRepo}
+}
+
+func (u *promotionUsecase) List(c context.Context) ([]entity.Promotion, error) {
+	return u.repo.List(c)
+}
+
+func (u *promotionUsecase) GetByID(c context.Context, id string) (entity.Promotion, error) {
+	promotion, err := u.repo.GetByID(c, id)
+	if err != nil {
+		return promotion, err
+	}
+
+	if promotion.ID != id {
+		return promotion, errors.New("promotion not found")
+	}
+
+	return promotion, nil
+}
+
+func (u *promotionUsecase) Create(c context.Context, input dto.ReqPromotion) (entity.Promotion, error) {
+	promotion := entity.Promotion{
+		ID:        uuid.NewString(),
+		CreatedAt: time.Now(),
+	}
+
+	err := u.applyInput(c, &promotion, input)
+	if err != nil {
+		return promotion, err
+	}
+
+	existing, err := u.repo.GetByCode(c, promotion.Code)
+	if err != nil {
+		return promotion, err
+	}
+	if existing.ID != "" {
+		return promotion, errors.New("promo code already exists")
+	}
+
+	return u.repo.Create(c, promotion)
+}
+
+func (u *promotionUsecase) Update(c context.Context, id string, input dto.ReqPromotion) (entity.Promotion, error) {
+	promotion, err := u.GetByID(c, id)
+	if err != nil {
+		return promotion, err
+	}
+
+	err = u.applyInput(c, &promotion, input)
+	if err != nil {
+		return promotion, err
+	}
+
+	existing, err := u.repo.GetByCode(c, promotion.Code)
+	if err != nil {
+		return promotion, err
+	}
+	if existing.ID != "" && existing.ID != promotion.ID {
+		return promotion, errors.New("promo code already exists")
+	}
+
+	return u.repo.Update(c, promotion)
+}
+
+func (u *promotionUsecase) Delete(c context.Context, id string) error {
+	_, err := u.GetByID(c, id)
+	if err != nil {
+		return err
+	}
+
+	return u.repo.Delete(c, id)
+}
+
+// applyInput memvalidasi input sesuai jenis promo lalu menyalinnya ke promotion
+func (u *promotionUsecase) applyInput(c context.Context, promotion *entity.Promotion, input dto.ReqPromotion) error {
+	code := strings.ToUpper(strings.TrimSpace(input.Code))
+	for _, r := range code {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return errors.New("code may only contain letters, digits, '-' and '_'")
+		}
+	}
+	if code == "" {
+		return errors.New("code must not be empty")
+	}
+
+	switch input.Type {
+	case entity.PromotionPercentage:
+		if input.Value < 1 || input.Value > 100 {
+			return errors.New("value for a percentage promotion must be between 1 and 100")
+		}
+	case entity.PromotionFixed:
+		if input.Value < 1 {
+			return errors.New("value for a fixed promotion must be greater than 0")
+		}
+	case entity.PromotionBuyXGetY:
+		if input.BuyQuantity < 1 || input.GetQuantity < 1 {
+			return errors.New("buyQuantity and getQuantity are required for a buy_x_get_y promotion")
+		}
+	}
+
+	currency := entity.NormalizeCurrency(input.Currency)
+	if currency == "" {
+		currency = entity.BaseCurrency()
+	}
+	if !entity.IsCurrency(currency) {
+		return errors.New("currency is not supported")
+	}
+
+	if input.StartsAt != nil && input.EndsAt != nil && !input.EndsAt.After(*input.StartsAt) {
+		return errors.New("endsAt must be after startsAt")
+	}
+
+	productIDs := uniqueStrings(input.ProductIDs)
+	if len(productIDs) > 0 {
+		products, err := u.productRepo.GetByIDs(c, productIDs)
+		if err != nil {
+			return err
+		}
+		if len(products) != len(productIDs) {
+			return errors.New("one or more products not found")
+		}
+	}
+
+	categoryIDs := uniqueStrings(input.CategoryIDs)
+	if len(categoryIDs) > 0 {
+		categories, err := u.categoryRepo.GetByIDs(c, categoryIDs)
+		if err != nil {
+			return err
+		}
+		if len(categories) != len(categoryIDs) {
+			return errors.New("one or more categories not found")
+		}
+	}
+
+	promotion.Code = code
+	promotion.Description = nil
+	if input.Description != "" {
+		promotion.Description = &input.Description
+	}
+	promotion.Type = input.Type
+	promotion.Currency = currency
+	promotion.Value = 0
+	promotion.MaxDiscount = nil
+	promotion.BuyQuantity = 0
+	promotion.GetQuantity = 0
+	switch input.Type {
+	case entity.PromotionPercentage:
+		promotion.Value = input.Value
+		if input.MaxDiscount != nil {
+			maxDiscount := entity.NewMoney(*input.MaxDiscount, currency)
+			promotion.MaxDiscount = &maxDiscount
+		}
+	case entity.PromotionFixed:
+		promotion.Value = input.Value
+	case entity.PromotionBuyXGetY:
+		promotion.BuyQuantity = input.BuyQuantity
+		promotion.GetQuantity = input.GetQuantity
+	}
+	promotion.MinSubtotal = entity.NewMoney(input.MinSubtotal, currency)
+	promotion.StartsAt = input.StartsAt
+	promotion.EndsAt = input.EndsAt
+	promotion.UsageLimit = input.UsageLimit
+	promotion.UsageLimitPerEmail = input.UsageLimitPerEmail
+	promotion.ProductIDs = productIDs
+	promotion.CategoryIDs = categoryIDs
+	promotion.IsActive = input.IsActive == nil || *input.IsActive
+	promotion.UpdatedAt = time.Now()
+
+	return nil
+}
+
+func uniqueStrings(values []string) []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// checkPromotion memastikan kode promo masih bisa dipakai oleh email tersebut
+// untuk keranjang dengan subtotal tertentu. Promo yang memuat nominal hanya
+// berlaku untuk pesanan dengan mata uang promo.
+func checkPromotion(c context.Context, repo repository.PromotionRepository, promotion entity.Promotion, email string, subtotal entity.Money, now time.Time) error {
+	if promotion.RequiresCurrency() && promotion.Currency != subtotal.Currency {
+		return fmt.Errorf("promo code is not valid for %s orders", subtotal.Currency)
+	}
+	if !promotion.IsActive {
+		return errors.New("promo code is not active")
+	}
+	if promotion.StartsAt != nil && now.Before(*promotion.StartsAt) {
+		return errors.New("promo code is not valid yet")
+	}
+	if promotion.EndsAt != nil && !now.Before(*promotion.EndsAt) {
+		return errors.New("promo code has expired")
+	}
+	if promotion.MinSubtotal.IsPositive() && subtotal.Amount < promotion.MinSubtotal.Amount {
+		return fmt.Errorf("promo code requires a minimum subtotal of %s", promotion.MinSubtotal)
+	}
+
+	// Diperiksa ulang di dalam transaksi saat pesanan dibuat
+	total, byEmail, err := repo.CountRedemptions(c, promotion.ID, email)
+	if err != nil {
+		return err
+	}
+	if promotion.UsageLimit != nil && total >= *promotion.UsageLimit {
+		return repository.ErrPromotionUsageLimit
+	}
+	if promotion.UsageLimitPerEmail != nil && byEmail >= *promotion.UsageLimitPerEmail {
+		return repository.ErrPromotionUsageLimit
+	}
+
+	return nil
+}
+
+// applyPromotion menghitung baris diskon promo untuk detail pesanan dan
+// membagi nominalnya ke Discount dan Total setiap detail. eligible berisi
+// id produk yang boleh mendapat diskon. Diskon free_shipping tidak dibagi ke
+// detail karena memotong ongkos kirim.
+func applyPromotion(promotion entity.Promotion, orderID string, details []entity.OrderDetail, eligible map[string]bool, shippingCost entity.Money) ([]entity.OrderDiscount, error) {
+	now := time.Now()
+	discount := entity.OrderDiscount{
+		OrderID:     orderID,
+		PromotionID: promotion.ID,
+		Code:        promotion.Code,
+		Type:        promotion.Type,
+		Currency:    shippingCost.Currency,
+		CreatedAt:   now,
+	}
+
+	var eligibleIndexes []int
+	eligibleTotal := shippingCost.Zero()
+	for i, detail := range details {
+		if eligible[detail.ProductID] {
+			var err error
+			eligibleIndexes = append(eligibleIndexes, i)
+			eligibleTotal, err = eligibleTotal.Add(detail.Total)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	switch promotion.Type {
+	case entity.PromotionPercentage, entity.PromotionFixed:
+		amount := entity.NewMoney(promotion.Value, eligibleTotal.Currency)
+		discount.Description = fmt.Sprintf("%s: %s off", promotion.Code, amount)
+		if promotion.Type == entity.PromotionPercentage {
+			// Dibulatkan ke bawah agar diskon tidak melebihi persentasenya
+			var err error
+			amount, err = eligibleTotal.MulDiv(promotion.Value, 100)
+			if err != nil {
+				return nil, err
+			}
+			discount.Description = fmt.Sprintf("%s: %d%% off", promotion.Code, promotion.Value)
+			if promotion.MaxDiscount != nil {
+				amount = amount.Min(*promotion.MaxDiscount)
+			}
+		}
+		amount = amount.Min(eligibleTotal)
+		if amount.IsZero() {
+			return nil, nil
+		}
+
+		err := allocateDiscount(details, eligibleIndexes, eligibleTotal, amount)
+		if err != nil {
+			return nil, err
+		}
+		discount.ID = uuid.NewString()
+		discount.Amount = amount
+		return []entity.OrderDiscount{discount}, nil
+
+	case entity.PromotionBuyXGetY:
+		// Setiap kelipatan buy+get pada satu baris mendapat get barang gratis
+		var discounts []entity.OrderDiscount
+		group := int64(promotion.BuyQuantity + promotion.GetQuantity)
+		for _, i := range eligibleIndexes {
+			free := int64(details[i].Quantity) / group * int64(promotion.GetQuantity)
+			if free == 0 {
+				continue
+			}
+
+			amount, err := details[i].Price.Mul(free)
+			if err != nil {
+				return nil, err
+			}
+			details[i].Discount, err = details[i].Discount.Add(amount)
+			if err != nil {
+				return nil, err
+			}
+			details[i].Total, err = details[i].Total.Sub(amount)
+			if err != nil {
+				return nil, err
+			}
+
+			line := discount
+			line.ID = uuid.NewString()
+			line.OrderDetailID = &details[i].ID
+			line.Description = fmt.Sprintf("%s: buy %d get %d free", promotion.Code, promotion.BuyQuantity, promotion.GetQuantity)
+			line.Amount = amount
+			discounts = append(discounts, line)
+		}
+		return discounts, nil
+
+	case entity.PromotionFreeShipping:
+		// Hanya berlaku jika ada produk eligible di keranjang
+		if len(eligibleIndexes) == 0 {
+			return nil, nil
+		}
+
+		discount.ID = uuid.NewString()
+		discount.Description = promotion.Code + ": free shipping"
+		discount.Amount = shippingCost
+		return []entity.OrderDiscount{discount}, nil
+	}
+
+	return nil, nil
+}
+
+// allocateDiscount membagi diskon tingkat pesanan ke detail yang eligible
+// sebanding dengan totalnya. Sisa pembulatan diberikan satu per satu mulai
+// dari detail pertama agar jumlahnya tepat sama dengan amount.
+func allocateDiscount(details []entity.OrderDetail, indexes []int, eligibleTotal entity.Money, amount entity.Money) error {
+	allocated := amount.Zero()
+	for _, i := range indexes {
+		share, err := details[i].Total.MulDiv(amount.Amount, eligibleTotal.Amount)
+		if err != nil {
+			return err
+		}
+		details[i].Discount, err = details[i].Discount.Add(share)
+		if err != nil {
+			return err
+		}
+		details[i].Total, err = details[i].Total.Sub(share)
+		if err != nil {
+			return err
+		}
+		allocated, err = allocated.Add(share)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Sisa pembulatan selalu lebih kecil dari jumlah detail
+	for remaining := amount.Amount - allocated.Amount; remaining > 0; {
+		progressed := false
+		for _, i := range indexes {
+			if remaining == 0 {
+				break
+			}
+			if details[i].Total.IsPositive() {
+				details[i].Discount.Amount++
+				details[i].Total.Amount--
+				remaining--
+				progressed = true
+			}
+		}
+		if !progressed {
+			break
+		}
+	}
+
+	return nil
+}
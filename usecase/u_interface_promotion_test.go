@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"errors"
+	"math"
+	"online-shop/model/entity"
+	"testing"
+)
+
+func idr(amount int64) entity.Money {
+	return entity.NewMoney(amount, "IDR")
+}
+
+func orderLine(id string, productID string, price int64, quantity int32) entity.OrderDetail {
+	return entity.OrderDetail{
+		ID:        id,
+		ProductID: productID,
+		Quantity:  quantity,
+		Price:     idr(price),
+		Discount:  idr(0),
+		Total:     idr(price * int64(quantity)),
+	}
+}
+
+func sumDiscounts(discounts []entity.OrderDiscount) int64 {
+	var total int64
+	for _, discount := range discounts {
+		total += discount.Amount.Amount
+	}
+	return total
+}
+
+func TestApplyPromotion(t *testing.T) {
+	maxDiscount := idr(50)
+
+	tests := []struct {
+		name      string
+		promotion entity.Promotion
+		details   []entity.OrderDetail
+		eligible  map[string]bool
+		shipping  entity.Money
+		// wantAmount adalah total diskon, wantDiscounts adalah Discount per detail
+		wantAmount    int64
+		wantDiscounts []int64
+		wantLines     int
+	}{
+		{
+			name:          "percentage split with rounding remainder",
+			promotion:     entity.Promotion{Code: "TEN", Type: entity.PromotionPercentage, Value: 10},
+			details:       []entity.OrderDetail{orderLine("a", "p1", 333, 1), orderLine("b", "p2", 667, 1)},
+			eligible:      map[string]bool{"p1": true, "p2": true},
+			shipping:      idr(0),
+			wantAmount:    100,
+			wantDiscounts: []int64{34, 66},
+			wantLines:     1,
+		},
+		{
+			name:          "percentage rounds down",
+			promotion:     entity.Promotion{Code: "TEN", Type: entity.PromotionPercentage, Value: 10},
+			details:       []entity.OrderDetail{orderLine("a", "p1", 999, 1)},
+			eligible:      map[string]bool{"p1": true},
+			shipping:      idr(0),
+			wantAmount:    99,
+			wantDiscounts: []int64{99},
+			wantLines:     1,
+		},
+		{
+			name:          "percentage capped by max discount",
+			promotion:     entity.Promotion{Code: "HALF", Type: entity.PromotionPercentage, Value: 50, MaxDiscount: &maxDiscount},
+			details:       []entity.OrderDetail{orderLine("a", "p1", 1000, 1)},
+			eligible:      map[string]bool{"p1": true},
+			shipping:      idr(0),
+			wantAmount:    50,
+			wantDiscounts: []int64{50},
+			wantLines:     1,
+		},
+		{
+			name:          "fixed only on eligible lines",
+			promotion:     entity.Promotion{Code: "FIX", Type: entity.PromotionFixed, Value: 300},
+			details:       []entity.OrderDetail{orderLine("a", "p1", 100, 2), orderLine("b", "p2", 500, 1), orderLine("c", "p3", 400, 1)},
+			eligible:      map[string]bool{"p1": true, "p3": true},
+			shipping:      idr(0),
+			wantAmount:    300,
+			wantDiscounts: []int64{100, 0, 200},
+			wantLines:     1,
+		},
+		{
+			name:          "fixed capped by eligible total",
+			promotion:     entity.Promotion{Code: "FIX", Type: entity.PromotionFixed, Value: 5000},
+			details:       []entity.OrderDetail{orderLine("a", "p1", 100, 3), orderLine("b", "p2", 200, 1)},
+			eligible:      map[string]bool{"p1": true, "p2": true},
+			shipping:      idr(0),
+			wantAmount:    500,
+			wantDiscounts: []int64{300, 200},
+			wantLines:     1,
+		},
+		{
+			name:          "nothing eligible",
+			promotion:     entity.Promotion{Code: "FIX", Type: entity.PromotionFixed, Value: 100},
+			details:       []entity.OrderDetail{orderLine("a", "p1", 100, 1)},
+			eligible:      map[string]bool{},
+			shipping:      idr(0),
+			wantAmount:    0,
+			wantDiscounts: []int64{0},
+			wantLines:     0,
+		},
+		{
+			name:          "buy two get one per line",
+			promotion:     entity.Promotion{Code: "B2G1", Type: entity.PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1},
+			details:       []entity.OrderDetail{orderLine("a", "p1", 100, 7), orderLine("b", "p2", 50, 2)},
+			eligible:      map[string]bool{"p1": true, "p2": true},
+			shipping:      idr(0),
+			wantAmount:    200,
+			wantDiscounts: []int64{200, 0},
+			wantLines:     1,
+		},
+		{
+			name:          "free shipping",
+			promotion:     entity.Promotion{Code: "SHIP", Type: entity.PromotionFreeShipping},
+			details:       []entity.OrderDetail{orderLine("a", "p1", 100, 1)},
+			eligible:      map[string]bool{"p1": true},
+			shipping:      idr(15000),
+			wantAmount:    15000,
+			wantDiscounts: []int64{0},
+			wantLines:     1,
+		},
+		{
+			name:          "free shipping without eligible product",
+			promotion:     entity.Promotion{Code: "SHIP", Type: entity.PromotionFreeShipping},
+			details:       []entity.OrderDetail{orderLine("a", "p1", 100, 1)},
+			eligible:      map[string]bool{},
+			shipping:      idr(15000),
+			wantAmount:    0,
+			wantDiscounts: []int64{0},
+			wantLines:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			totals := make([]int64, len(tt.details))
+			for i, detail := range tt.details {
+				totals[i] = detail.Total.Amount
+			}
+
+			discounts, err := applyPromotion(tt.promotion, "order-1", tt.details, tt.eligible, tt.shipping)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(discounts) != tt.wantLines {
+				t.Errorf("discount lines = %d, want %d", len(discounts), tt.wantLines)
+			}
+			if got := sumDiscounts(discounts); got != tt.wantAmount {
+				t.Errorf("discount amount = %d, want %d", got, tt.wantAmount)
+			}
+
+			for i, detail := range tt.details {
+				if detail.Discount.Amount != tt.wantDiscounts[i] {
+					t.Errorf("details[%d].Discount = %d, want %d", i, detail.Discount.Amount, tt.wantDiscounts[i])
+				}
+				if detail.Total.Amount != totals[i]-detail.Discount.Amount {
+					t.Errorf("details[%d].Total = %d, want %d", i, detail.Total.Amount, totals[i]-detail.Discount.Amount)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyPromotionErrors(t *testing.T) {
+	promotion := entity.Promotion{Code: "TEN", Type: entity.PromotionPercentage, Value: 10}
+
+	usd := orderLine("b", "p2", 100, 1)
+	usd.Total = entity.NewMoney(100, "USD")
+	_, err := applyPromotion(promotion, "order-1", []entity.OrderDetail{orderLine("a", "p1", 100, 1), usd}, map[string]bool{"p1": true, "p2": true}, idr(0))
+	if !errors.Is(err, entity.ErrCurrencyMismatch) {
+		t.Errorf("mixed currencies: err = %v, want %v", err, entity.ErrCurrencyMismatch)
+	}
+
+	huge := orderLine("a", "p1", math.MaxInt64, 1)
+	_, err = applyPromotion(promotion, "order-1", []entity.OrderDetail{huge, orderLine("b", "p2", 1, 1)}, map[string]bool{"p1": true, "p2": true}, idr(0))
+	if !errors.Is(err, entity.ErrAmountOverflow) {
+		t.Errorf("overflowing subtotal: err = %v, want %v", err, entity.ErrAmountOverflow)
+	}
+}
+
+func TestAllocateDiscount(t *testing.T) {
+	tests := []struct {
+		name   string
+		totals []int64
+		amount int64
+		want   []int64
+	}{
+		{"proportional", []int64{200, 300, 500}, 100, []int64{20, 30, 50}},
+		{"remainder from first line", []int64{1, 1, 1}, 2, []int64{1, 1, 0}},
+		{"remainder spread", []int64{100, 100, 100}, 100, []int64{34, 33, 33}},
+		{"whole total", []int64{7, 13}, 20, []int64{7, 13}},
+		{"remainder skips empty line", []int64{0, 10, 10}, 5, []int64{0, 3, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := make([]entity.OrderDetail, len(tt.totals))
+			indexes := make([]int, len(tt.totals))
+			var eligibleTotal int64
+			for i, total := range tt.totals {
+				details[i] = entity.OrderDetail{Discount: idr(0), Total: idr(total)}
+				indexes[i] = i
+				eligibleTotal += total
+			}
+
+			err := allocateDiscount(details, indexes, idr(eligibleTotal), idr(tt.amount))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var allocated int64
+			for i, detail := range details {
+				allocated += detail.Discount.Amount
+				if detail.Discount.Amount != tt.want[i] {
+					t.Errorf("details[%d].Discount = %d, want %d", i, detail.Discount.Amount, tt.want[i])
+				}
+				if detail.Total.Amount < 0 {
+					t.Errorf("details[%d].Total = %d, must not be negative", i, detail.Total.Amount)
+				}
+			}
+			if allocated != tt.amount {
+				t.Errorf("allocated = %d, want %d", allocated, tt.amount)
+			}
+		})
+	}
+}
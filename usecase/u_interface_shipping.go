@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ShippingUsecase interface {
+	List(c context.Context) ([]entity.ShippingMethod, error)
+	GetByID(c context.Context, id string) (entity.ShippingMethod, error)
+	Create(c context.Context, input dto.ReqShippingMethod) (entity.ShippingMethod, error)
+	Update(c context.Context, id string, input dto.ReqShippingMethod) (entity.ShippingMethod, error)
+	Delete(c context.Context, id string) error
+	Quote(c context.Context, input dto.ReqShippingQuote) ([]entity.ShippingQuote, error)
+}
+
+type shippingUsecase struct {
+	repo        repository.ShippingRepository
+	productRepo repository.Repository
+}
+
+func NewShippingUsecase(repo repository.ShippingRepository, productRepo repository.Repository) ShippingUsecase {
+	return &shippingUsecase{repo, productRepo}
+}
+
+func (u *shippingUsecase) List(c context.Context) ([]entity.ShippingMethod, error) {
+	return u.repo.List(c, false)
+}
+
+func (u *shippingUsecase) GetByID(c context.Context, id string) (entity.ShippingMethod, error) {
+	method, err := u.repo.GetByID(c, id)
+	if err != nil {
+		return method, err
+	}
+
+	if method.ID != id {
+		return method, errors.New("shipping method not found")
+	}
+
+	return method, nil
+}
+
+func (u *shippingUsecase) Create(c context.Context, input dto.ReqShippingMethod) (entity.ShippingMethod, error) {
+	method := entity.ShippingMethod{
+		ID:        uuid.NewString(),
+		CreatedAt: time.Now(),
+	}
+
+	err := applyShippingInput(&method, input)
+	if err != nil {
+		return method, err
+	}
+
+	return u.repo.Create(c, method)
+}
+
+func (u *shippingUsecase) Update(c context.Context, id string, input dto.ReqShippingMethod) (entity.ShippingMethod, error) {
+	method, err := u.GetByID(c, id)
+	if err != nil {
+		return method, err
+	}
+
+	err = applyShippingInput(&method, input)
+	if err != nil {
+		return method, err
+	}
+
+	return u.repo.Update(c, method)
+}
+
+func (u *shippingUsecase) Delete(c context.Context, id string) error {
+	_, err := u.GetByID(c, id)
+	if err != nil {
+		return err
+	}
+
+	return u.repo.Delete(c, id)
+}
+
+// Quote mengembalikan metode pengiriman aktif yang bisa mengirim keranjang
+// ke alamat tersebut beserta ongkos kirimnya
+func (u *shippingUsecase) Quote(c context.Context, input dto.ReqShippingQuote) ([]entity.ShippingQuote, error) {
+	productIDs := make([]string, 0, len(input.Products))
+	for _, productQty := range input.Products {
+		if productQty.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity for product with ID %s must be greater than zero", productQty.ID)
+		}
+		productIDs = append(productIDs, productQty.ID)
+	}
+
+	products, err := u.productRepo.GetByIDs(c, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	productMap := make(map[string]entity.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+	}
+
+	var weight int64
+	for _, productQty := range input.Products {
+		product, exists := productMap[productQty.ID]
+		if !exists {
+			return nil, fmt.Errorf("product with ID %s not found", productQty.ID)
+		}
+		weight, err = addWeight(weight, product.Weight, productQty.Quantity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	methods, err := u.repo.List(c, true)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, err := checkoutCurrency(input.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := []entity.ShippingQuote{}
+	for _, method := range methods {
+		quote, ok := quoteShipping(method, input.Address, weight, currency)
+		if ok {
+			quotes = append(quotes, quote)
+		}
+	}
+
+	return quotes, nil
+}
+
+// ErrWeightOverflow dikembalikan jika total berat keranjang melebihi batas
+// int64
+var ErrWeightOverflow = errors.New("total weight is too large")
+
+// addWeight menambahkan berat weight sebanyak quantity ke total. Berat
+// dan jumlah barang tidak pernah negatif.
+func addWeight(total int64, weight int64, quantity int32) (int64, error) {
+	if weight != 0 && int64(quantity) > (math.MaxInt64-total)/weight {
+		return 0, ErrWeightOverflow
+	}
+
+	return total + weight*int64(quantity), nil
+}
+
+// quoteShipping menghitung ongkos kirim sebuah metode untuk alamat, berat
+// paket, dan mata uang pesanan, false jika metode tidak melayani alamat,
+// berat, atau mata uang tersebut
+func quoteShipping(method entity.ShippingMethod, address entity.Address, weight int64, currency string) (entity.ShippingQuote, bool) {
+	if !method.IsActive {
+		return entity.ShippingQuote{}, false
+	}
+
+	rate, ok := method.Rate(address.Province, weight, currency)
+	if !ok {
+		return entity.ShippingQuote{}, false
+	}
+
+	return entity.ShippingQuote{
+		MethodID:    method.ID,
+		Name:        method.Name,
+		Description: method.Description,
+		Zone:        rate.Zone,
+		Weight:      weight,
+		Cost:        rate.Price,
+	}, true
+}
+
+func applyShippingInput(method *entity.ShippingMethod, input dto.ReqShippingMethod) error {
+	rates := make([]entity.ShippingRate, 0, len(input.Rates))
+	for i, rate := range input.Rates {
+		if rate.MaxWeight != nil && *rate.MaxWeight <= rate.MinWeight {
+			return fmt.Errorf("rates[%d]: maxWeight must be greater than minWeight", i)
+		}
+
+		provinces := rate.Provinces
+		if provinces == nil {
+			provinces = []string{}
+		}
+
+		currency := entity.NormalizeCurrency(rate.Currency)
+		if currency == "" {
+			currency = entity.BaseCurrency()
+		}
+		if !entity.IsCurrency(currency) {
+			return fmt.Errorf("rates[%d]: currency is not supported", i)
+		}
+
+		rates = append(rates, entity.ShippingRate{
+			Zone:      rate.Zone,
+			Provinces: provinces,
+			MinWeight: rate.MinWeight,
+			MaxWeight: rate.MaxWeight,
+			Price:     entity.NewMoney(rate.Price, currency),
+		})
+	}
+
+	method.Name = input.Name
+	method.Description = nil
+	if input.Description != "" {
+		method.Description = &input.Description
+	}
+	method.Rates = rates
+	method.SortOrder = input.SortOrder
+	method.IsActive = input.IsActive == nil || *input.IsActive
+	method.UpdatedAt = time.Now()
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAddWeight(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int64
+		weight   int64
+		quantity int32
+		want     int64
+		wantErr  error
+	}{
+		{"adds weight times quantity", 500, 250, 3, 1250, nil},
+		{"zero weight", 500, 0, math.MaxInt32, 500, nil},
+		{"reaches the limit exactly", math.MaxInt64 - 10, 5, 2, math.MaxInt64, nil},
+		{"overflows the total", math.MaxInt64 - 10, 5, 3, 0, ErrWeightOverflow},
+		{"overflows the product", 0, math.MaxInt64/2 + 1, 2, 0, ErrWeightOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := addWeight(tt.total, tt.weight, tt.quantity)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("addWeight() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("addWeight() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type TaxUsecase interface {
+	ListClasses(c context.Context) ([]entity.TaxClass, error)
+	CreateClass(c context.Context, input dto.ReqTaxClass) (entity.TaxClass, error)
+	DeleteClass(c context.Context, code string) error
+	ListRates(c context.Context) ([]entity.TaxRate, error)
+	GetRate(c context.Context, id string) (entity.TaxRate, error)
+	CreateRate(c context.Context, input dto.ReqTaxRate) (entity.TaxRate, error)
+	UpdateRate(c context.Context, id string, input dto.ReqTaxRate) (entity.TaxRate, error)
+	DeleteRate(c context.Context, id string) error
+}
+
+type taxUsecase struct {
+	repo repository.TaxRepository
+}
+
+func NewTaxUsecase(repo repository.TaxRepository) TaxUsecase {
+	return &taxUsecase{repo}
+}
+
+func (u *taxUsecase) ListClasses(c context.Context) ([]entity.TaxClass, error) {
+	return u.repo.ListClasses(c)
+}
+
+func (u *taxUsecase) CreateClass(c context.Context, input dto.ReqTaxClass) (entity.TaxClass, error) {
+	code := strings.ToLower(strings.TrimSpace(input.Code))
+	for _, r := range code {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return entity.TaxClass{}, errors.New("code may only contain lowercase letters, digits and '_'")
+		}
+	}
+
+	existing, err := u.repo.GetClass(c, code)
+	if err != nil {
+		return existing, err
+	}
+	if existing.Code != "" {
+		return existing, errors.New("tax class already exists")
+	}
+
+	return u.repo.CreateClass(c, entity.TaxClass{
+		Code:      code,
+		Name:      input.Name,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (u *taxUsecase) DeleteClass(c context.Context, code string) error {
+	if code == entity.DefaultTaxClass {
+		return errors.New("the default tax class cannot be deleted")
+	}
+
+	class, err := u.repo.GetClass(c, code)
+	if err != nil {
+		return err
+	}
+	if class.Code == "" {
+		return errors.New("tax class not found")
+	}
+
+	count, err := u.repo.CountClassUsage(c, code)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("tax class is still used by products")
+	}
+
+	return u.repo.DeleteClass(c, code)
+}
+
+func (u *taxUsecase) ListRates(c context.Context) ([]entity.TaxRate, error) {
+	return u.repo.ListRates(c)
+}
+
+func (u *taxUsecase) GetRate(c context.Context, id string) (entity.TaxRate, error) {
+	rate, err := u.repo.GetRate(c, id)
+	if err != nil {
+		return rate, err
+	}
+
+	if rate.ID != id {
+		return rate, errors.New("tax rate not found")
+	}
+
+	return rate, nil
+}
+
+func (u *taxUsecase) CreateRate(c context.Context, input dto.ReqTaxRate) (entity.TaxRate, error) {
+	rate := entity.TaxRate{
+		ID:        uuid.NewString(),
+		CreatedAt: time.Now(),
+	}
+
+	err := u.applyRateInput(c, &rate, input)
+	if err != nil {
+		return rate, err
+	}
+
+	return u.repo.CreateRate(c, rate)
+}
+
+func (u *taxUsecase) UpdateRate(c context.Context, id string, input dto.ReqTaxRate) (entity.TaxRate, error) {
+	rate, err := u.GetRate(c, id)
+	if err != nil {
+		return rate, err
+	}
+
+	err = u.applyRateInput(c, &rate, input)
+	if err != nil {
+		return rate, err
+	}
+
+	return u.repo.UpdateRate(c, rate)
+}
+
+func (u *taxUsecase) DeleteRate(c context.Context, id string) error {
+	_, err := u.GetRate(c, id)
+	if err != nil {
+		return err
+	}
+
+	return u.repo.DeleteRate(c, id)
+}
+
+func (u *taxUsecase) applyRateInput(c context.Context, rate *entity.TaxRate, input dto.ReqTaxRate) error {
+	class, err := u.repo.GetClass(c, input.TaxClass)
+	if err != nil {
+		return err
+	}
+	if class.Code == "" {
+		return errors.New("tax class not found")
+	}
+
+	rate.TaxClass = class.Code
+	rate.Province = strings.TrimSpace(input.Province)
+	rate.Name = input.Name
+	rate.Rate = input.Rate
+	rate.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// pricesIncludeTax melaporkan apakah harga produk sudah termasuk pajak
+func pricesIncludeTax() bool {
+	return viper.GetString("TAX_PRICING_MODE") == "inclusive"
+}
+
+// taxRatesByClass memilih tarif setiap kelas pajak, tarif khusus provinsi
+// didahulukan daripada tarif umum
+func taxRatesByClass(rates []entity.TaxRate) map[string]int64 {
+	result := make(map[string]int64)
+	specific := make(map[string]bool)
+	for _, rate := range rates {
+		if rate.Province == "" {
+			if !specific[rate.TaxClass] {
+				result[rate.TaxClass] = rate.Rate
+			}
+			continue
+		}
+		result[rate.TaxClass] = rate.Rate
+		specific[rate.TaxClass] = true
+	}
+	return result
+}
+
+// calculateTax menghitung pajak amount dengan tarif dalam basis poin, dibulatkan
+// setengah ke atas. Jika inclusive, pajak diambil dari amount yang sudah
+// termasuk pajak.
+func calculateTax(amount entity.Money, rate int64, inclusive bool) (entity.Money, error) {
+	if !amount.IsPositive() || rate <= 0 {
+		return amount.Zero(), nil
+	}
+
+	if inclusive {
+		net, err := amount.MulDivRound(10000, 10000+rate)
+		if err != nil {
+			return entity.Money{}, err
+		}
+		return amount.Sub(net)
+	}
+	return amount.MulDivRound(rate, 10000)
+}
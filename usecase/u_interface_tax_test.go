@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"errors"
+	"math"
+	"online-shop/model/entity"
+	"testing"
+)
+
+func TestCalculateTax(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    entity.Money
+		rate      int64
+		inclusive bool
+		want      entity.Money
+	}{
+		{"exclusive", entity.NewMoney(10000, "IDR"), 1100, false, entity.NewMoney(1100, "IDR")},
+		{"exclusive rounds half up", entity.NewMoney(1005, "IDR"), 1000, false, entity.NewMoney(101, "IDR")},
+		{"exclusive rounds down below half", entity.NewMoney(1004, "IDR"), 1000, false, entity.NewMoney(100, "IDR")},
+		{"inclusive", entity.NewMoney(11100, "IDR"), 1100, true, entity.NewMoney(1100, "IDR")},
+		{"inclusive rounds net amount", entity.NewMoney(1000, "IDR"), 1100, true, entity.NewMoney(99, "IDR")},
+		{"keeps currency", entity.NewMoney(1999, "USD"), 700, false, entity.NewMoney(140, "USD")},
+		{"zero rate", entity.NewMoney(10000, "IDR"), 0, false, entity.NewMoney(0, "IDR")},
+		{"negative rate", entity.NewMoney(10000, "IDR"), -100, false, entity.NewMoney(0, "IDR")},
+		{"zero amount", entity.NewMoney(0, "IDR"), 1100, true, entity.NewMoney(0, "IDR")},
+		{"negative amount", entity.NewMoney(-500, "IDR"), 1100, false, entity.NewMoney(0, "IDR")},
+		{"large amount without overflow", entity.NewMoney(math.MaxInt64, "IDR"), 10000, false, entity.NewMoney(math.MaxInt64, "IDR")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calculateTax(tt.amount, tt.rate, tt.inclusive)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("calculateTax(%s, %d, %v) = %s, want %s", tt.amount, tt.rate, tt.inclusive, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateTaxOverflow(t *testing.T) {
+	_, err := calculateTax(entity.NewMoney(math.MaxInt64, "IDR"), 10001, false)
+	if !errors.Is(err, entity.ErrAmountOverflow) {
+		t.Errorf("err = %v, want %v", err, entity.ErrAmountOverflow)
+	}
+}
@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"online-shop/model/dto"
+	"online-shop/model/entity"
+	"online-shop/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VariantUsecase interface {
+	GetVariants(c context.Context, productID string) (dto.ResProductVariants, error)
+	SetOptions(c context.Context, productID string, input dto.ReqProductOptions) ([]entity.ProductOption, error)
+	GetByID(c context.Context, productID string, id string) (entity.ProductVariant, error)
+	Create(c context.Context, productID string, input dto.ReqVariant) (entity.ProductVariant, error)
+	Update(c context.Context, productID string, id string, input dto.ReqVariant) (entity.ProductVariant, error)
+	Delete(c context.Context, productID string, id string) error
+	AdjustStock(c context.Context, productID string, id string, input dto.ReqStockAdjustment) (entity.ProductVariant, error)
+}
+
+type variantUsecase struct {
+	repo        repository.VariantRepository
+	productRepo repository.Repository
+}
+
+func NewVariantUsecase(repo repository.VariantRepository, productRepo repository.Repository) VariantUsecase {
+	return &variantUsecase{repo, productRepo}
+}
+
+func (u *variantUsecase) GetVariants(c context.Context, productID string) (dto.ResProductVariants, error) {
+	var result dto.ResProductVariants
+
+	err := u.checkProduct(c, productID)
+	if err != nil {
+		return result, err
+	}
+
+	result.Options, err = u.repo.GetOptions(c, productID)
+	if err != nil {
+		return result, err
+	}
+
+	result.Variants, err = u.repo.GetByProductID(c, productID)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (u *variantUsecase) SetOptions(c context.Context, productID string, input dto.ReqProductOptions) ([]entity.ProductOption, error) {
+	err := u.checkProduct(c, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make([]entity.ProductOption, 0, len(input.Options))
+	names := make(map[string]bool)
+	for i, option := range input.Options {
+		name := strings.ToLower(strings.TrimSpace(option.Name))
+		if names[name] {
+			return nil, fmt.Errorf("option %s is defined more than once", option.Name)
+		}
+		names[name] = true
+
+		values := make([]string, 0, len(option.Values))
+		for _, value := range option.Values {
+			value = strings.TrimSpace(value)
+			if slices.Contains(values, value) {
+				return nil, fmt.Errorf("value %s is defined more than once for option %s", value, name)
+			}
+			values = append(values, value)
+		}
+
+		options = append(options, entity.ProductOption{
+			ID:        uuid.NewString(),
+			ProductID: productID,
+			Name:      name,
+			Values:    values,
+			Position:  i,
+		})
+	}
+
+	// Varian yang sudah ada harus tetap sesuai dengan opsi yang baru
+	variants, err := u.repo.GetByProductID(c, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, variant := range variants {
+		if validateVariantOptions(options, variant.Options) != nil {
+			return nil, fmt.Errorf("variant %s does not match the new options", variant.SKU)
+		}
+	}
+
+	err = u.repo.SetOptions(c, productID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+func (u *variantUsecase) GetByID(c context.Context, productID string, id string) (entity.ProductVariant, error) {
+	variant, err := u.repo.GetByID(c, id)
+	if err != nil {
+		return variant, err
+	}
+
+	if variant.ID != id || variant.ProductID != productID {
+		return variant, errors.New("variant not found")
+	}
+
+	return variant, nil
+}
+
+func (u *variantUsecase) Create(c context.Context, productID string, input dto.ReqVariant) (entity.ProductVariant, error) {
+	err := u.checkProduct(c, productID)
+	if err != nil {
+		return entity.ProductVariant{}, err
+	}
+
+	variant := entity.ProductVariant{
+		ID:        uuid.NewString(),
+		ProductID: productID,
+		Price:     variantPrice(input.Price),
+		Stock:     input.Stock,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	err = u.applyInput(c, &variant, input)
+	if err != nil {
+		return variant, err
+	}
+
+	result, err := u.repo.Create(c, variant)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (u *variantUsecase) Update(c context.Context, productID string, id string, input dto.ReqVariant) (entity.ProductVariant, error) {
+	variant, err := u.GetByID(c, productID, id)
+	if err != nil {
+		return variant, err
+	}
+
+	variant.Price = variantPrice(input.Price)
+	variant.UpdatedAt = time.Now()
+
+	err = u.applyInput(c, &variant, input)
+	if err != nil {
+		return variant, err
+	}
+
+	result, err := u.repo.Update(c, variant)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (u *variantUsecase) Delete(c context.Context, productID string, id string) error {
+	variant, err := u.GetByID(c, productID, id)
+	if err != nil {
+		return err
+	}
+
+	variant.UpdatedAt = time.Now()
+
+	return u.repo.Delete(c, variant)
+}
+
+func (u *variantUsecase) AdjustStock(c context.Context, productID string, id string, input dto.ReqStockAdjustment) (entity.ProductVariant, error) {
+	movement := entity.StockMovement{
+		ID:        uuid.NewString(),
+		ProductID: productID,
+		VariantID: &id,
+		Change:    input.Change,
+		Reason:    input.Reason,
+		CreatedAt: time.Now(),
+	}
+
+	if input.Note != "" {
+		movement.Note = &input.Note
+	}
+
+	result, err := u.repo.AdjustStock(c, movement)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return result, errors.New("variant not found")
+	}
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (u *variantUsecase) checkProduct(c context.Context, productID string) error {
+	product, err := u.productRepo.GetByID(c, productID)
+	if err != nil {
+		return err
+	}
+
+	if product.ID != productID {
+		return errors.New("product not found")
+	}
+
+	return nil
+}
+
+// applyInput memvalidasi SKU dan opsi varian lalu menerapkannya ke variant
+func (u *variantUsecase) applyInput(c context.Context, variant *entity.ProductVariant, input dto.ReqVariant) error {
+	sku := strings.ToUpper(strings.TrimSpace(input.SKU))
+	if sku == "" {
+		return errors.New("sku must not be empty")
+	}
+
+	existing, err := u.repo.GetBySKU(c, sku)
+	if err != nil {
+		return err
+	}
+
+	if existing.ID != "" && existing.ID != variant.ID {
+		return errors.New("sku is already used by another variant")
+	}
+
+	options, err := u.repo.GetOptions(c, variant.ProductID)
+	if err != nil {
+		return err
+	}
+
+	selected := make(map[string]string, len(input.Options))
+	for name, value := range input.Options {
+		selected[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+
+	err = validateVariantOptions(options, selected)
+	if err != nil {
+		return err
+	}
+
+	// Kombinasi opsi yang sama tidak boleh dipakai dua varian
+	variants, err := u.repo.GetByProductID(c, variant.ProductID)
+	if err != nil {
+		return err
+	}
+
+	for _, other := range variants {
+		if other.ID != variant.ID && maps.Equal(other.Options, selected) {
+			return fmt.Errorf("variant %s already uses the same options", other.SKU)
+		}
+	}
+
+	variant.SKU = sku
+	variant.Options = selected
+	return nil
+}
+
+// validateVariantOptions memastikan varian memilih tepat satu nilai yang
+// tersedia untuk setiap jenis opsi produk
+func validateVariantOptions(options []entity.ProductOption, selected map[string]string) error {
+	if len(selected) != len(options) {
+		return errors.New("variant must select exactly one value for every product option")
+	}
+
+	for _, option := range options {
+		value, ok := selected[option.Name]
+		if !ok {
+			return fmt.Errorf("variant must select a value for option %s", option.Name)
+		}
+		if !slices.Contains(option.Values, value) {
+			return fmt.Errorf("%s is not a valid value for option %s", value, option.Name)
+		}
+	}
+
+	return nil
+}
+
+// variantPrice mengubah harga varian dari input yang berada dalam mata uang dasar
+func variantPrice(price *int64) *entity.Money {
+	if price == nil {
+		return nil
+	}
+
+	money := entity.NewMoney(*price, entity.BaseCurrency())
+	return &money
+}
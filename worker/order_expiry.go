@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"online-shop/usecase"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type OrderExpiryWorker interface {
+	Start()
+	Stop()
+}
+
+type orderExpiryWorker struct {
+	orderUsecase usecase.OrderUsecase
+	redis        *redis.Client
+	leaseKey     string
+	interval     time.Duration
+	batchSize    int
+	instanceID   string
+	cancel       context.CancelFunc
+	done         chan struct{}
+}
+
+func NewOrderExpiryWorker(orderUsecase usecase.OrderUsecase, redis *redis.Client, leaseKey string, interval time.Duration) OrderExpiryWorker {
+	return &orderExpiryWorker{
+		orderUsecase: orderUsecase,
+		redis:        redis,
+		leaseKey:     leaseKey,
+		interval:     interval,
+		batchSize:    100,
+		instanceID:   uuid.NewString(),
+	}
+}
+
+func (w *orderExpiryWorker) Start() {
+	if w.interval <= 0 {
+		log.Println("order expiry worker disabled: interval is not configured")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.run(ctx)
+			}
+		}
+	}()
+
+	log.Println("order expiry worker started, interval:", w.interval)
+}
+
+// Stop menghentikan worker dan menunggu proses yang sedang berjalan selesai
+func (w *orderExpiryWorker) Stop() {
+	if w.cancel == nil {
+		return
+	}
+
+	w.cancel()
+	<-w.done
+	log.Println("order expiry worker stopped")
+}
+
+func (w *orderExpiryWorker) run(ctx context.Context) {
+	// Hanya satu replika yang memegang lease pada setiap interval. Lease
+	// dilepas otomatis oleh TTL sehingga replika lain bisa mengambil alih
+	// jika replika ini mati.
+	acquired, err := w.redis.SetNX(ctx, w.leaseKey, w.instanceID, w.interval).Result()
+	if err != nil {
+		log.Println("order expiry worker: acquire lease:", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	for {
+		expired, err := w.orderUsecase.ExpireOrders(ctx, w.batchSize)
+		if err != nil {
+			log.Println("order expiry worker:", err)
+			return
+		}
+
+		if expired > 0 {
+			log.Println("order expiry worker: expired orders:", expired)
+		}
+
+		if expired < w.batchSize || ctx.Err() != nil {
+			return
+		}
+	}
+}